@@ -0,0 +1,25 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file is the entry of rubikctl
+
+// Package main provide main function for rubikctl.
+package main
+
+import (
+	"os"
+
+	"isula.org/rubik/pkg/rubikctl"
+)
+
+func main() {
+	os.Exit(rubikctl.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
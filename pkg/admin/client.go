@@ -0,0 +1,175 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements the client of the admin API
+
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"isula.org/rubik/pkg/common/audit"
+	"isula.org/rubik/pkg/core/typedef"
+)
+
+// requestTimeout is the maximum time to wait for the response of the agent
+const requestTimeout = 10 * time.Second
+
+// Client requests the admin API of the agent over the unix socket
+type Client struct {
+	socket string
+	client *http.Client
+}
+
+// NewClient returns the client connecting to the socket
+func NewClient(socket string) *Client {
+	return &Client{
+		socket: socket,
+		client: &http.Client{
+			Timeout: requestTimeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+				},
+			},
+		},
+	}
+}
+
+// do sends the request and decodes the JSON response into v if v is not nil
+func (c *Client) do(method, path string, body io.Reader, v interface{}) error {
+	// the host is ignored because the request is sent to the unix socket
+	req, err := http.NewRequest(method, "http://rubik"+path, body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to rubik via %v: %v", c.socket, err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		var errResp ErrorResponse
+		if err := json.Unmarshal(data, &errResp); err != nil || errResp.Error == "" {
+			return fmt.Errorf("request failed with status %v", resp.Status)
+		}
+		return fmt.Errorf("%v", errResp.Error)
+	}
+	if v == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+	return nil
+}
+
+// ListPods returns the pods sorted by namespace and name, all pods are returned if the namespace is empty
+func (c *Client) ListPods(namespace string) ([]*typedef.PodInfo, error) {
+	path := PodsPath
+	if namespace != "" {
+		path += "?" + url.Values{"namespace": []string{namespace}}.Encode()
+	}
+	var pods []*typedef.PodInfo
+	if err := c.do(http.MethodGet, path, nil, &pods); err != nil {
+		return nil, err
+	}
+	return pods, nil
+}
+
+// GetPod returns the details of the pod
+func (c *Client) GetPod(uid string) (*PodDetail, error) {
+	var pod PodDetail
+	if err := c.do(http.MethodGet, PodsPath+"/"+url.PathEscape(uid), nil, &pod); err != nil {
+		return nil, err
+	}
+	return &pod, nil
+}
+
+// ExplainPod returns what rubik did to the pod
+func (c *Client) ExplainPod(uid string) (*PodExplanation, error) {
+	var explanation PodExplanation
+	if err := c.do(http.MethodGet, PodsPath+"/"+url.PathEscape(uid)+ExplainSuffix, nil, &explanation); err != nil {
+		return nil, err
+	}
+	return &explanation, nil
+}
+
+// ListServices returns the status of services sorted by name
+func (c *Client) ListServices() ([]ServiceStatus, error) {
+	var services []ServiceStatus
+	if err := c.do(http.MethodGet, ServicesPath, nil, &services); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// ControlService changes the state of the enabled service
+func (c *Client) ControlService(name string, action ServiceAction) error {
+	return c.do(http.MethodPost, ServicesPath+"/"+url.PathEscape(name)+"/"+string(action), nil, nil)
+}
+
+// GetConfig returns the configuration in use in the format of the configuration file
+func (c *Client) GetConfig() (map[string]interface{}, error) {
+	var fields map[string]interface{}
+	if err := c.do(http.MethodGet, ConfigPath, nil, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// ValidateConfig checks the configuration data by the agent
+func (c *Client) ValidateConfig(data []byte) error {
+	return c.do(http.MethodPost, ValidateConfigPath, bytes.NewReader(data), nil)
+}
+
+// QueryAudit returns the latest writes in the audit journal of the agent matching the query
+func (c *Client) QueryAudit(q audit.Query) ([]audit.Record, error) {
+	params := url.Values{}
+	for key, value := range map[string]string{
+		AuditPodParam:     q.PodUID,
+		AuditServiceParam: q.Service,
+		AuditFileParam:    q.File,
+	} {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	if !q.Since.IsZero() {
+		params.Set(AuditSinceParam, q.Since.Format(time.RFC3339))
+	}
+	if q.Limit > 0 {
+		params.Set(AuditLimitParam, strconv.Itoa(q.Limit))
+	}
+	path := AuditPath
+	if len(params) != 0 {
+		path += "?" + params.Encode()
+	}
+	var records []audit.Record
+	if err := c.do(http.MethodGet, path, nil, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements the liveness and readiness endpoints
+
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"isula.org/rubik/pkg/common/log"
+)
+
+const (
+	healthStatusOK     = "ok"
+	healthStatusFailed = "failed"
+)
+
+// healthz returns 200 if rubik is alive, otherwise 503
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, r, s.source.LivenessChecks())
+}
+
+// readyz returns 200 if rubik is ready, otherwise 503
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, r, s.source.ReadinessChecks())
+}
+
+func writeHealth(w http.ResponseWriter, r *http.Request, checks []HealthCheck) {
+	if !allowGet(w, r) {
+		return
+	}
+	var resp = HealthResponse{Status: healthStatusOK, Checks: checks}
+	if resp.Checks == nil {
+		resp.Checks = make([]HealthCheck, 0)
+	}
+	code := http.StatusOK
+	for _, c := range checks {
+		if !c.Healthy {
+			resp.Status, code = healthStatusFailed, http.StatusServiceUnavailable
+			break
+		}
+	}
+	WriteJSON(w, code, &resp)
+}
+
+// ServeHealth serves HealthzPath and ReadyzPath on the TCP address until the context is canceled,
+// which is used by the probes of kubelet
+func (s *Server) ServeHealth(ctx context.Context, address string) error {
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %v: %v", address, err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(HealthzPath, s.healthz)
+	mux.HandleFunc(ReadyzPath, s.readyz)
+	server := &http.Server{Handler: mux}
+	go func() {
+		if err := server.Serve(l); err != nil && err != http.ErrServerClosed {
+			log.Errorf("health server exits abnormally: %v", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Warnf("failed to shutdown health server: %v", err)
+		}
+	}()
+	log.Infof("health server listens on %v", l.Addr())
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file resolves the cgroup details of pods
+
+package admin
+
+import (
+	"sort"
+
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/core/typedef"
+	"isula.org/rubik/pkg/core/typedef/cgroup"
+)
+
+// cgroupSubsystems are the subsystems whose paths are resolved
+var cgroupSubsystems = []string{"cpu", "cpuacct", "cpuset", "memory", "blkio", "net_cls", "perf_event"}
+
+// qosKeys are the cgroup files set by rubik services
+var qosKeys = []*cgroup.Key{
+	{SubSys: "cpu", FileName: constant.CPUCgroupFileName},
+	{SubSys: "memory", FileName: constant.MemoryCgroupFileName},
+	{SubSys: "net_cls", FileName: constant.NetCgroupFileName},
+	{SubSys: "cpu", FileName: "cpu.cfs_quota_us"},
+	{SubSys: "cpu", FileName: "cpu.cfs_period_us"},
+	{SubSys: "cpu", FileName: "cpu.cfs_burst_us"},
+	{SubSys: "memory", FileName: "memory.high"},
+	{SubSys: "blkio", FileName: "blkio.cost.weight"},
+}
+
+// newCgroupDetail resolves the cgroup paths of the hierarchy and reads the current qos values
+func newCgroupDetail(h *cgroup.Hierarchy) CgroupDetail {
+	detail := CgroupDetail{
+		Paths:  make(map[string]string, len(cgroupSubsystems)),
+		Values: make(map[string]string, len(qosKeys)),
+	}
+	for _, subsys := range cgroupSubsystems {
+		detail.Paths[subsys] = h.AbsolutePath(subsys)
+	}
+	for _, key := range qosKeys {
+		if attr := h.GetCgroupAttr(key); attr.Err == nil {
+			detail.Values[key.FileName] = attr.Value
+		}
+	}
+	return detail
+}
+
+// newPodDetail returns the cgroup details of the pod and its containers
+func newPodDetail(pod *typedef.PodInfo) *PodDetail {
+	detail := &PodDetail{
+		PodInfo:          pod,
+		Cgroup:           newCgroupDetail(&pod.Hierarchy),
+		ContainerCgroups: make(map[string]CgroupDetail, len(pod.IDContainersMap)),
+	}
+	for id, cont := range pod.IDContainersMap {
+		detail.ContainerCgroups[id] = newCgroupDetail(&cont.Hierarchy)
+	}
+	return detail
+}
+
+// newPodExplanation returns the running services and the latest values written to the cgroup files of the pod
+func newPodExplanation(pod *typedef.PodInfo, statuses []ServiceStatus) *PodExplanation {
+	var services = make([]string, 0, len(statuses))
+	for _, s := range statuses {
+		services = append(services, s.Name)
+	}
+	sort.Strings(services)
+	// the cgroups of containers are under the cgroup of the pod
+	writes := cgroup.RecentWrites(pod.Path)
+	return &PodExplanation{
+		Name:      pod.Name,
+		UID:       pod.UID,
+		Namespace: pod.Namespace,
+		Services:  services,
+		Writes:    writes,
+	}
+}
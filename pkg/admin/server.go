@@ -0,0 +1,288 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements the admin API server over the unix socket
+
+// Package admin provides the local HTTP/JSON API for introspecting rubik
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"isula.org/rubik/pkg/common/audit"
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/common/log"
+	"isula.org/rubik/pkg/core/typedef"
+)
+
+const (
+	// shutdownTimeout is the maximum time to wait for the requests being processed when the server stops
+	shutdownTimeout = 3 * time.Second
+	// maxBodySize is the maximum size of the request body
+	maxBodySize = 10 * 1024 * 1024
+)
+
+// Server serves the admin API over the unix socket
+type Server struct {
+	socket string
+	source Source
+	mux    *http.ServeMux
+}
+
+// NewServer creates the admin API server listening on the socket
+func NewServer(socket string, source Source) *Server {
+	s := &Server{
+		socket: socket,
+		source: source,
+		mux:    http.NewServeMux(),
+	}
+	s.mux.HandleFunc(PodsPath, s.listPods)
+	s.mux.HandleFunc(PodsPath+"/", s.getPod)
+	s.mux.HandleFunc(ServicesPath, s.listServices)
+	s.mux.HandleFunc(ServicesPath+"/", s.controlService)
+	s.mux.HandleFunc(ConfigPath, s.getConfig)
+	s.mux.HandleFunc(ValidateConfigPath, s.validateConfig)
+	s.mux.HandleFunc(AuditPath, s.queryAudit)
+	s.mux.HandleFunc(HealthzPath, s.healthz)
+	s.mux.HandleFunc(ReadyzPath, s.readyz)
+	return s
+}
+
+// Handle registers the handler for the path, which is used to extend the API
+func (s *Server) Handle(path string, handler http.Handler) {
+	s.mux.Handle(path, handler)
+}
+
+// Start listens on the socket and serves the requests until the context is canceled
+func (s *Server) Start(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(s.socket), constant.DefaultDirMode); err != nil {
+		return fmt.Errorf("failed to create directory of %v: %v", s.socket, err)
+	}
+	// only one rubik runs at the same time, so the existing socket is left by the previous rubik
+	if err := os.Remove(s.socket); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %v: %v", s.socket, err)
+	}
+	l, err := net.Listen("unix", s.socket)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %v: %v", s.socket, err)
+	}
+	if err := os.Chmod(s.socket, constant.DefaultFileMode); err != nil {
+		l.Close()
+		return fmt.Errorf("failed to set mode of %v: %v", s.socket, err)
+	}
+
+	server := &http.Server{Handler: s.mux}
+	go func() {
+		if err := server.Serve(l); err != nil && err != http.ErrServerClosed {
+			log.Errorf("admin server exits abnormally: %v", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Warnf("failed to shutdown admin server: %v", err)
+		}
+		log.DropError(os.Remove(s.socket))
+	}()
+	log.Infof("admin server listens on %v", s.socket)
+	return nil
+}
+
+// WriteJSON writes the object as the JSON response
+func WriteJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Warnf("failed to write response: %v", err)
+	}
+}
+
+// WriteError writes the error as the JSON response
+func WriteError(w http.ResponseWriter, code int, err error) {
+	WriteJSON(w, code, &ErrorResponse{Error: err.Error()})
+}
+
+// allowGet only allows the GET method and returns false if the method is not allowed
+func allowGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet {
+		return true
+	}
+	WriteError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %v is not allowed", r.Method))
+	return false
+}
+
+// listPods returns the pods sorted by namespace and name, the pods can be filtered by the namespace
+func (s *Server) listPods(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	namespace := r.URL.Query().Get("namespace")
+	var pods = make([]*typedef.PodInfo, 0)
+	for _, pod := range s.source.ListPods() {
+		if namespace == "" || pod.Namespace == namespace {
+			pods = append(pods, pod)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+	WriteJSON(w, http.StatusOK, pods)
+}
+
+// getPod returns the details of the pod at PodsPath/<uid>, or explains the pod at PodsPath/<uid>/explain
+func (s *Server) getPod(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	uid := strings.TrimPrefix(r.URL.Path, PodsPath+"/")
+	explain := strings.HasSuffix(uid, ExplainSuffix)
+	uid = strings.TrimSuffix(uid, ExplainSuffix)
+	pod, ok := s.source.ListPods()[uid]
+	if !ok {
+		WriteError(w, http.StatusNotFound, fmt.Errorf("pod %v not found", uid))
+		return
+	}
+	if explain {
+		WriteJSON(w, http.StatusOK, newPodExplanation(pod, s.source.ListServices()))
+		return
+	}
+	WriteJSON(w, http.StatusOK, newPodDetail(pod))
+}
+
+// listServices returns the status of services sorted by name
+func (s *Server) listServices(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	services := s.source.ListServices()
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+	WriteJSON(w, http.StatusOK, services)
+}
+
+// controlService changes the state of the service at ServicesPath/<name>/<action>
+func (s *Server) controlService(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %v is not allowed", r.Method))
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, ServicesPath+"/"), "/")
+	const partsLen = 2
+	if len(parts) != partsLen || parts[0] == "" {
+		WriteError(w, http.StatusNotFound, fmt.Errorf("expect %v/<name>/<action>", ServicesPath))
+		return
+	}
+	name, action := parts[0], ServiceAction(parts[1])
+	switch action {
+	case PauseService, ResumeService, StopService, StartService:
+	default:
+		WriteError(w, http.StatusBadRequest, fmt.Errorf("unknown action %v", action))
+		return
+	}
+	if err := s.source.ControlService(name, action); err != nil {
+		code := http.StatusConflict
+		if _, ok := err.(ServiceNotFoundError); ok {
+			code = http.StatusNotFound
+		}
+		WriteError(w, code, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getConfig returns the configuration in the format of the configuration file,
+// the agent configuration is filled with the default values
+func (s *Server) getConfig(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	c := s.source.Config()
+	var fields = make(map[string]interface{}, len(c.Fields)+1)
+	for k, v := range c.Fields {
+		fields[k] = v
+	}
+	fields["agent"] = c.Agent
+	WriteJSON(w, http.StatusOK, fields)
+}
+
+// validateConfig checks the configuration in the request body without applying it
+func (s *Server) validateConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %v is not allowed", r.Method))
+		return
+	}
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, fmt.Errorf("failed to read request: %v", err))
+		return
+	}
+	if err := s.source.ValidateConfig(data); err != nil {
+		WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// queryAudit returns the latest writes in the audit journal matching the query parameters
+func (s *Server) queryAudit(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	if !audit.Enabled() {
+		WriteError(w, http.StatusNotFound, fmt.Errorf("audit is not enabled"))
+		return
+	}
+	params := r.URL.Query()
+	q := audit.Query{
+		PodUID:  params.Get(AuditPodParam),
+		Service: params.Get(AuditServiceParam),
+		File:    params.Get(AuditFileParam),
+	}
+	if since := params.Get(AuditSinceParam); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid %v: %v", AuditSinceParam, err))
+			return
+		}
+		q.Since = t
+	}
+	if limit := params.Get(AuditLimitParam); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid %v: %v", AuditLimitParam, limit))
+			return
+		}
+		q.Limit = n
+	}
+	records, err := audit.Search(q)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, records)
+}
@@ -0,0 +1,310 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file tests the admin API server
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/common/util"
+	"isula.org/rubik/pkg/config"
+	"isula.org/rubik/pkg/core/typedef"
+	"isula.org/rubik/pkg/core/typedef/cgroup"
+)
+
+type fakeSource struct {
+	pods     map[string]*typedef.PodInfo
+	services []ServiceStatus
+	config   *config.Config
+}
+
+func (s *fakeSource) ListPods() map[string]*typedef.PodInfo {
+	return s.pods
+}
+
+func (s *fakeSource) ListServices() []ServiceStatus {
+	return s.services
+}
+
+func (s *fakeSource) Config() *config.Config {
+	return s.config
+}
+
+func (s *fakeSource) ValidateConfig(data []byte) error {
+	return config.NewConfig(config.JSON).LoadConfigData(data)
+}
+
+func (s *fakeSource) ControlService(name string, action ServiceAction) error {
+	for i := range s.services {
+		if s.services[i].Name != name {
+			continue
+		}
+		if action == PauseService && s.services[i].State == ServicePaused {
+			return fmt.Errorf("service %v is %v", name, ServicePaused)
+		}
+		s.services[i].State = ServicePaused
+		return nil
+	}
+	return ServiceNotFoundError(name)
+}
+
+func (s *fakeSource) LivenessChecks() []HealthCheck {
+	return []HealthCheck{{Name: "informer", Healthy: true}}
+}
+
+func (s *fakeSource) ReadinessChecks() []HealthCheck {
+	return []HealthCheck{{Name: "informer", Healthy: true}, {Name: "services", Message: "services are not pre-started"}}
+}
+
+func newFakeSource() *fakeSource {
+	c := config.NewConfig(config.JSON)
+	c.Fields = map[string]interface{}{"preemption": map[string]interface{}{"resource": []string{"cpu"}}}
+	return &fakeSource{
+		pods: map[string]*typedef.PodInfo{
+			"uid-b": {Name: "b", UID: "uid-b", Namespace: "default"},
+			"uid-a": {Name: "a", UID: "uid-a", Namespace: "default"},
+			"uid-c": {Name: "c", UID: "uid-c", Namespace: "kube-system"},
+		},
+		services: []ServiceStatus{
+			{Name: "quotaTurbo", Runner: true, Running: true, Restarts: 1},
+			{Name: "preemption"},
+		},
+		config: c,
+	}
+}
+
+// TestServer_Handlers tests the handlers of admin API
+func TestServer_Handlers(t *testing.T) {
+	defer os.RemoveAll(constant.TmpTestDir)
+	source := newFakeSource()
+	pod := source.pods["uid-a"]
+	pod.Hierarchy = cgroup.Hierarchy{MountPoint: constant.TmpTestDir, Path: "kubepods/poda", Owner: "preemption"}
+	assert.NoError(t, util.WriteFile(filepath.Join(pod.AbsolutePath("cpu"), constant.CPUCgroupFileName), "0"))
+	assert.NoError(t, pod.SetCgroupAttr(&cgroup.Key{SubSys: "cpu", FileName: constant.CPUCgroupFileName}, "-1"))
+	defer cgroup.ForgetWrites(pod.Path)
+	s := NewServer("", source)
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		code   int
+		check  func(t *testing.T, body []byte)
+	}{
+		{
+			name:   "TC1-list pods sorted by namespace and name",
+			method: http.MethodGet,
+			path:   PodsPath,
+			code:   http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var pods []*typedef.PodInfo
+				assert.NoError(t, json.Unmarshal(body, &pods))
+				assert.Len(t, pods, 3)
+				assert.Equal(t, []string{"a", "b", "c"}, []string{pods[0].Name, pods[1].Name, pods[2].Name})
+			},
+		},
+		{
+			name:   "TC2-list pods of namespace",
+			method: http.MethodGet,
+			path:   PodsPath + "?namespace=kube-system",
+			code:   http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var pods []*typedef.PodInfo
+				assert.NoError(t, json.Unmarshal(body, &pods))
+				assert.Len(t, pods, 1)
+			},
+		},
+		{
+			name:   "TC3-get pod",
+			method: http.MethodGet,
+			path:   PodsPath + "/uid-a",
+			code:   http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var pod PodDetail
+				assert.NoError(t, json.Unmarshal(body, &pod))
+				assert.Equal(t, "a", pod.Name)
+				assert.Equal(t, filepath.Join(constant.TmpTestDir, "cpu", "kubepods/poda"), pod.Cgroup.Paths["cpu"])
+				assert.Equal(t, map[string]string{constant.CPUCgroupFileName: "-1"}, pod.Cgroup.Values)
+			},
+		},
+		{
+			name:   "TC4-get non-existed pod",
+			method: http.MethodGet,
+			path:   PodsPath + "/uid-x",
+			code:   http.StatusNotFound,
+			check: func(t *testing.T, body []byte) {
+				var resp ErrorResponse
+				assert.NoError(t, json.Unmarshal(body, &resp))
+				assert.Contains(t, resp.Error, "uid-x")
+			},
+		},
+		{
+			name:   "TC5-list services sorted by name",
+			method: http.MethodGet,
+			path:   ServicesPath,
+			code:   http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var services []ServiceStatus
+				assert.NoError(t, json.Unmarshal(body, &services))
+				assert.Len(t, services, 2)
+				assert.Equal(t, "preemption", services[0].Name)
+				assert.Equal(t, int64(1), services[1].Restarts)
+			},
+		},
+		{
+			name:   "TC6-get config with default agent config",
+			method: http.MethodGet,
+			path:   ConfigPath,
+			code:   http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var fields map[string]map[string]interface{}
+				assert.NoError(t, json.Unmarshal(body, &fields))
+				assert.Equal(t, constant.DefaultCgroupRoot, fields["agent"]["cgroupRoot"])
+				assert.Contains(t, fields, "preemption")
+			},
+		},
+		{
+			name:   "TC7-method not allowed",
+			method: http.MethodPost,
+			path:   ServicesPath,
+			code:   http.StatusMethodNotAllowed,
+		},
+		{
+			name:   "TC8-explain pod",
+			method: http.MethodGet,
+			path:   PodsPath + "/uid-a" + ExplainSuffix,
+			code:   http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var explanation PodExplanation
+				assert.NoError(t, json.Unmarshal(body, &explanation))
+				assert.Equal(t, []string{"preemption", "quotaTurbo"}, explanation.Services)
+				assert.Len(t, explanation.Writes, 1)
+				assert.Equal(t, "preemption", explanation.Writes[0].Owner)
+				assert.Equal(t, "-1", explanation.Writes[0].Value)
+			},
+		},
+		{
+			name:   "TC9-explain non-existed pod",
+			method: http.MethodGet,
+			path:   PodsPath + "/uid-x" + ExplainSuffix,
+			code:   http.StatusNotFound,
+		},
+		{
+			name:   "TC10-query audit without enabling audit",
+			method: http.MethodGet,
+			path:   AuditPath + "?pod=uid-a",
+			code:   http.StatusNotFound,
+		},
+		{
+			name:   "TC11-pause service",
+			method: http.MethodPost,
+			path:   ServicesPath + "/preemption/" + string(PauseService),
+			code:   http.StatusNoContent,
+		},
+		{
+			name:   "TC12-pause paused service",
+			method: http.MethodPost,
+			path:   ServicesPath + "/preemption/" + string(PauseService),
+			code:   http.StatusConflict,
+		},
+		{
+			name:   "TC13-control non-existed service",
+			method: http.MethodPost,
+			path:   ServicesPath + "/cpuevict/" + string(StopService),
+			code:   http.StatusNotFound,
+		},
+		{
+			name:   "TC14-unknown action",
+			method: http.MethodPost,
+			path:   ServicesPath + "/preemption/restart",
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:   "TC15-alive",
+			method: http.MethodGet,
+			path:   HealthzPath,
+			code:   http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp HealthResponse
+				assert.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, "ok", resp.Status)
+			},
+		},
+		{
+			name:   "TC16-not ready",
+			method: http.MethodGet,
+			path:   ReadyzPath,
+			code:   http.StatusServiceUnavailable,
+			check: func(t *testing.T, body []byte) {
+				var resp HealthResponse
+				assert.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, "failed", resp.Status)
+				assert.Len(t, resp.Checks, 2)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s.mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			assert.Equal(t, tt.code, w.Code)
+			if tt.check != nil {
+				tt.check(t, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+// TestServer_Start tests serving over the unix socket
+func TestServer_Start(t *testing.T) {
+	defer os.RemoveAll(constant.TmpTestDir)
+	socket := filepath.Join(constant.TmpTestDir, "rubik.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, NewServer(socket, newFakeSource()).Start(ctx))
+	// the socket left by the previous server is replaced
+	assert.NoError(t, NewServer(socket, newFakeSource()).Start(ctx))
+
+	client := NewClient(socket)
+	services, err := client.ListServices()
+	assert.NoError(t, err)
+	assert.Len(t, services, 2)
+	pods, err := client.ListPods("kube-system")
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+	pod, err := client.GetPod("uid-b")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", pod.Name)
+	_, err = client.GetPod("uid-x")
+	assert.EqualError(t, err, "pod uid-x not found")
+	explanation, err := client.ExplainPod("uid-b")
+	assert.NoError(t, err)
+	assert.Empty(t, explanation.Writes)
+	fields, err := client.GetConfig()
+	assert.NoError(t, err)
+	assert.Contains(t, fields, "agent")
+	assert.NoError(t, client.ValidateConfig([]byte(`{"agent": {"logLevel": "debug"}}`)))
+	assert.Error(t, client.ValidateConfig([]byte(`{"agent": []}`)))
+
+	_, err = NewClient(filepath.Join(constant.TmpTestDir, "missing.sock")).ListServices()
+	assert.Error(t, err)
+}
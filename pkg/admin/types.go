@@ -0,0 +1,168 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file defines the data exposed by the admin API
+
+package admin
+
+import (
+	"fmt"
+	"time"
+
+	"isula.org/rubik/pkg/config"
+	"isula.org/rubik/pkg/core/typedef"
+	"isula.org/rubik/pkg/core/typedef/cgroup"
+)
+
+// the paths of the admin API
+const (
+	// PodsPath lists the pods cached by rubik, PodsPath/<uid> gets a single pod
+	PodsPath = "/v1/pods"
+	// ServicesPath lists the running services, ServicesPath/<name>/<action> changes the state of a service
+	ServicesPath = "/v1/services"
+	// ConfigPath gets the configuration in use
+	ConfigPath = "/v1/config"
+	// ValidateConfigPath checks the configuration in the request body without applying it
+	ValidateConfigPath = ConfigPath + "/validate"
+	// ExplainSuffix follows PodsPath/<uid> to explain what rubik did to the pod
+	ExplainSuffix = "/explain"
+	// AuditPath queries the writes recorded in the audit journal
+	AuditPath = "/v1/audit"
+	// HealthzPath returns whether rubik is alive, it fails if rubik needs to be restarted
+	HealthzPath = "/healthz"
+	// ReadyzPath returns whether rubik is ready to manage the pods
+	ReadyzPath = "/readyz"
+)
+
+// the query parameters of AuditPath
+const (
+	AuditPodParam     = "pod"
+	AuditServiceParam = "service"
+	AuditFileParam    = "file"
+	// AuditSinceParam is the time in RFC3339 format
+	AuditSinceParam = "since"
+	AuditLimitParam = "limit"
+)
+
+// ServiceAction changes the state of an enabled service at runtime
+type ServiceAction string
+
+const (
+	// PauseService stops running the service and handling the pods, the values written are kept
+	PauseService ServiceAction = "pause"
+	// ResumeService resumes the paused service
+	ResumeService ServiceAction = "resume"
+	// StopService stops and terminates the service, the values written are restored
+	StopService ServiceAction = "stop"
+	// StartService pre-starts the stopped service with the current pods and runs it
+	StartService ServiceAction = "start"
+)
+
+// the states of the services changed at runtime
+const (
+	ServicePaused  = "paused"
+	ServiceStopped = "stopped"
+)
+
+// ServiceNotFoundError indicates the service is not enabled
+type ServiceNotFoundError string
+
+func (e ServiceNotFoundError) Error() string {
+	return fmt.Sprintf("service %v is not enabled", string(e))
+}
+
+// ServiceStatus is the status of a service managed by rubik
+type ServiceStatus struct {
+	Name string `json:"name"`
+	// Runner indicates whether the service is a persistent service
+	Runner bool `json:"runner"`
+	// Running indicates whether the persistent service is running
+	Running bool `json:"running"`
+	// Restarts is the number of times the persistent service is restarted
+	Restarts int64 `json:"restarts"`
+	// Panics is the number of panics caught while the service is running
+	Panics        int64      `json:"panics"`
+	LastPanic     string     `json:"lastPanic,omitempty"`
+	LastPanicTime *time.Time `json:"lastPanicTime,omitempty"`
+	// State is ServicePaused or ServiceStopped if the service is paused or stopped at runtime
+	State string `json:"state,omitempty"`
+	// Degraded indicates the optional service failed to pre-start and is being retried,
+	// LastError is the last failure and Retries is the number of retries
+	Degraded  bool        `json:"degraded,omitempty"`
+	LastError string      `json:"lastError,omitempty"`
+	Retries   int64       `json:"retries,omitempty"`
+	Config    interface{} `json:"config,omitempty"`
+}
+
+// CgroupDetail is the resolved cgroup paths and the current values of the qos related cgroup files
+type CgroupDetail struct {
+	// Paths are the absolute cgroup paths indexed by the subsystem
+	Paths map[string]string `json:"paths"`
+	// Values are the current values indexed by the file name, the files not existed are omitted
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// PodDetail is the pod with the cgroup details of itself and its containers
+type PodDetail struct {
+	*typedef.PodInfo
+	Cgroup CgroupDetail `json:"cgroup"`
+	// ContainerCgroups are the cgroup details of the containers indexed by the container ID
+	ContainerCgroups map[string]CgroupDetail `json:"containerCgroups,omitempty"`
+}
+
+// PodExplanation explains what rubik did to the pod
+type PodExplanation struct {
+	Name      string `json:"name"`
+	UID       string `json:"uid"`
+	Namespace string `json:"namespace"`
+	// Services are the running services
+	Services []string `json:"services"`
+	// Writes are the latest values written to the cgroup files of the pod and its containers
+	Writes []cgroup.WriteRecord `json:"writes"`
+}
+
+// HealthCheck is the result of a health check
+type HealthCheck struct {
+	Name string `json:"name"`
+	// Message is the reason why the check fails
+	Message string `json:"message,omitempty"`
+	Healthy bool   `json:"healthy"`
+}
+
+// HealthResponse is returned by HealthzPath and ReadyzPath
+type HealthResponse struct {
+	// Status is "ok" if all checks pass, otherwise "failed"
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+// ErrorResponse is returned when the request fails
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Source provides the runtime information of rubik exposed by the admin API
+type Source interface {
+	// ListPods returns the pods cached by rubik
+	ListPods() map[string]*typedef.PodInfo
+	// ListServices returns the status of the running services
+	ListServices() []ServiceStatus
+	// Config returns the configuration in use
+	Config() *config.Config
+	// ValidateConfig checks the configuration data in the format of the configuration file
+	ValidateConfig(data []byte) error
+	// LivenessChecks returns the checks failing when rubik needs to be restarted
+	LivenessChecks() []HealthCheck
+	// ReadinessChecks returns the checks failing before rubik is ready to manage the pods
+	ReadinessChecks() []HealthCheck
+	// ControlService changes the state of the enabled service
+	ControlService(name string, action ServiceAction) error
+}
@@ -33,19 +33,19 @@ type Viewer interface {
 type Publisher interface {
 	Subscribe(s Subscriber) error
 	Unsubscribe(s Subscriber)
-	Publish(topic typedef.EventType, event typedef.Event)
+	Publish(event typedef.Event)
 }
 
 // Subscriber is a common interface for subscribers
 type Subscriber interface {
 	ID() string
-	NotifyFunc(eventType typedef.EventType, event typedef.Event)
+	NotifyFunc(event typedef.Event)
 	TopicsFunc() []typedef.EventType
 }
 
 // EventHandler is the processing interface for change events
 type EventHandler interface {
-	HandleEvent(eventType typedef.EventType, event typedef.Event)
+	HandleEvent(event typedef.Event)
 	EventTypes() []typedef.EventType
 }
 
@@ -54,3 +54,9 @@ type Informer interface {
 	Publisher
 	Start(ctx context.Context) error
 }
+
+// HealthChecker is implemented by the informer which detects that it stops receiving the pod events
+type HealthChecker interface {
+	// Healthy returns the reason why the informer stops receiving the pod events, nil if it is healthy
+	Healthy() error
+}
@@ -0,0 +1,224 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements the single path writing the kernel interface files with auditing
+
+// Package audit records the writes of rubik to the cgroup, resctrl and /proc files in a journal
+package audit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"isula.org/rubik/pkg/common/util"
+)
+
+const (
+	// maxValueSize is the maximum size of the old value read before writing
+	maxValueSize = 4096
+	// journalName is the file name of the journal in the journal directory
+	journalName = "audit.jsonl"
+	// minJournalSize and maxJournalSize are the range of the size of the journal in MB
+	minJournalSize int64 = 1
+	maxJournalSize int64 = 1024
+	unitMB         int64 = 1024 * 1024
+)
+
+// actionFiles are the files on which writing performs an action instead of replacing the value,
+// such as moving a task to the group listed by the file, so the values of them are not restored
+var actionFiles = map[string]struct{}{
+	"tasks":           {},
+	"cgroup.procs":    {},
+	"cgroup.threads":  {},
+	"net_qos_enable":  {},
+	"net_qos_disable": {},
+}
+
+// LogFunc logs the message in the format of fmt.Printf, the package does not depend on the log package
+// because the log package is below the cgroup package which writes the files through this package
+type LogFunc func(format string, args ...interface{})
+
+// Origin is the service writing the file and the pod the file belongs to, which are empty if unknown
+type Origin struct {
+	Service string
+	PodUID  string
+}
+
+// Record is a write recorded in the journal
+type Record struct {
+	Time    time.Time `json:"time"`
+	Service string    `json:"service,omitempty"`
+	PodUID  string    `json:"podUID,omitempty"`
+	File    string    `json:"file"`
+	// Old is the value before writing, it is empty for the action files or the unreadable files
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+var (
+	defaultJournal *journal
+	journalSync    sync.RWMutex
+)
+
+// CheckConfig checks the directory and the size in MB of the journal
+func CheckConfig(dir string, size int64) error {
+	if !filepath.IsAbs(dir) {
+		return fmt.Errorf("invalid path, audit directory must be an absolute path: %v", dir)
+	}
+	if size < minJournalSize || size > maxJournalSize {
+		return fmt.Errorf("invalid audit size: %d (valid range is %d-%d)", size, minJournalSize, maxJournalSize)
+	}
+	return nil
+}
+
+// Init starts recording the writes in the journal under the directory, the failures of the journal are reported by warnf.
+// The journal is rotated once it exceeds the size in MB, and only the last rotated journal is kept.
+func Init(dir string, size int64, warnf LogFunc) error {
+	if err := CheckConfig(dir, size); err != nil {
+		return err
+	}
+	j, err := openJournal(filepath.Join(dir, journalName), size*unitMB, warnf)
+	if err != nil {
+		return err
+	}
+	journalSync.Lock()
+	old := defaultJournal
+	defaultJournal = j
+	journalSync.Unlock()
+	if old != nil {
+		return old.close()
+	}
+	return nil
+}
+
+// Close stops recording the writes
+func Close() error {
+	journalSync.Lock()
+	j := defaultJournal
+	defaultJournal = nil
+	journalSync.Unlock()
+	if j == nil {
+		return nil
+	}
+	return j.close()
+}
+
+// Enabled returns true if the writes are recorded in the journal
+func Enabled() bool {
+	journalSync.RLock()
+	defer journalSync.RUnlock()
+	return defaultJournal != nil
+}
+
+// Search returns the recorded writes matching the query in time order
+func Search(q Query) ([]Record, error) {
+	journalSync.RLock()
+	j := defaultJournal
+	journalSync.RUnlock()
+	if j == nil {
+		return nil, fmt.Errorf("audit is not enabled")
+	}
+	return j.search(q)
+}
+
+// WriteFile writes the value to the file, the write is recorded in the journal if it changes the file.
+// The original value of the file is kept to be restored by Revert after the service stops.
+// In dry-run mode, the file is not written and the change is logged instead.
+func WriteFile(origin Origin, path, value string) error {
+	return write(origin, path, value, true)
+}
+
+// write writes the value to the file, the original value is kept if revertible is true.
+// The action files are never read because they may list thousands of tasks,
+// so writing them is always recorded with the empty old value.
+func write(origin Origin, path, value string, revertible bool) error {
+	_, action := actionFiles[filepath.Base(path)]
+	if DryRun() {
+		if action {
+			Discard(origin, "write %q to %v", value, path)
+		} else if old := readValue(path); old != strings.TrimSpace(value) {
+			Discard(origin, "write %q to %v (current %q)", value, path, old)
+		}
+		return nil
+	}
+
+	journalSync.RLock()
+	j := defaultJournal
+	journalSync.RUnlock()
+	// the original value is only kept when the file is written by the service for the first time
+	track := revertible && !action && origin.Service != "" && !defaultReverts.touch(origin, path)
+	var (
+		old     string
+		changed = true
+	)
+	if !action && (track || j != nil) {
+		old = readValue(path)
+		changed = old != strings.TrimSpace(value)
+	}
+	if err := util.WriteFile(path, value); err != nil {
+		return err
+	}
+	if track {
+		defaultReverts.add(origin, path, old, false)
+	}
+	if j == nil || !changed {
+		return nil
+	}
+	j.append(&Record{
+		Time:    time.Now(),
+		Service: origin.Service,
+		PodUID:  origin.PodUID,
+		File:    filepath.Clean(path),
+		Old:     old,
+		New:     value,
+	})
+	return nil
+}
+
+// Mkdir creates the directory if it does not exist, for example the resctrl group.
+// The directory created is removed by Revert after the service stops.
+func Mkdir(origin Origin, path string, perm os.FileMode) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if Discard(origin, "create directory %v", path) {
+		return nil
+	}
+	if err := os.Mkdir(path, perm); err != nil {
+		if os.IsExist(err) {
+			return nil
+		}
+		return err
+	}
+	if origin.Service != "" {
+		defaultReverts.add(origin, path, "", true)
+	}
+	return nil
+}
+
+// readValue reads the current value of the file, the value is empty if the file cannot be read
+func readValue(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxValueSize))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,218 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file tests the audit journal
+
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/common/util"
+)
+
+// TestWriteFile tests recording the writes which change the files
+func TestWriteFile(t *testing.T) {
+	dir := constant.TmpTestDir
+	defer os.RemoveAll(dir)
+	quota := filepath.Join(dir, "cpu.cfs_quota_us")
+	tasks := filepath.Join(dir, "tasks")
+	assert.NoError(t, util.WriteFile(quota, "-1\n"))
+	assert.NoError(t, util.WriteFile(tasks, "1\n"))
+	preemption := Origin{Service: "preemption", PodUID: "uid-a"}
+
+	// the writes are not recorded before the journal is enabled
+	assert.NoError(t, WriteFile(preemption, quota, "10000"))
+	_, err := Search(Query{})
+	assert.Error(t, err)
+
+	assert.NoError(t, Init(dir, 1, t.Errorf))
+	defer Close()
+	assert.True(t, Enabled())
+	assert.NoError(t, WriteFile(preemption, quota, "20000"))
+	// the unchanged value is not recorded
+	assert.NoError(t, WriteFile(preemption, quota, "20000"))
+	// the task files are not read, so every write to them is recorded
+	assert.NoError(t, WriteFile(preemption, tasks, "1"))
+	assert.NoError(t, WriteFile(Origin{Service: "dynCache"}, tasks, "2"))
+	// the failed write is not recorded
+	assert.Error(t, WriteFile(preemption, dir, "2"))
+
+	records, err := Search(Query{})
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, "preemption", records[0].Service)
+	assert.Equal(t, "uid-a", records[0].PodUID)
+	assert.Equal(t, quota, records[0].File)
+	assert.Equal(t, "10000", records[0].Old)
+	assert.Equal(t, "20000", records[0].New)
+	assert.Equal(t, "", records[1].Old)
+	assert.Equal(t, "1", records[1].New)
+	assert.Equal(t, "", records[2].Old)
+	assert.Equal(t, "2", records[2].New)
+
+	records, err = Search(Query{PodUID: "uid-a", File: "cfs_quota"})
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	records, err = Search(Query{Service: "dynCache"})
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	records, err = Search(Query{Since: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.Len(t, records, 0)
+	records, err = Search(Query{Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{tasks}, []string{records[0].File})
+}
+
+// TestJournalRotate tests keeping the latest records within the size after rotation
+func TestJournalRotate(t *testing.T) {
+	dir := constant.TmpTestDir
+	defer os.RemoveAll(dir)
+	const maxSize = 1024
+	j, err := openJournal(filepath.Join(dir, journalName), maxSize, t.Errorf)
+	assert.NoError(t, err)
+	defer j.close()
+
+	const total = 100
+	for i := 0; i < total; i++ {
+		j.append(&Record{Time: time.Now(), Service: "quotaTurbo", File: "cpu.cfs_quota_us", New: fmt.Sprint(i)})
+	}
+	for _, path := range []string{j.path, j.rotated()} {
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(maxSize))
+	}
+	records, err := j.search(Query{Limit: total})
+	assert.NoError(t, err)
+	assert.Less(t, len(records), total)
+	assert.Equal(t, fmt.Sprint(total-1), records[len(records)-1].New)
+
+	records, err = j.search(Query{Limit: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"97", "98", "99"}, []string{records[0].New, records[1].New, records[2].New})
+}
+
+// TestCheckConfig tests checking the directory and the size of the journal
+func TestCheckConfig(t *testing.T) {
+	assert.NoError(t, CheckConfig("/var/log/rubik", 16))
+	assert.Error(t, CheckConfig("log", 16))
+	assert.Error(t, CheckConfig("/var/log/rubik", 0))
+	assert.Error(t, CheckConfig("/var/log/rubik", maxJournalSize+1))
+}
+
+// TestDryRun tests logging the changes without applying them in dry-run mode
+func TestDryRun(t *testing.T) {
+	dir := constant.TmpTestDir
+	defer os.RemoveAll(dir)
+	quota := filepath.Join(dir, "cpu.cfs_quota_us")
+	assert.NoError(t, util.WriteFile(quota, "-1"))
+	origin := Origin{Service: "quotaTurbo", PodUID: "uid-a"}
+	assert.False(t, Discard(origin, "evict pod %v", "a"))
+
+	var logs []string
+	SetDryRun(func(format string, args ...interface{}) {
+		logs = append(logs, fmt.Sprintf(format, args...))
+	})
+	defer SetDryRun(nil)
+	assert.True(t, DryRun())
+	assert.NoError(t, Init(dir, 1, t.Errorf))
+	defer Close()
+
+	assert.NoError(t, WriteFile(origin, quota, "20000"))
+	// the unchanged value is not logged
+	assert.NoError(t, WriteFile(origin, quota, "-1"))
+	// the task file is logged without reading it
+	tasks := filepath.Join(dir, "tasks")
+	assert.NoError(t, WriteFile(origin, tasks, "1"))
+	group := filepath.Join(dir, "rubik_high")
+	assert.NoError(t, Mkdir(Origin{Service: "dynCache"}, group, constant.DefaultDirMode))
+	assert.True(t, Discard(Origin{}, "evict pod %v", "a"))
+
+	value, err := util.ReadFile(quota)
+	assert.NoError(t, err)
+	assert.Equal(t, "-1", string(value))
+	assert.False(t, util.PathExist(group))
+	assert.False(t, util.PathExist(tasks))
+	assert.Equal(t, []string{
+		`dry run: quotaTurbo would write "20000" to ` + quota + ` (current "-1") for pod uid-a`,
+		`dry run: quotaTurbo would write "1" to ` + tasks + ` for pod uid-a`,
+		"dry run: dynCache would create directory " + group,
+		"dry run: rubik would evict pod a",
+	}, logs)
+	// the discarded writes are not recorded in the journal
+	records, err := Search(Query{})
+	assert.NoError(t, err)
+	assert.Len(t, records, 0)
+}
+
+// TestRevert tests restoring the original states after all services changing them stop
+func TestRevert(t *testing.T) {
+	// the files written by the other tests are not reverted
+	defaultReverts = &revertJournal{entries: make(map[string]*revertEntry)}
+	dir := constant.TmpTestDir
+	defer os.RemoveAll(dir)
+	quota := filepath.Join(dir, "cpu.cfs_quota_us")
+	high := filepath.Join(dir, "memory.high")
+	throttle := filepath.Join(dir, "blkio.throttle.read_bps_device")
+	assert.NoError(t, util.WriteFile(quota, "-1"))
+	assert.NoError(t, util.WriteFile(high, "max"))
+	assert.NoError(t, util.WriteFile(throttle, ""))
+	RegisterRestorer(filepath.Base(throttle), func(original, current string) []string {
+		return []string{"8:0 0"}
+	})
+	quotaTurbo := Origin{Service: "quotaTurbo", PodUID: "uid-a"}
+	dynMemory := Origin{Service: "dynMemory", PodUID: "uid-a"}
+	expect := func(path, value string) {
+		data, err := util.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, value, string(data))
+	}
+
+	// the original value is kept when the file is written for the first time
+	assert.NoError(t, WriteFile(quotaTurbo, quota, "10000"))
+	assert.NoError(t, WriteFile(quotaTurbo, quota, "20000"))
+	assert.NoError(t, WriteFile(dynMemory, quota, "30000"))
+	assert.NoError(t, WriteFile(dynMemory, high, "1024"))
+	assert.NoError(t, WriteFile(dynMemory, throttle, "8:0 1024"))
+	// the writes without the service are not reverted
+	assert.NoError(t, WriteFile(Origin{}, filepath.Join(dir, "cpu.shares"), "2"))
+	group := filepath.Join(dir, "rubik_high")
+	assert.NoError(t, Mkdir(Origin{Service: "dynCache"}, group, constant.DefaultDirMode))
+	assert.NoError(t, WriteFile(Origin{Service: "dynCache"}, filepath.Join(group, "schemata"), "L3:0=f"))
+
+	// the file shared by the services is restored after both services stop
+	assert.NoError(t, Revert("quotaTurbo"))
+	expect(quota, "30000")
+	assert.NoError(t, Revert("dynMemory"))
+	expect(quota, "-1")
+	expect(high, "max")
+	expect(throttle, "8:0 0")
+	assert.NoError(t, Revert("dynMemory"))
+
+	// the directory created is removed after the files in it are restored
+	assert.NoError(t, os.Remove(filepath.Join(group, "schemata")))
+	assert.NoError(t, Revert("dynCache"))
+	assert.False(t, util.PathExist(group))
+
+	// the files of the removed pod are forgotten
+	assert.NoError(t, WriteFile(quotaTurbo, quota, "10000"))
+	ForgetPod("uid-a")
+	assert.NoError(t, Revert("quotaTurbo"))
+	expect(quota, "10000")
+}
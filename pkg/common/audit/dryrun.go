@@ -0,0 +1,65 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements the dry-run mode discarding the changes of rubik on the node
+
+package audit
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	// dryRunLogf logs the discarded changes, the changes are applied if it is nil
+	dryRunLogf LogFunc
+	dryRunSync sync.RWMutex
+)
+
+// SetDryRun enables the dry-run mode if logf is not nil, in which the writes and the evictions are
+// not applied and logged by logf, while the files are still read from the system
+func SetDryRun(logf LogFunc) {
+	dryRunSync.Lock()
+	dryRunLogf = logf
+	dryRunSync.Unlock()
+}
+
+// DryRun returns true if the changes are discarded
+func DryRun() bool {
+	return dryRunLogger() != nil
+}
+
+// Discard logs the action not applied in dry-run mode and returns true, or returns false if the action should be applied
+func Discard(origin Origin, format string, args ...interface{}) bool {
+	logf := dryRunLogger()
+	if logf == nil {
+		return false
+	}
+	action := fmt.Sprintf(format, args...)
+	if origin.PodUID != "" {
+		action += " for pod " + origin.PodUID
+	}
+	logf("dry run: %v would %v", serviceName(origin), action)
+	return true
+}
+
+func dryRunLogger() LogFunc {
+	dryRunSync.RLock()
+	defer dryRunSync.RUnlock()
+	return dryRunLogf
+}
+
+func serviceName(origin Origin) string {
+	if origin.Service == "" {
+		return "rubik"
+	}
+	return origin.Service
+}
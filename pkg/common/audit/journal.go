@@ -0,0 +1,186 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements the bounded journal of the writes in JSON lines
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"isula.org/rubik/pkg/common/constant"
+)
+
+const (
+	// DefaultLimit is the number of the latest records returned if the limit of the query is not set
+	DefaultLimit = 100
+	// maxLineSize is the maximum size of a line in the journal
+	maxLineSize = 64 * 1024
+)
+
+// Query filters the records, the empty fields match all records
+type Query struct {
+	PodUID  string
+	Service string
+	// File matches the records whose file path contains it
+	File string
+	// Since matches the records written at or after it
+	Since time.Time
+	// Limit is the maximum number of the latest records returned
+	Limit int
+}
+
+func (q *Query) match(r *Record) bool {
+	return (q.PodUID == "" || r.PodUID == q.PodUID) &&
+		(q.Service == "" || r.Service == q.Service) &&
+		(q.File == "" || strings.Contains(r.File, q.File)) &&
+		!r.Time.Before(q.Since)
+}
+
+// journal appends the records to the file in JSON lines and rotates the file when it exceeds the size
+type journal struct {
+	sync.Mutex
+	path    string
+	maxSize int64
+	size    int64
+	file    *os.File
+	warnf   LogFunc
+}
+
+func openJournal(path string, maxSize int64, warnf LogFunc) (*journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), constant.DefaultDirMode); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %v", err)
+	}
+	j := &journal{path: path, maxSize: maxSize, warnf: warnf}
+	if err := j.open(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (j *journal) open() error {
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, constant.DefaultFileMode)
+	if err != nil {
+		return fmt.Errorf("failed to open audit journal: %v", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat audit journal: %v", err)
+	}
+	j.file, j.size = f, info.Size()
+	return nil
+}
+
+func (j *journal) rotated() string {
+	return j.path + ".1"
+}
+
+// append writes the record, the record is dropped with a warning if it fails to be written
+func (j *journal) append(r *Record) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		j.warnf("failed to encode audit record of %v: %v", r.File, err)
+		return
+	}
+	data = append(data, '\n')
+
+	j.Lock()
+	defer j.Unlock()
+	if j.file == nil {
+		return
+	}
+	if j.size+int64(len(data)) > j.maxSize && j.size > 0 {
+		if err := j.file.Close(); err != nil {
+			j.warnf("failed to close audit journal: %v", err)
+		}
+		j.file = nil
+		if err := os.Rename(j.path, j.rotated()); err != nil {
+			j.warnf("failed to rotate audit journal: %v", err)
+		}
+		if err := j.open(); err != nil {
+			j.warnf("%v", err)
+			return
+		}
+	}
+	n, err := j.file.Write(data)
+	j.size += int64(n)
+	if err != nil {
+		j.warnf("failed to write audit journal: %v", err)
+	}
+}
+
+// search reads the rotated journal and the current journal, and returns the latest records matching the query
+func (j *journal) search(q Query) ([]Record, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	j.Lock()
+	defer j.Unlock()
+	var records = make([]Record, 0)
+	for _, path := range []string{j.rotated(), j.path} {
+		var err error
+		if records, err = searchFile(path, &q, records); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// searchFile appends the matched records in the file to records, only the latest q.Limit records are kept
+func searchFile(path string, q *Query, records []Record) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return records, nil
+		}
+		return nil, fmt.Errorf("failed to open audit journal: %v", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			// the last line may be incomplete if rubik exits while writing
+			continue
+		}
+		if !q.match(&r) {
+			continue
+		}
+		records = append(records, r)
+		if len(records) > q.Limit {
+			records = records[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit journal: %v", err)
+	}
+	return records, nil
+}
+
+func (j *journal) close() error {
+	j.Lock()
+	defer j.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
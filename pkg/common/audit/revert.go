@@ -0,0 +1,173 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements the revert journal restoring the files changed by the services
+
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"isula.org/rubik/pkg/common/util"
+)
+
+// Restorer returns the values written in order to restore the file from the current value to the original value
+type Restorer func(original, current string) []string
+
+var (
+	restorers    = make(map[string]Restorer)
+	restorerSync sync.RWMutex
+)
+
+// RegisterRestorer registers the restorer of the files with the name,
+// the lines of the original value are written one by one by default
+func RegisterRestorer(fileName string, r Restorer) {
+	restorerSync.Lock()
+	restorers[fileName] = r
+	restorerSync.Unlock()
+}
+
+func restorerOf(path string) Restorer {
+	restorerSync.RLock()
+	defer restorerSync.RUnlock()
+	if r, ok := restorers[filepath.Base(path)]; ok {
+		return r
+	}
+	return restoreLines
+}
+
+// restoreLines writes the non-empty lines of the original value
+func restoreLines(original, _ string) []string {
+	var values []string
+	for _, line := range strings.Split(original, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			values = append(values, line)
+		}
+	}
+	return values
+}
+
+// revertEntry is the original state of a file or a directory changed by the services
+type revertEntry struct {
+	path     string
+	podUID   string
+	original string
+	// created indicates the directory is created by rubik, which is removed when reverting
+	created  bool
+	services map[string]struct{}
+}
+
+// revertJournal keeps the original states until all services changing them stop
+type revertJournal struct {
+	sync.Mutex
+	entries map[string]*revertEntry
+}
+
+var defaultReverts = &revertJournal{entries: make(map[string]*revertEntry)}
+
+// touch adds the service to the entry of the path and returns true if the original state has been kept
+func (r *revertJournal) touch(origin Origin, path string) bool {
+	r.Lock()
+	defer r.Unlock()
+	e, ok := r.entries[filepath.Clean(path)]
+	if ok {
+		e.services[origin.Service] = struct{}{}
+	}
+	return ok
+}
+
+// add keeps the original state of the path if it is changed for the first time, and adds the service to the entry
+func (r *revertJournal) add(origin Origin, path, original string, created bool) {
+	path = filepath.Clean(path)
+	r.Lock()
+	defer r.Unlock()
+	e, ok := r.entries[path]
+	if !ok {
+		e = &revertEntry{
+			path:     path,
+			podUID:   origin.PodUID,
+			original: original,
+			created:  created,
+			services: make(map[string]struct{}),
+		}
+		r.entries[path] = e
+	}
+	e.services[origin.Service] = struct{}{}
+}
+
+// release removes the service from the entries, and returns the entries not changed by other services
+func (r *revertJournal) release(service string) []*revertEntry {
+	r.Lock()
+	defer r.Unlock()
+	var released []*revertEntry
+	for path, e := range r.entries {
+		if _, ok := e.services[service]; !ok {
+			continue
+		}
+		delete(e.services, service)
+		if len(e.services) == 0 {
+			delete(r.entries, path)
+			released = append(released, e)
+		}
+	}
+	// the files are restored before removing the directories, and the nested directories are removed first
+	sort.Slice(released, func(i, j int) bool {
+		if released[i].created != released[j].created {
+			return !released[i].created
+		}
+		return released[i].path > released[j].path
+	})
+	return released
+}
+
+// Revert restores the files and removes the directories changed by the service to the original states
+// if they are not changed by other running services. The files removed are ignored.
+func Revert(service string) error {
+	var errs error
+	for _, e := range defaultReverts.release(service) {
+		origin := Origin{Service: service, PodUID: e.podUID}
+		if e.created {
+			if Discard(origin, "remove directory %v", e.path) {
+				continue
+			}
+			if err := os.Remove(e.path); err != nil && !os.IsNotExist(err) {
+				errs = util.AppendErr(errs, fmt.Errorf("failed to remove %v: %v", e.path, err))
+			}
+			continue
+		}
+		if !util.PathExist(e.path) {
+			continue
+		}
+		for _, value := range restorerOf(e.path)(e.original, readValue(e.path)) {
+			if err := write(origin, e.path, value, false); err != nil {
+				errs = util.AppendErr(errs, fmt.Errorf("failed to restore %v: %v", e.path, err))
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// ForgetPod drops the original states of the files of the pod, which are removed with the pod
+func ForgetPod(uid string) {
+	defaultReverts.Lock()
+	defer defaultReverts.Unlock()
+	for path, e := range defaultReverts.entries {
+		if e.podUID == uid {
+			delete(defaultReverts.entries, path)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements the checkpoint file saving the states of the services
+
+// Package checkpoint saves the versioned states of the services to restore them after rubik restarts
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"isula.org/rubik/pkg/common/constant"
+)
+
+// fileName is the name of the checkpoint file in the checkpoint directory
+const fileName = "checkpoint.json"
+
+// State is the state of a service saved in the checkpoint
+type State struct {
+	// Version is the version of the format of the data defined by the service
+	Version int `json:"version"`
+	// Time is the time when the state is saved
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data"`
+}
+
+// NewState encodes the state of the service in the version
+func NewState(version int, v interface{}) (*State, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode state: %v", err)
+	}
+	return &State{Version: version, Time: time.Now(), Data: data}, nil
+}
+
+// Decode decodes the state into v, the state saved in another version is not decoded
+func (s *State) Decode(version int, v interface{}) error {
+	if s.Version != version {
+		return fmt.Errorf("unsupported state version %d, expect %d", s.Version, version)
+	}
+	if err := json.Unmarshal(s.Data, v); err != nil {
+		return fmt.Errorf("failed to decode state: %v", err)
+	}
+	return nil
+}
+
+// checkpointFile is the content of the checkpoint file
+type checkpointFile struct {
+	Services map[string]*State `json:"services"`
+}
+
+// Store keeps the states of the services and saves them in the checkpoint file
+type Store struct {
+	sync.Mutex
+	path   string
+	states map[string]*State
+	// disabled is set once the checkpoint fails to be saved, such as the directory is read-only,
+	// after which the states are no longer saved
+	disabled bool
+}
+
+// CheckConfig checks the directory of the checkpoint file
+func CheckConfig(dir string) error {
+	if !filepath.IsAbs(dir) {
+		return fmt.Errorf("invalid path, checkpoint directory must be an absolute path: %v", dir)
+	}
+	return nil
+}
+
+// Open loads the states from the checkpoint file in the directory.
+// The store is returned with no states and the error if the file is corrupted.
+func Open(dir string) (*Store, error) {
+	if err := CheckConfig(dir); err != nil {
+		return nil, err
+	}
+	s := &Store{
+		path:   filepath.Join(dir, fileName),
+		states: make(map[string]*State),
+	}
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, fmt.Errorf("failed to read checkpoint: %v", err)
+	}
+	var f checkpointFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return s, fmt.Errorf("failed to decode checkpoint %v: %v", s.path, err)
+	}
+	for name, state := range f.Services {
+		if state != nil {
+			s.states[name] = state
+		}
+	}
+	return s, nil
+}
+
+// Get returns the state of the service, nil is returned if the state is not saved
+func (s *Store) Get(service string) *State {
+	s.Lock()
+	defer s.Unlock()
+	return s.states[service]
+}
+
+// Set replaces the state of the service, the state is saved to the file by Save
+func (s *Store) Set(service string, state *State) {
+	s.Lock()
+	defer s.Unlock()
+	s.states[service] = state
+}
+
+// Delete drops the state of the service, the state is removed from the file by Save
+func (s *Store) Delete(service string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.states, service)
+}
+
+// Disabled returns true if the checkpoint failed to be saved and is no longer saved
+func (s *Store) Disabled() bool {
+	s.Lock()
+	defer s.Unlock()
+	return s.disabled
+}
+
+// Save writes the states to the checkpoint file, the file is replaced atomically
+// so that the previous checkpoint is kept if rubik exits while saving.
+// The store is disabled if the checkpoint fails to be saved, so that the error is returned only once.
+func (s *Store) Save() error {
+	s.Lock()
+	defer s.Unlock()
+	if s.disabled {
+		return nil
+	}
+	if err := s.save(); err != nil {
+		s.disabled = true
+		return err
+	}
+	return nil
+}
+
+func (s *Store) save() error {
+	data, err := json.Marshal(&checkpointFile{Services: s.states})
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), constant.DefaultDirMode); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %v", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, constant.DefaultFileMode); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace checkpoint: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file tests the checkpoint file
+
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/common/util"
+)
+
+type fooState struct {
+	Percent int `json:"percent"`
+}
+
+// TestStore tests saving and loading the states of the services
+func TestStore(t *testing.T) {
+	dir := filepath.Join(constant.TmpTestDir, "checkpoint")
+	defer os.RemoveAll(constant.TmpTestDir)
+	_, err := Open("checkpoint")
+	assert.Error(t, err)
+
+	// there is no state before the checkpoint is saved
+	s, err := Open(dir)
+	assert.NoError(t, err)
+	assert.Nil(t, s.Get("dynCache"))
+	state, err := NewState(1, &fooState{Percent: 30})
+	assert.NoError(t, err)
+	s.Set("dynCache", state)
+	s.Set("cpi", state)
+	s.Delete("cpi")
+	assert.NoError(t, s.Save())
+
+	s, err = Open(dir)
+	assert.NoError(t, err)
+	assert.Nil(t, s.Get("cpi"))
+	restored := s.Get("dynCache")
+	assert.NotNil(t, restored)
+	var foo fooState
+	assert.NoError(t, restored.Decode(1, &foo))
+	assert.Equal(t, 30, foo.Percent)
+	// the state of another version is not decoded
+	assert.Error(t, restored.Decode(2, &foo))
+
+	// the corrupted checkpoint is dropped
+	assert.NoError(t, util.WriteFile(filepath.Join(dir, fileName), "{"))
+	s, err = Open(dir)
+	assert.Error(t, err)
+	assert.NotNil(t, s)
+	assert.Nil(t, s.Get("dynCache"))
+}
+
+// TestStoreSaveFailed tests the store is disabled once the checkpoint fails to be saved
+func TestStoreSaveFailed(t *testing.T) {
+	defer os.RemoveAll(constant.TmpTestDir)
+	// the directory cannot be created under a regular file
+	parent := filepath.Join(constant.TmpTestDir, "file")
+	assert.NoError(t, util.WriteFile(parent, ""))
+	s, err := Open(filepath.Join(parent, "checkpoint"))
+	assert.Error(t, err)
+	assert.NotNil(t, s)
+	assert.False(t, s.Disabled())
+	assert.Error(t, s.Save())
+	assert.True(t, s.Disabled())
+	// the failure is only reported once
+	assert.NoError(t, s.Save())
+}
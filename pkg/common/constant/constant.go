@@ -24,8 +24,19 @@ const (
 	ConfigFile = "/var/lib/rubik/config.json"
 	// LockFile is rubik lock file
 	LockFile = "/run/rubik/rubik.lock"
+	// AdminSocket is the unix socket of rubik admin API
+	AdminSocket = "/run/rubik/rubik.sock"
 	// DefaultCgroupRoot is mount point
 	DefaultCgroupRoot = "/sys/fs/cgroup"
+	// DefaultCheckpointDir is the directory saving the states of the services across restarts
+	DefaultCheckpointDir = "/run/rubik"
+	// DefaultEventQueueSize is the default maximum number of the pending events of each subscriber and service
+	DefaultEventQueueSize = 1024
+	// DefaultEventOverflowPolicy is the default policy of discarding the events when the queue is full
+	DefaultEventOverflowPolicy = "coalesce"
+	// DefaultReconcileInterval is the default interval in seconds of restoring the settings of the pods,
+	// the settings are not restored by default
+	DefaultReconcileInterval = 0
 	// TmpTestDir is tmp directory for test
 	TmpTestDir = "/tmp/rubik-test"
 )
@@ -56,6 +67,9 @@ const (
 const (
 	// PriorityAnnotationKey is annotation key to mark offline pod
 	PriorityAnnotationKey = "volcano.sh/preemptable"
+	// PriorityTierAnnotationKey is annotation key to set the priority tier of the pod,
+	// which takes precedence over PriorityAnnotationKey
+	PriorityTierAnnotationKey = "rubik.isula.org/priority"
 	// CacheLimitAnnotationKey is annotation key to set L3/Mb resctrl group
 	CacheLimitAnnotationKey = "volcano.sh/cache-limit"
 	// QuotaBurstAnnotationKey is annotation key to set cpu.cfs_burst_ns
@@ -70,8 +84,12 @@ const (
 
 // log config
 const (
-	LogDriverStdio  = "stdio"
-	LogDriverFile   = "file"
+	LogDriverStdio = "stdio"
+	LogDriverFile  = "file"
+	// LogDriverJournald sends the logs to journald by its native protocol
+	LogDriverJournald = "journald"
+	// LogDriverSyslog sends the logs to the local syslog socket
+	LogDriverSyslog = "syslog"
 	LogLevelDebug   = "debug"
 	LogLevelInfo    = "info"
 	LogLevelWarn    = "warn"
@@ -80,8 +98,20 @@ const (
 	DefaultLogDir   = "/var/log/rubik"
 	DefaultLogLevel = LogLevelInfo
 	DefaultLogSize  = 1024
+	// DefaultAuditSize is the default size in MB of the audit journal in the log directory
+	DefaultAuditSize = 16
 	// LogEntryKey is the key representing EntryName in the context
 	LogEntryKey = "module"
+	// LogPodKey is the key of the UID of the pod in the structured logs
+	LogPodKey = "podUID"
+	// LogServiceKey is the key of the service name in the structured logs
+	LogServiceKey = "service"
+	// LogEventKey is the key of the event type in the structured logs
+	LogEventKey = "eventType"
+	// LogFormatText outputs the logs as plain text lines
+	LogFormatText = "text"
+	// LogFormatJSON outputs the logs as JSON lines
+	LogFormatJSON = "json"
 )
 
 // exit code
@@ -126,6 +156,16 @@ const (
 	CgroupDriverCgroupfs = "cgroupfs"
 )
 
+// cgroup version
+const (
+	// CgroupVersionAuto detects the cgroup version from the file system type of the mount point
+	CgroupVersionAuto = ""
+	// CgroupVersionV1 is the legacy hierarchy where each subsystem is mounted separately
+	CgroupVersionV1 = "v1"
+	// CgroupVersionV2 is the unified hierarchy where all controllers share one mount point
+	CgroupVersionV2 = "v2"
+)
+
 // container engine
 const (
 	// ContainerEngineCrio is name of crio container engine
@@ -0,0 +1,83 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file defines the logger attaching the structured fields to the logs
+
+package log
+
+import "isula.org/rubik/pkg/common/constant"
+
+// Entry is the logger attaching the fields to each log.
+// It is never modified after creation, so it is safe to share among goroutines.
+type Entry struct {
+	fields map[string]string
+}
+
+// WithModule returns the logger of the module, the log level of the module can be overridden
+func WithModule(module string) *Entry {
+	return WithField(constant.LogEntryKey, module)
+}
+
+// WithField returns the logger with the field
+func WithField(key, value string) *Entry {
+	var e *Entry
+	return e.WithField(key, value)
+}
+
+// WithField returns a copy of the logger with the field added
+func (e *Entry) WithField(key, value string) *Entry {
+	var fields map[string]string
+	if e == nil {
+		fields = make(map[string]string, 1)
+	} else {
+		fields = make(map[string]string, len(e.fields)+1)
+		for k, v := range e.fields {
+			fields[k] = v
+		}
+	}
+	fields[key] = value
+	return &Entry{fields: fields}
+}
+
+// WithPod returns a copy of the logger with the UID of the pod
+func (e *Entry) WithPod(uid string) *Entry {
+	return e.WithField(constant.LogPodKey, uid)
+}
+
+// WithService returns a copy of the logger with the service name
+func (e *Entry) WithService(name string) *Entry {
+	return e.WithField(constant.LogServiceKey, name)
+}
+
+// WithEvent returns a copy of the logger with the event type
+func (e *Entry) WithEvent(eventType string) *Entry {
+	return e.WithField(constant.LogEventKey, eventType)
+}
+
+// Debugf outputs debug level logs with the fields
+func (e *Entry) Debugf(format string, args ...interface{}) {
+	logln(logDebug, e, format, args...)
+}
+
+// Infof outputs info level logs with the fields
+func (e *Entry) Infof(format string, args ...interface{}) {
+	logln(logInfo, e, format, args...)
+}
+
+// Warnf outputs warn level logs with the fields
+func (e *Entry) Warnf(format string, args ...interface{}) {
+	logln(logWarn, e, format, args...)
+}
+
+// Errorf outputs error level logs with the fields
+func (e *Entry) Errorf(format string, args ...interface{}) {
+	logln(logError, e, format, args...)
+}
@@ -15,10 +15,12 @@
 package log
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -31,7 +33,7 @@ type level uint32
 
 const (
 	logStack           = 20
-	logStackFrom       = 2
+	logStackFrom       = 3
 	logFileNum         = 10
 	logSizeMin   int64 = 10          // 10MB
 	logSizeMax   int64 = 1024 * 1024 // 1TB
@@ -41,8 +43,19 @@ const (
 const (
 	stdio int = iota
 	file
+	journald
+	syslog
 )
 
+// drivers maps the names of the log drivers to the drivers
+var drivers = map[string]int{
+	"":                         stdio,
+	constant.LogDriverStdio:    stdio,
+	constant.LogDriverFile:     file,
+	constant.LogDriverJournald: journald,
+	constant.LogDriverSyslog:   syslog,
+}
+
 const (
 	logDebug level = iota
 	logInfo
@@ -57,9 +70,66 @@ var (
 	logSize        int64 = 1024
 	logFileMaxSize int64
 	logFileSize    int64
-	lock           = sync.Mutex{}
+	logFile        *os.File
+	logSocket      *socketWriter
+	logFormat      = constant.LogFormatText
+	// moduleLevels overrides the log level of the logs of the modules
+	moduleLevels map[string]level
+	lock         = sync.Mutex{}
 )
 
+// settings is the optional log config
+type settings struct {
+	format       string
+	moduleLevels map[string]level
+}
+
+// Option sets the optional log config
+type Option func(s *settings) error
+
+// WithFormat sets the format of the logs, one of text and json
+func WithFormat(format string) Option {
+	return func(s *settings) error {
+		switch format {
+		case constant.LogFormatText, "":
+			s.format = constant.LogFormatText
+		case constant.LogFormatJSON:
+			s.format = format
+		default:
+			return fmt.Errorf("invalid log format: %s", format)
+		}
+		return nil
+	}
+}
+
+// WithModuleLevels sets the log levels of the modules, which override the log level of the logs with the module
+func WithModuleLevels(levels map[string]string) Option {
+	return func(s *settings) error {
+		s.moduleLevels = make(map[string]level, len(levels))
+		for module, lvl := range levels {
+			if module == "" {
+				return fmt.Errorf("module name of log level cannot be empty")
+			}
+			l, err := levelFromString(lvl)
+			if err != nil {
+				return fmt.Errorf("invalid log level of module %s: %v", module, err)
+			}
+			s.moduleLevels[module] = l
+		}
+		return nil
+	}
+}
+
+func newSettings(opts []Option) (*settings, error) {
+	s := &settings{format: constant.LogFormatText}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
 func makeLogDir(logDir string) error {
 	if !filepath.IsAbs(logDir) {
 		return fmt.Errorf("invalid path, log directory must be an absolute path: %v", logDir)
@@ -72,47 +142,85 @@ func makeLogDir(logDir string) error {
 	return nil
 }
 
-// InitConfig initializes log config
-func InitConfig(driver, logdir, lvl string, size int64) error {
-	if driver == "" {
-		driver = constant.LogDriverStdio
-	}
-	if driver != constant.LogDriverStdio && driver != constant.LogDriverFile {
+// CheckConfig checks the log config without applying it
+func CheckConfig(driver, logdir, lvl string, size int64, opts ...Option) error {
+	d, ok := drivers[driver]
+	if !ok {
 		return fmt.Errorf("invalid log driver: %s", driver)
 	}
-	logDriver = stdio
-	if driver == constant.LogDriverFile {
-		logDriver = file
-	}
-
-	if lvl == "" {
-		lvl = constant.LogLevelInfo
+	if d == journald || d == syslog {
+		if _, err := os.Stat(socketPath(d)); err != nil {
+			return fmt.Errorf("log driver %s is unavailable: %v", driver, err)
+		}
 	}
-	levelstr, err := levelFromString(lvl)
-	if err != nil {
+	if _, err := levelFromString(lvl); err != nil {
 		return err
 	}
-	logLevel = levelstr
-
 	if size < logSizeMin || size > logSizeMax {
 		return fmt.Errorf("invalid log size: %d (valid range is %d-%d)", size, logSizeMin, logSizeMax)
 	}
+	if driver == constant.LogDriverFile && !filepath.IsAbs(logdir) {
+		return fmt.Errorf("invalid path, log directory must be an absolute path: %v", logdir)
+	}
+	_, err := newSettings(opts)
+	return err
+}
+
+// InitConfig initializes log config
+func InitConfig(driver, logdir, lvl string, size int64, opts ...Option) error {
+	if err := CheckConfig(driver, logdir, lvl, size, opts...); err != nil {
+		return err
+	}
+	s, err := newSettings(opts)
+	if err != nil {
+		return err
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	closeDriver()
+	logFormat, moduleLevels = s.format, s.moduleLevels
+	logDriver = drivers[driver]
+	logLevel, _ = levelFromString(lvl)
 	logSize = size
 	logFileMaxSize = logSize / logFileNum
 
-	if driver == constant.LogDriverFile {
+	switch logDriver {
+	case file:
 		if err := makeLogDir(logdir); err != nil {
+			logDriver = stdio
 			return err
 		}
 		logFname = filepath.Join(logdir, "rubik.log")
 		if f, err := os.Stat(logFname); err == nil {
 			atomic.StoreInt64(&logFileSize, f.Size())
 		}
+	case journald, syslog:
+		logSocket = newSocketWriter(logDriver)
+		if err := logSocket.connect(); err != nil {
+			logDriver, logSocket = stdio, nil
+			return fmt.Errorf("failed to connect to log driver %s: %v", driver, err)
+		}
 	}
 
 	return nil
 }
 
+// closeDriver releases the file or the socket opened by the current log driver
+func closeDriver() {
+	closeLogFile()
+	if logSocket != nil {
+		logSocket.close()
+		logSocket = nil
+	}
+}
+
+func closeLogFile() {
+	if logFile != nil {
+		DropError(logFile.Close())
+		logFile = nil
+	}
+}
+
 // DropError drop unused error
 func DropError(args ...interface{}) {
 	argn := len(args)
@@ -174,36 +282,66 @@ func renameLogFile() {
 	DropError(os.Chmod(firstDumpLogName, constant.DefaultDumpLogFileMode))
 }
 
-func rotateLog(line int64) string {
+func rotateLog(line int64) {
 	if atomic.AddInt64(&logFileSize, line) > logFileMaxSize*unitMB {
+		closeLogFile()
 		renameLogFile()
 		atomic.StoreInt64(&logFileSize, line)
 	}
+}
 
-	return logFname
+// writeFile appends the line to the log file, which is kept open until it is rotated
+func writeFile(line string) {
+	rotateLog(int64(len(line)))
+	if logFile == nil {
+		f, err := os.OpenFile(logFname, os.O_CREATE|os.O_APPEND|os.O_WRONLY, constant.DefaultFileMode)
+		if err != nil {
+			return
+		}
+		logFile = f
+	}
+	DropError(logFile.WriteString(line))
 }
 
-func writeLine(line string) {
+func writeRecord(r *record) {
 	lock.Lock()
 	defer lock.Unlock()
 
-	if logDriver == stdio {
-		fmt.Printf("%s", line)
-		return
+	switch logDriver {
+	case file:
+		writeFile(formatLine(r))
+	case journald, syslog:
+		if err := logSocket.send(r); err != nil {
+			// the log is not lost when journald or syslog is restarting
+			fmt.Printf("%s", formatLine(r))
+		}
+	default:
+		fmt.Printf("%s", formatLine(r))
 	}
+}
 
-	f, err := os.OpenFile(rotateLog(int64(len(line))), os.O_CREATE|os.O_APPEND|os.O_WRONLY, constant.DefaultFileMode)
-	if err != nil {
-		return
-	}
+// record is a log to be written by the log driver
+type record struct {
+	time   time.Time
+	level  string
+	file   string
+	line   int
+	fn     string
+	fields map[string]string
+	msg    string
+}
 
-	DropError(f.WriteString(line))
-	DropError(f.Close())
+// caller returns the location where the log is output
+func (r *record) caller() string {
+	if r.file == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d:%s()", r.file, r.line, r.fn)
 }
 
-func output(lvl string, format string, args ...interface{}) {
-	tag := fmt.Sprintf("%s [rubik] level=%s ", time.Now().Format("2006-01-02 15:04:05.000"), lvl)
-	raw := fmt.Sprintf(format, args...) + "\n"
+func output(lvl string, fields map[string]string, format string, args ...interface{}) {
+	now := time.Now()
+	msg := fmt.Sprintf(format, args...)
 
 	depth := 1
 	if lvl == constant.LogLevelStack {
@@ -211,42 +349,95 @@ func output(lvl string, format string, args ...interface{}) {
 	}
 
 	for i := logStackFrom; i < logStackFrom+depth; i++ {
-		line := tag + raw
+		r := &record{time: now, level: lvl, fields: fields, msg: msg}
 		pc, file, linum, ok := runtime.Caller(i)
 		if ok {
 			fs := strings.Split(runtime.FuncForPC(pc).Name(), "/")
 			fs = strings.Split("."+fs[len(fs)-1], ".")
-			fn := fs[len(fs)-1]
-			line = tag + fmt.Sprintf("%s:%d:%s() ", file, linum, fn) + raw
+			r.file, r.line, r.fn = file, linum, fs[len(fs)-1]
 		} else if lvl == constant.LogLevelStack {
 			break
 		}
-		writeLine(line)
+		writeRecord(r)
+	}
+}
+
+// formatLine formats the log as a line in the configured format
+func formatLine(r *record) string {
+	if logFormat == constant.LogFormatJSON {
+		return formatJSON(r) + "\n"
+	}
+	return fmt.Sprintf("%s [rubik] level=%s %s\n", r.time.Format("2006-01-02 15:04:05.000"), r.level, formatText(r))
+}
+
+// formatText formats the caller, the fields and the message of the log
+func formatText(r *record) string {
+	var b strings.Builder
+	if caller := r.caller(); caller != "" {
+		b.WriteString(caller + " ")
+	}
+	keys := make([]string, 0, len(r.fields))
+	for k := range r.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s=%s ", k, r.fields[k])
 	}
+	b.WriteString(r.msg)
+	return b.String()
 }
 
-func logln(lvl level, format string, args ...interface{}) {
-	if lvl >= logLevel {
-		output(levelToString(lvl), format, args...)
+func formatJSON(r *record) string {
+	// time, level, caller and msg are always recorded
+	const reservedKeys = 4
+	m := make(map[string]string, len(r.fields)+reservedKeys)
+	for k, v := range r.fields {
+		m[k] = v
+	}
+	m["time"] = r.time.Format(time.RFC3339Nano)
+	m["level"] = r.level
+	m["msg"] = r.msg
+	if caller := r.caller(); caller != "" {
+		m["caller"] = caller
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return formatText(r)
+	}
+	return string(data)
+}
+
+func logln(lvl level, e *Entry, format string, args ...interface{}) {
+	var fields map[string]string
+	if e != nil {
+		fields = e.fields
+	}
+	threshold := logLevel
+	if l, ok := moduleLevels[fields[constant.LogEntryKey]]; ok {
+		threshold = l
+	}
+	if lvl >= threshold {
+		output(levelToString(lvl), fields, format, args...)
 	}
 }
 
 // Debugf output debug level logs when then log level of the logger is less than or equal to debug level
 func Debugf(format string, args ...interface{}) {
-	logln(logDebug, format, args...)
+	logln(logDebug, nil, format, args...)
 }
 
 // Infof output info level logs when then log level of the logger is less than or equal to info level
 func Infof(format string, args ...interface{}) {
-	logln(logInfo, format, args...)
+	logln(logInfo, nil, format, args...)
 }
 
 // Warnf output warn level logs when then log level of the logger is less than or equal to warn level
 func Warnf(format string, args ...interface{}) {
-	logln(logWarn, format, args...)
+	logln(logWarn, nil, format, args...)
 }
 
 // Errorf output error level logs when the log level of the logger is less than or equal to error level
 func Errorf(format string, args ...interface{}) {
-	logln(logError, format, args...)
+	logln(logError, nil, format, args...)
 }
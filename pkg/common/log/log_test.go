@@ -14,9 +14,13 @@
 package log
 
 import (
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -167,6 +171,136 @@ func TestInitConfigLogLevel(t *testing.T) {
 	}
 }
 
+// TestStructuredLog tests the JSON format and the log levels of the modules
+func TestStructuredLog(t *testing.T) {
+	logDir := try.GenTestDir().String()
+	defer try.DelTestDir()
+	logFilePath := filepath.Join(logDir, "rubik.log")
+	defer func() {
+		assert.NoError(t, InitConfig("stdio", "", "", logSize))
+	}()
+
+	// invalid options
+	assert.Error(t, InitConfig("file", logDir, "", logSize, WithFormat("xml")))
+	assert.Error(t, CheckConfig("file", logDir, "", logSize, WithModuleLevels(map[string]string{"dynCache": "verbose"})))
+	assert.Error(t, CheckConfig("file", logDir, "", logSize, WithModuleLevels(map[string]string{"": "debug"})))
+
+	// the debug logs are only output for dynCache
+	assert.NoError(t, InitConfig("file", logDir, "info", logSize, WithFormat("json"),
+		WithModuleLevels(map[string]string{"dynCache": "debug", "psi": "error"})))
+	WithModule("dynCache").WithPod("pod1").WithService("dynCache").WithEvent("addinfo").Debugf("debug of %s", "dynCache")
+	WithModule("psi").Infof("info of psi")
+	WithModule("quotaBurst").Debugf("debug of quotaBurst")
+	Infof("info without module")
+	b, err := ioutil.ReadFile(logFilePath)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	assert.Equal(t, 2, len(lines))
+
+	var record map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "debug of dynCache", record["msg"])
+	assert.Equal(t, "debug", record["level"])
+	assert.Equal(t, "dynCache", record[constant.LogEntryKey])
+	assert.Equal(t, "pod1", record[constant.LogPodKey])
+	assert.Equal(t, "dynCache", record[constant.LogServiceKey])
+	assert.Equal(t, "addinfo", record[constant.LogEventKey])
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, "info without module", record["msg"])
+
+	// the fields are appended before the message in the text format
+	assert.NoError(t, os.Remove(logFilePath))
+	assert.NoError(t, InitConfig("file", logDir, "info", logSize, WithFormat("text")))
+	WithModule("psi").WithPod("pod2").Infof("info of psi")
+	b, err = ioutil.ReadFile(logFilePath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "module=psi podUID=pod2 info of psi")
+}
+
+// TestLogCaller tests the caller reported is the function calling the logger
+func TestLogCaller(t *testing.T) {
+	logDir := try.GenTestDir().String()
+	defer try.DelTestDir()
+	logFilePath := filepath.Join(logDir, "rubik.log")
+	defer func() {
+		assert.NoError(t, InitConfig("stdio", "", "", logSize))
+	}()
+
+	assert.NoError(t, InitConfig("file", logDir, "info", logSize, WithFormat("json")))
+	_, file, line, _ := runtime.Caller(0)
+	Infof("info without module")
+	WithModule("psi").Infof("info of psi")
+	b, err := ioutil.ReadFile(logFilePath)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	assert.Equal(t, 2, len(lines))
+	for i, l := range lines {
+		var record map[string]string
+		assert.NoError(t, json.Unmarshal([]byte(l), &record))
+		assert.Equal(t, fmt.Sprintf("%s:%d:TestLogCaller()", file, line+i+1), record["caller"])
+	}
+
+	assert.NoError(t, os.Remove(logFilePath))
+	assert.NoError(t, InitConfig("file", logDir, "info", logSize, WithFormat("text")))
+	_, file, line, _ = runtime.Caller(0)
+	Warnf("warn in text")
+	b, err = ioutil.ReadFile(logFilePath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), fmt.Sprintf("%s:%d:TestLogCaller() warn in text", file, line+1))
+}
+
+// TestSocketDrivers tests the journald and syslog drivers with the local sockets
+func TestSocketDrivers(t *testing.T) {
+	logDir := try.GenTestDir().String()
+	defer try.DelTestDir()
+	oldJournal, oldSyslog := journalSocket, syslogSocket
+	journalSocket, syslogSocket = filepath.Join(logDir, "journal.sock"), filepath.Join(logDir, "syslog.sock")
+	defer func() {
+		journalSocket, syslogSocket = oldJournal, oldSyslog
+		assert.NoError(t, InitConfig("stdio", "", "", logSize))
+	}()
+
+	// the socket of the driver does not exist
+	assert.Error(t, CheckConfig("journald", "", "", logSize))
+	assert.Error(t, InitConfig("syslog", "", "", logSize))
+
+	listen := func(path string) *net.UnixConn {
+		conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+		assert.NoError(t, err)
+		return conn
+	}
+	receive := func(conn *net.UnixConn) string {
+		buf := make([]byte, unitMB)
+		n, err := conn.Read(buf)
+		assert.NoError(t, err)
+		return string(buf[:n])
+	}
+
+	journal := listen(journalSocket)
+	defer journal.Close()
+	assert.NoError(t, InitConfig("journald", "", "debug", logSize))
+	WithModule("psi").WithPod("pod1").Errorf("first line\nsecond line")
+	data := receive(journal)
+	assert.Contains(t, data, "PRIORITY=3\n")
+	assert.Contains(t, data, "SYSLOG_IDENTIFIER=rubik\n")
+	assert.Contains(t, data, "RUBIK_MODULE=psi\n")
+	assert.Contains(t, data, "RUBIK_POD_UID=pod1\n")
+	assert.Contains(t, data, "CODE_FILE=")
+	// the multi-line message is prefixed by its length
+	assert.Contains(t, data, "MESSAGE\n\x16\x00\x00\x00\x00\x00\x00\x00first line\nsecond line\n")
+
+	sys := listen(syslogSocket)
+	defer sys.Close()
+	assert.NoError(t, InitConfig("syslog", "", "debug", logSize))
+	Warnf("syslog warning")
+	data = receive(sys)
+	assert.True(t, strings.HasPrefix(data, "<28>"), data)
+	assert.Contains(t, data, fmt.Sprintf("rubik[%d]: ", os.Getpid()))
+	assert.Contains(t, data, "syslog warning")
+	Debugf("syslog debug")
+	assert.True(t, strings.HasPrefix(receive(sys), "<31>"))
+}
+
 // test_rubik_set_logsize_0001
 func TestInitConfigLogSize(t *testing.T) {
 	logDir := try.GenTestDir().String()
@@ -0,0 +1,167 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements the journald and syslog log drivers
+
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"isula.org/rubik/pkg/common/constant"
+)
+
+const (
+	// syslogIdentifier is the identifier of rubik in journald and syslog
+	syslogIdentifier = "rubik"
+	// syslogFacility is the daemon facility of syslog
+	syslogFacility = 3 << 3
+	// journalFieldPrefix is the prefix of the names of the structured fields in journald
+	journalFieldPrefix = "RUBIK_"
+)
+
+var (
+	// journalSocket is the socket receiving the native protocol of journald
+	journalSocket = "/run/systemd/journal/socket"
+	// syslogSocket is the socket of the local syslog daemon
+	syslogSocket = "/dev/log"
+)
+
+// priorities maps the log levels of rubik to the syslog severities
+var priorities = map[string]int{
+	constant.LogLevelDebug: 7,
+	constant.LogLevelInfo:  6,
+	constant.LogLevelWarn:  4,
+	constant.LogLevelError: 3,
+	constant.LogLevelStack: 7,
+}
+
+func socketPath(driver int) string {
+	if driver == journald {
+		return journalSocket
+	}
+	return syslogSocket
+}
+
+// socketWriter sends each log as a datagram to the local socket of journald or syslog
+type socketWriter struct {
+	path   string
+	encode func(r *record) []byte
+	conn   net.Conn
+}
+
+func newSocketWriter(driver int) *socketWriter {
+	w := &socketWriter{path: socketPath(driver), encode: encodeSyslog}
+	if driver == journald {
+		w.encode = encodeJournal
+	}
+	return w
+}
+
+func (w *socketWriter) connect() error {
+	conn, err := net.Dial("unixgram", w.path)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+// send sends the log, and reconnects once if the daemon has been restarted
+func (w *socketWriter) send(r *record) error {
+	data := w.encode(r)
+	if w.conn != nil {
+		if _, err := w.conn.Write(data); err == nil {
+			return nil
+		}
+		w.close()
+	}
+	if err := w.connect(); err != nil {
+		return err
+	}
+	_, err := w.conn.Write(data)
+	return err
+}
+
+func (w *socketWriter) close() {
+	if w.conn != nil {
+		DropError(w.conn.Close())
+		w.conn = nil
+	}
+}
+
+// encodeSyslog encodes the log in the format of the local syslog socket: <PRI>TIMESTAMP TAG[PID]: MSG
+func encodeSyslog(r *record) []byte {
+	msg := formatText(r)
+	if logFormat == constant.LogFormatJSON {
+		msg = formatJSON(r)
+	}
+	return []byte(fmt.Sprintf("<%d>%s %s[%d]: %s\n", syslogFacility|priorities[r.level],
+		r.time.Format("Jan _2 15:04:05"), syslogIdentifier, os.Getpid(), msg))
+}
+
+// encodeJournal encodes the log in the native protocol of journald, the fields are kept as journal fields
+func encodeJournal(r *record) []byte {
+	var b bytes.Buffer
+	appendJournalField(&b, "MESSAGE", r.msg)
+	appendJournalField(&b, "PRIORITY", strconv.Itoa(priorities[r.level]))
+	appendJournalField(&b, "SYSLOG_IDENTIFIER", syslogIdentifier)
+	if r.file != "" {
+		appendJournalField(&b, "CODE_FILE", r.file)
+		appendJournalField(&b, "CODE_LINE", strconv.Itoa(r.line))
+		appendJournalField(&b, "CODE_FUNC", r.fn)
+	}
+	for k, v := range r.fields {
+		appendJournalField(&b, journalFieldName(k), v)
+	}
+	return b.Bytes()
+}
+
+// appendJournalField appends KEY=value, or the key followed by the little-endian length
+// and the value if the value has multiple lines
+func appendJournalField(b *bytes.Buffer, key, value string) {
+	if !strings.Contains(value, "\n") {
+		b.WriteString(key + "=" + value + "\n")
+		return
+	}
+	b.WriteString(key + "\n")
+	DropError(binary.Write(b, binary.LittleEndian, uint64(len(value))))
+	b.WriteString(value + "\n")
+}
+
+// journalFieldName converts the key of the field to the journal field name, for example podUID to RUBIK_POD_UID
+func journalFieldName(key string) string {
+	var (
+		b    strings.Builder
+		prev rune
+	)
+	b.WriteString(journalFieldPrefix)
+	for _, c := range key {
+		switch {
+		case c < unicode.MaxASCII && unicode.IsUpper(c) && unicode.IsLower(prev):
+			b.WriteByte('_')
+			b.WriteRune(c)
+		case c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c)):
+			b.WriteRune(unicode.ToUpper(c))
+		default:
+			b.WriteByte('_')
+		}
+		prev = c
+	}
+	return b.String()
+}
@@ -0,0 +1,261 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements counters and gauges in the Prometheus text format
+
+// Package metrics collects the metrics of rubik and exposes them in the Prometheus text format
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"isula.org/rubik/pkg/common/log"
+)
+
+const (
+	counterType = "counter"
+	gaugeType   = "gauge"
+	// labelSeparator separates the label values in the key of series, which is not allowed in label values
+	labelSeparator = "\xff"
+	// contentType is the content type of the Prometheus text format
+	contentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// Registry holds the metric families to be exposed
+type Registry struct {
+	sync.RWMutex
+	vecs map[string]*vec
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{vecs: make(map[string]*vec)}
+}
+
+// defaultRegistry holds the metrics of rubik
+var defaultRegistry = NewRegistry()
+
+type series struct {
+	labelValues []string
+	value       float64
+}
+
+// vec is a metric family partitioned by the label values
+type vec struct {
+	sync.RWMutex
+	name   string
+	help   string
+	typ    string
+	labels []string
+	series map[string]*series
+}
+
+func (r *Registry) newVec(name, help, typ string, labels []string) *vec {
+	v := &vec{
+		name:   name,
+		help:   help,
+		typ:    typ,
+		labels: labels,
+		series: make(map[string]*series),
+	}
+	r.Lock()
+	defer r.Unlock()
+	if _, existed := r.vecs[name]; existed {
+		panic(fmt.Sprintf("metric %v is registered repeatedly", name))
+	}
+	r.vecs[name] = v
+	return v
+}
+
+// update applies the function to the value of series with the label values
+func (v *vec) update(labelValues []string, f func(float64) float64) {
+	if len(labelValues) != len(v.labels) {
+		// metrics are only updated by rubik itself, so the mismatch is a programming error
+		panic(fmt.Sprintf("metric %v expects %d label values, got %d", v.name, len(v.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, labelSeparator)
+	v.Lock()
+	defer v.Unlock()
+	s, existed := v.series[key]
+	if !existed {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		v.series[key] = s
+	}
+	s.value = f(s.value)
+}
+
+func (v *vec) get(labelValues []string) (float64, bool) {
+	v.RLock()
+	defer v.RUnlock()
+	s, existed := v.series[strings.Join(labelValues, labelSeparator)]
+	if !existed {
+		return 0, false
+	}
+	return s.value, true
+}
+
+// Delete deletes the series with the label values
+func (v *vec) Delete(labelValues ...string) {
+	v.Lock()
+	delete(v.series, strings.Join(labelValues, labelSeparator))
+	v.Unlock()
+}
+
+// Reset deletes all the series
+func (v *vec) Reset() {
+	v.Lock()
+	v.series = make(map[string]*series)
+	v.Unlock()
+}
+
+// write writes the metric family in the Prometheus text format
+func (v *vec) write(w io.Writer) error {
+	v.RLock()
+	var all = make([]*series, 0, len(v.series))
+	for _, s := range v.series {
+		all = append(all, &series{labelValues: s.labelValues, value: s.value})
+	}
+	v.RUnlock()
+	if len(all) == 0 {
+		return nil
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return strings.Join(all[i].labelValues, labelSeparator) < strings.Join(all[j].labelValues, labelSeparator)
+	})
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, escapeHelp(v.help), v.name, v.typ); err != nil {
+		return err
+	}
+	for _, s := range all {
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", v.name, formatLabels(v.labels, s.labelValues),
+			strconv.FormatFloat(s.value, 'g', -1, 64)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var pairs = make([]string, 0, len(names))
+	for i, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", name, escapeLabelValue(values[i])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
+
+// CounterVec is a counter partitioned by the label values
+type CounterVec struct {
+	*vec
+}
+
+// NewCounterVec creates a counter in the registry
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{vec: r.newVec(name, help, counterType, labels)}
+}
+
+// Inc increases the counter with the label values by 1
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increases the counter with the label values by delta, negative delta is ignored
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	c.update(labelValues, func(old float64) float64 { return old + delta })
+}
+
+// Value returns the value of the counter with the label values
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	value, _ := c.get(labelValues)
+	return value
+}
+
+// GaugeVec is a gauge partitioned by the label values
+type GaugeVec struct {
+	*vec
+}
+
+// NewGaugeVec creates a gauge in the registry
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{vec: r.newVec(name, help, gaugeType, labels)}
+}
+
+// Set sets the gauge with the label values
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.update(labelValues, func(float64) float64 { return value })
+}
+
+// Value returns the value of the gauge with the label values and whether the gauge is set
+func (g *GaugeVec) Value(labelValues ...string) (float64, bool) {
+	return g.get(labelValues)
+}
+
+// Expose writes all the metric families sorted by name in the Prometheus text format
+func (r *Registry) Expose(w io.Writer) error {
+	r.RLock()
+	var vecs = make([]*vec, 0, len(r.vecs))
+	for _, v := range r.vecs {
+		vecs = append(vecs, v)
+	}
+	r.RUnlock()
+	sort.Slice(vecs, func(i, j int) bool {
+		return vecs[i].name < vecs[j].name
+	})
+	bw := bufio.NewWriter(w)
+	for _, v := range vecs {
+		if err := v.write(bw); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP exposes the metrics of the registry
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, fmt.Sprintf("method %v is not allowed", req.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	// the response has been partially written, so the error can only be logged
+	if err := r.Expose(w); err != nil {
+		log.Warnf("failed to write metrics: %v", err)
+	}
+}
+
+// Handler returns the handler exposing the metrics of rubik
+func Handler() http.Handler {
+	return defaultRegistry
+}
@@ -0,0 +1,106 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file tests the metrics
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRegistry_Expose tests updating metrics and the Prometheus text format
+func TestRegistry_Expose(t *testing.T) {
+	r := NewRegistry()
+	events := r.NewCounterVec("test_events_total", "Number of events.", "type")
+	quota := r.NewGaugeVec("test_quota", "Quota with \\ and\nnewline.", "cgroup")
+	r.NewGaugeVec("test_empty", "Metric without series.")
+
+	events.Inc("add")
+	events.Add(2, "add")
+	events.Add(-1, "add")
+	events.Inc("delete")
+	assert.Equal(t, float64(3), events.Value("add"))
+	quota.Set(100000, `kubepods/"a"`)
+	quota.Set(1.5, "kubepods/b")
+	quota.Set(2.5, "kubepods/b")
+	quota.Set(1, "kubepods/c")
+	quota.Delete("kubepods/c")
+	_, ok := quota.Value("kubepods/c")
+	assert.False(t, ok)
+	assert.Panics(t, func() { quota.Set(1) })
+	assert.Panics(t, func() { r.NewGaugeVec("test_quota", "") })
+
+	var buf bytes.Buffer
+	assert.NoError(t, r.Expose(&buf))
+	assert.Equal(t, `# HELP test_events_total Number of events.
+# TYPE test_events_total counter
+test_events_total{type="add"} 3
+test_events_total{type="delete"} 1
+# HELP test_quota Quota with \\ and\nnewline.
+# TYPE test_quota gauge
+test_quota{cgroup="kubepods/\"a\""} 100000
+test_quota{cgroup="kubepods/b"} 2.5
+`, buf.String())
+
+	quota.Reset()
+	buf.Reset()
+	assert.NoError(t, r.Expose(&buf))
+	assert.NotContains(t, buf.String(), "test_quota")
+}
+
+// TestRegistry_ServeHTTP tests the metrics handler
+func TestRegistry_ServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("test_total", "Test.").Inc()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, Path, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, contentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "test_total 1\n")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, Path, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+// TestServe tests serving the metrics over TCP
+func TestServe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.Error(t, Serve(ctx, "invalid address"))
+
+	// find a free port
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := l.Addr().String()
+	l.Close()
+	assert.NoError(t, Serve(ctx, address))
+
+	EventsHandled.Inc("test", "test")
+	resp, err := http.Get(fmt.Sprintf("http://%s%s", address, Path))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `rubik_events_handled_total{subscriber="test",type="test"} 1`)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file defines the metrics of rubik and serves them over TCP
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"isula.org/rubik/pkg/common/log"
+)
+
+const (
+	// Path is the path of the metrics endpoint
+	Path = "/metrics"
+	// shutdownTimeout is the maximum time to wait for the scrapes being processed when the server stops
+	shutdownTimeout = 3 * time.Second
+)
+
+// results of the decisions
+const (
+	ResultSucceeded = "succeeded"
+	ResultFailed    = "failed"
+)
+
+// metrics of the agent
+var (
+	// EventsHandled counts the events handled by the subscribers
+	EventsHandled = defaultRegistry.NewCounterVec("rubik_events_handled_total",
+		"Number of events handled by the subscribers.", "subscriber", "type")
+	// EventQueueDepth is the number of the events waiting in the queue
+	EventQueueDepth = defaultRegistry.NewGaugeVec("rubik_event_queue_depth",
+		"Number of the events waiting in the queue of the subscribers and the services.", "queue")
+	// EventsDiscarded counts the events discarded by the overflow policy of the queue
+	EventsDiscarded = defaultRegistry.NewCounterVec("rubik_events_discarded_total",
+		"Number of the events dropped or coalesced because the queue is full.", "queue", "reason")
+	// ServiceErrors counts the failures of services to handle the pods
+	ServiceErrors = defaultRegistry.NewCounterVec("rubik_service_errors_total",
+		"Number of failures of services to handle the pods.", "service", "operation")
+	// ServiceRestarts counts the restarts of the persistent services
+	ServiceRestarts = defaultRegistry.NewCounterVec("rubik_service_restarts_total",
+		"Number of restarts of the persistent services.", "service")
+	// ServicePanics counts the panics of the persistent services
+	ServicePanics = defaultRegistry.NewCounterVec("rubik_service_panics_total",
+		"Number of panics of the persistent services.", "service")
+	// CgroupDrifts counts the settings of the pods changed by others and restored by the services
+	CgroupDrifts = defaultRegistry.NewCounterVec("rubik_cgroup_drifts_total",
+		"Number of the cgroup files of the pods changed by others and restored by the services.", "service", "file")
+	// ServiceDegraded is 1 if the optional service failed to pre-start and is being retried
+	ServiceDegraded = defaultRegistry.NewGaugeVec("rubik_service_degraded",
+		"Whether the optional service failed to pre-start and is being retried.", "service")
+)
+
+// metrics of the decisions made by services
+var (
+	// Evictions counts the pods evicted by rubik
+	Evictions = defaultRegistry.NewCounterVec("rubik_evictions_total",
+		"Number of pods evicted, partitioned by the trigger and the result.", "trigger", "result")
+	// DynCachePercent is the current percentage of the dynamic cache limit
+	DynCachePercent = defaultRegistry.NewGaugeVec("rubik_dyncache_dynamic_percent",
+		"Current percentage of L3 cache and memory bandwidth of the dynamic level.", "resource")
+	// QuotaTurboQuota is the current cpu quota of the container adjusted by quota turbo
+	QuotaTurboQuota = defaultRegistry.NewGaugeVec("rubik_quotaturbo_cpu_quota_microseconds",
+		"Current cpu.cfs_quota_us of the containers adjusted by quota turbo.", "cgroup")
+	// PSIAvg10 is the latest some avg10 of the pods monitored by psi
+	PSIAvg10 = defaultRegistry.NewGaugeVec("rubik_psi_some_avg10",
+		"Latest PSI some avg10 of the online pods.", "pod", "resource")
+	// CPIMean is the mean of the CPI of the online pods
+	CPIMean = defaultRegistry.NewGaugeVec("rubik_cpi_mean",
+		"Mean of the cycles per instruction of the pods.", "pod")
+	// CPIStdDev is the standard deviation of the CPI of the online pods
+	CPIStdDev = defaultRegistry.NewGaugeVec("rubik_cpi_stddev",
+		"Standard deviation of the cycles per instruction of the pods.", "pod")
+)
+
+// Serve exposes the metrics on the TCP address until the context is canceled
+func Serve(ctx context.Context, address string) error {
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %v: %v", address, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(Path, Handler())
+	server := &http.Server{Handler: mux}
+	go func() {
+		if err := server.Serve(l); err != nil && err != http.ErrServerClosed {
+			log.Errorf("metrics server exits abnormally: %v", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Warnf("failed to shutdown metrics server: %v", err)
+		}
+	}()
+	log.Infof("metrics server listens on %v", l.Addr())
+	return nil
+}
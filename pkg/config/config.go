@@ -22,11 +22,15 @@ import (
 	"isula.org/rubik/pkg/common/util"
 )
 
-const agentKey = "agent"
+const (
+	agentKey    = "agent"
+	podRulesKey = "podRules"
+)
 
 // sysConfKeys saves the system configuration key, which is the service name except
 var sysConfKeys = map[string]struct{}{
-	agentKey: {},
+	agentKey:    {},
+	podRulesKey: {},
 }
 
 // Config saves all configuration information of rubik
@@ -38,14 +42,46 @@ type Config struct {
 
 // AgentConfig is the configuration of rubik, including important basic configurations such as logs
 type AgentConfig struct {
-	LogDriver       string   `json:"logDriver,omitempty"`
-	LogLevel        string   `json:"logLevel,omitempty"`
-	LogSize         int64    `json:"logSize,omitempty"`
-	LogDir          string   `json:"logDir,omitempty"`
-	CgroupRoot      string   `json:"cgroupRoot,omitempty"`
-	EnabledFeatures []string `json:"enabledFeatures,omitempty"`
-	CgroupDriver    string   `json:"cgroupDriver,omitempty"`
-	InformerType    string   `json:"informerType,omitempty"`
+	LogDriver       string            `json:"logDriver,omitempty"`
+	LogLevel        string            `json:"logLevel,omitempty"`
+	LogSize         int64             `json:"logSize,omitempty"`
+	LogDir          string            `json:"logDir,omitempty"`
+	LogFormat       string            `json:"logFormat,omitempty"`
+	LogModuleLevels map[string]string `json:"logModuleLevels,omitempty"`
+	CgroupRoot      string            `json:"cgroupRoot,omitempty"`
+	EnabledFeatures []string          `json:"enabledFeatures,omitempty"`
+	CgroupDriver    string            `json:"cgroupDriver,omitempty"`
+	InformerType    string            `json:"informerType,omitempty"`
+	CgroupVersion   string            `json:"cgroupVersion,omitempty"`
+	EnableMetrics   bool              `json:"enableMetrics,omitempty"`
+	MetricsAddress  string            `json:"metricsAddress,omitempty"`
+	EnablePolicy    bool              `json:"enablePolicy,omitempty"`
+	EnableEvents    bool              `json:"enableEvents,omitempty"`
+	EnableAudit     bool              `json:"enableAudit,omitempty"`
+	AuditSize       int64             `json:"auditSize,omitempty"`
+	DryRun          bool              `json:"dryRun,omitempty"`
+	CheckpointDir   string            `json:"checkpointDir,omitempty"`
+	HealthAddress   string            `json:"healthAddress,omitempty"`
+	// RevertOnExit restores the files changed by the services when rubik exits,
+	// the files are always restored when the services are disabled or stopped at runtime
+	RevertOnExit bool `json:"revertOnExit"`
+	// OptionalFeatures are the enabled features which are retried instead of stopping rubik if they fail to start
+	OptionalFeatures    []string `json:"optionalFeatures,omitempty"`
+	EventQueueSize      int      `json:"eventQueueSize,omitempty"`
+	EventOverflowPolicy string   `json:"eventOverflowPolicy,omitempty"`
+	ReconcileInterval   int      `json:"reconcileInterval,omitempty"`
+	// Annotations are the annotations or labels of other schedulers translated into the settings of rubik,
+	// indexed by the name of the setting
+	Annotations map[string][]AnnotationSource `json:"annotations,omitempty"`
+}
+
+// AnnotationSource is an annotation or a label of the pods whose values are translated into a setting of rubik
+type AnnotationSource struct {
+	Key   string `json:"key"`
+	Label bool   `json:"label,omitempty"`
+	// Values translate the values of the source into the values of the setting,
+	// the values not in the table are ignored. The values are used as they are if the table is empty.
+	Values map[string]string `json:"values,omitempty"`
 }
 
 // NewConfig returns an config object pointer
@@ -53,13 +89,20 @@ func NewConfig(pType parserType) *Config {
 	c := &Config{
 		ConfigParser: defaultParserFactory.getParser(pType),
 		Agent: &AgentConfig{
-			LogDriver:    constant.LogDriverStdio,
-			LogSize:      constant.DefaultLogSize,
-			LogLevel:     constant.DefaultLogLevel,
-			LogDir:       constant.DefaultLogDir,
-			CgroupRoot:   constant.DefaultCgroupRoot,
-			CgroupDriver: constant.CgroupDriverCgroupfs,
-			InformerType: constant.APIServerInformer,
+			LogDriver:           constant.LogDriverStdio,
+			LogSize:             constant.DefaultLogSize,
+			LogLevel:            constant.DefaultLogLevel,
+			LogDir:              constant.DefaultLogDir,
+			LogFormat:           constant.LogFormatText,
+			AuditSize:           constant.DefaultAuditSize,
+			CheckpointDir:       constant.DefaultCheckpointDir,
+			RevertOnExit:        true,
+			CgroupRoot:          constant.DefaultCgroupRoot,
+			CgroupDriver:        constant.CgroupDriverCgroupfs,
+			InformerType:        constant.APIServerInformer,
+			EventQueueSize:      constant.DefaultEventQueueSize,
+			EventOverflowPolicy: constant.DefaultEventOverflowPolicy,
+			ReconcileInterval:   constant.DefaultReconcileInterval,
 		},
 	}
 	return c
@@ -84,19 +127,53 @@ func (c *Config) parseAgentConfig() error {
 	return c.UnmarshalSubConfig(content, c.Agent)
 }
 
-// LoadConfig loads and parses configuration data from the file, and save it to the Config
+// UnmarshalPodRules parses the pod rules into v, v is unchanged if the pod rules are not configured
+func (c *Config) UnmarshalPodRules(v interface{}) error {
+	content, ok := c.Fields[podRulesKey]
+	if !ok {
+		return nil
+	}
+	return c.UnmarshalSubConfig(content, v)
+}
+
+// LoadConfig loads and parses configuration data from the file and the fragments in the drop-in directory,
+// and save it to the Config. The fragment later in order overrides the whole value of the same key.
 func (c *Config) LoadConfig(path string) error {
 	if path == "" {
 		path = constant.ConfigFile
 	}
-	data, err := loadConfigFile(path)
+	files, err := ConfigFiles(path)
 	if err != nil {
-		return fmt.Errorf("failed to load config file %s: %w", path, err)
+		return err
+	}
+	fields := make(map[string]interface{})
+	for _, file := range files {
+		data, err := loadConfigFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to load config file %s: %w", file, err)
+		}
+		fragment, err := c.parserOf(file).ParseConfig(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse config file %s: %v", file, err)
+		}
+		for key, value := range fragment {
+			fields[key] = value
+		}
 	}
+	return c.setFields(fields)
+}
+
+// LoadConfigData parses the configuration data in the format of the configuration file, and save it to the Config
+func (c *Config) LoadConfigData(data []byte) error {
 	fields, err := c.ParseConfig(data)
 	if err != nil {
 		return fmt.Errorf("failed to parse config: %v", err)
 	}
+	return c.setFields(fields)
+}
+
+// setFields saves the parsed configuration and parses the agent configuration from it
+func (c *Config) setFields(fields map[string]interface{}) error {
 	c.Fields = fields
 	if err := c.parseAgentConfig(); err != nil {
 		return fmt.Errorf("failed to parse agent config: %v", err)
@@ -130,3 +207,19 @@ func (c *Config) UnwrapServiceConfig() map[string]interface{} {
 	}
 	return serviceConfig
 }
+
+// MergeServiceConfig replaces the service configurations by the fields indexed by the service name
+func (c *Config) MergeServiceConfig(fields map[string]interface{}) error {
+	for name := range fields {
+		if c.filterNonServiceKeys(name) {
+			return fmt.Errorf("%v is not a service", name)
+		}
+	}
+	if c.Fields == nil {
+		c.Fields = make(map[string]interface{}, len(fields))
+	}
+	for name, conf := range fields {
+		c.Fields[name] = conf
+	}
+	return nil
+}
@@ -15,6 +15,7 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -170,3 +171,151 @@ func TestUnwrapServiceConfig(t *testing.T) {
 		t.Fatalf("config is not exists")
 	}
 }
+
+// TestLoadConfigYAML tests loading the configuration in yaml
+func TestLoadConfigYAML(t *testing.T) {
+	const rubikConfig = `
+agent:
+  logLevel: debug
+  enabledFeatures:
+    - preemption
+preemption:
+  resource: [cpu]
+`
+	defer os.RemoveAll(constant.TmpTestDir)
+	tmpConfigFile := filepath.Join(constant.TmpTestDir, "config.yaml")
+	assert.NoError(t, util.WriteFile(tmpConfigFile, rubikConfig))
+
+	c := NewConfig(JSON)
+	assert.NoError(t, c.LoadConfig(tmpConfigFile))
+	assert.Equal(t, "debug", c.Agent.LogLevel)
+	assert.Equal(t, []string{"preemption"}, c.Agent.EnabledFeatures)
+	var preemption struct {
+		Resource []string `json:"resource"`
+	}
+	assert.NoError(t, c.UnmarshalSubConfig(c.UnwrapServiceConfig()["preemption"], &preemption))
+	assert.Equal(t, []string{"cpu"}, preemption.Resource)
+
+	c = NewConfig(YAML)
+	assert.NoError(t, c.LoadConfigData([]byte(`{"agent": {"logLevel": "warn"}}`)))
+	assert.Equal(t, "warn", c.Agent.LogLevel)
+	assert.Error(t, c.LoadConfigData([]byte("agent: [")))
+}
+
+// TestLoadConfigDropIn tests merging the fragments of the drop-in directory
+func TestLoadConfigDropIn(t *testing.T) {
+	defer os.RemoveAll(constant.TmpTestDir)
+	tmpConfigFile := filepath.Join(constant.TmpTestDir, "config.json")
+	dropInDir := filepath.Join(constant.TmpTestDir, DropInDirName)
+	files := map[string]string{
+		tmpConfigFile: `{"agent": {"logLevel": "info"}, "preemption": {"resource": ["cpu"]}, "psi": {"interval": 10}}`,
+		filepath.Join(dropInDir, "10-platform.yaml"): "agent:\n  logLevel: debug\npreemption:\n  resource: [memory]\n",
+		filepath.Join(dropInDir, "20-app.json"):      `{"preemption": {"resource": ["net"]}}`,
+		filepath.Join(dropInDir, "30-ignored.txt"):   `{"psi": {"interval": 20}}`,
+		filepath.Join(dropInDir, ".hidden.json"):     `{"psi": {"interval": 30}}`,
+	}
+	for path, content := range files {
+		assert.NoError(t, util.WriteFile(path, content))
+	}
+
+	got, err := ConfigFiles(tmpConfigFile)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{tmpConfigFile, filepath.Join(dropInDir, "10-platform.yaml"),
+		filepath.Join(dropInDir, "20-app.json")}, got)
+
+	c := NewConfig(JSON)
+	assert.NoError(t, c.LoadConfig(tmpConfigFile))
+	assert.Equal(t, "debug", c.Agent.LogLevel)
+	serviceConfig := c.UnwrapServiceConfig()
+	assert.Equal(t, map[string]interface{}{"resource": []interface{}{"net"}}, serviceConfig["preemption"])
+	assert.Equal(t, map[string]interface{}{"interval": json.Number("10")}, serviceConfig["psi"])
+
+	// a damaged fragment fails the whole configuration
+	assert.NoError(t, util.WriteFile(filepath.Join(dropInDir, "40-damaged.yaml"), "psi: ["))
+	err = NewConfig(JSON).LoadConfig(tmpConfigFile)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "40-damaged.yaml")
+}
+
+// TestApplyOverrides tests applying the overrides selecting the node
+func TestApplyOverrides(t *testing.T) {
+	const rubikConfig = `{
+	"agent": {"enabledFeatures": ["preemption", "psi"]},
+	"preemption": {
+		"resource": ["cpu"],
+		"overrides": [
+			{"nodeSelector": {"pool": "batch"}, "config": {"resource": ["cpu", "memory"]}},
+			{"nodeName": "node-1", "config": {"resource": ["net"]}}
+		]
+	},
+	"psi": {
+		"interval": 10,
+		"resource": ["cpu"],
+		"overrides": [{"nodeName": "node-2", "config": {"interval": 20}}]
+	},
+	"ioCost": [{"nodeName": "global"}]
+}`
+	const labelsErr = "apiserver unavailable"
+	var calls int
+	labels := func(l map[string]string, err error) NodeLabelsFunc {
+		return func() (map[string]string, error) {
+			calls++
+			return l, err
+		}
+	}
+	tests := []struct {
+		name           string
+		nodeName       string
+		nodeLabels     NodeLabelsFunc
+		wantErr        string
+		wantPreemption []interface{}
+		wantInterval   json.Number
+	}{
+		{name: "TC1-no node name", nodeLabels: labels(nil, fmt.Errorf(labelsErr)),
+			wantPreemption: []interface{}{"cpu"}, wantInterval: "10"},
+		{name: "TC2-select by labels", nodeName: "node-2", nodeLabels: labels(map[string]string{"pool": "batch"}, nil),
+			wantPreemption: []interface{}{"cpu", "memory"}, wantInterval: "20"},
+		{name: "TC3-later override wins", nodeName: "node-1",
+			nodeLabels:     labels(map[string]string{"pool": "batch", "zone": "a"}, nil),
+			wantPreemption: []interface{}{"net"}, wantInterval: "10"},
+		{name: "TC4-labels not matched", nodeName: "node-3", nodeLabels: labels(map[string]string{"pool": "online"}, nil),
+			wantPreemption: []interface{}{"cpu"}, wantInterval: "10"},
+		{name: "TC5-failed to get labels", nodeName: "node-3", nodeLabels: labels(nil, fmt.Errorf(labelsErr)),
+			wantErr: labelsErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = 0
+			c := NewConfig(JSON)
+			assert.NoError(t, c.LoadConfigData([]byte(rubikConfig)))
+			err := c.ApplyOverrides(tt.nodeName, tt.nodeLabels)
+			if tt.wantErr != "" {
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			if tt.nodeName == "" {
+				assert.Equal(t, 0, calls)
+			} else {
+				// the labels are got only once
+				assert.Equal(t, 1, calls)
+			}
+			preemption := c.UnwrapServiceConfig()["preemption"].(map[string]interface{})
+			assert.Equal(t, tt.wantPreemption, preemption["resource"])
+			assert.NotContains(t, preemption, overridesKey)
+			psi := c.UnwrapServiceConfig()["psi"].(map[string]interface{})
+			assert.Equal(t, tt.wantInterval, psi["interval"])
+			assert.Equal(t, []interface{}{"cpu"}, psi["resource"])
+		})
+	}
+
+	for _, invalid := range []string{
+		`{"psi": {"overrides": {"nodeName": "node-1"}}}`,
+		`{"psi": {"overrides": [{"config": {"interval": 20}}]}}`,
+		`{"psi": {"overrides": [{"nodeName": ["node-1"]}]}}`,
+	} {
+		c := NewConfig(JSON)
+		assert.NoError(t, c.LoadConfigData([]byte(invalid)))
+		assert.Error(t, c.ApplyOverrides("node-1", labels(nil, nil)), invalid)
+	}
+}
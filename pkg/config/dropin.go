@@ -0,0 +1,68 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file finds the configuration fragments in the drop-in directory
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DropInDirName is the name of the directory next to the configuration file,
+// whose fragments are merged into the configuration in lexical order
+const DropInDirName = "conf.d"
+
+// fileParserTypes is the parser type of the configuration file indexed by the extension
+var fileParserTypes = map[string]parserType{
+	".json": JSON,
+	".yaml": YAML,
+	".yml":  YAML,
+}
+
+// ConfigFiles returns the configuration file and the fragments in the drop-in directory in the order of merging
+func ConfigFiles(path string) ([]string, error) {
+	var (
+		files = []string{path}
+		dir   = filepath.Join(filepath.Dir(path), DropInDirName)
+	)
+	// the entries are sorted by file name
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return files, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read drop-in directory %v: %v", dir, err)
+	}
+	for _, entry := range entries {
+		// hidden entries such as the data directory of the mounted ConfigMap are skipped
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if _, ok := fileParserTypes[filepath.Ext(entry.Name())]; !ok {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	return files, nil
+}
+
+// parserOf returns the parser of the file according to its extension, the parser of the Config is used by default
+func (c *Config) parserOf(path string) ConfigParser {
+	if pType, ok := fileParserTypes[filepath.Ext(path)]; ok && pType != JSON {
+		return defaultParserFactory.getParser(pType)
+	}
+	return c.ConfigParser
+}
@@ -0,0 +1,120 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file applies the overrides of the service configuration selected by the node
+
+package config
+
+import (
+	"fmt"
+)
+
+// overridesKey is the key of the overrides in the service configuration
+const overridesKey = "overrides"
+
+// Override is the service configuration taking effect on the selected nodes
+type Override struct {
+	// NodeName selects the node by name
+	NodeName string `json:"nodeName,omitempty"`
+	// NodeSelector selects the nodes having all the labels
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Config replaces the fields of the service configuration
+	Config map[string]interface{} `json:"config,omitempty"`
+}
+
+// NodeLabelsFunc returns the labels of the node where rubik runs
+type NodeLabelsFunc func() (map[string]string, error)
+
+// matches returns true if the override selects the node
+func (o *Override) matches(nodeName string, labels map[string]string) bool {
+	if o.NodeName != "" && o.NodeName != nodeName {
+		return false
+	}
+	for k, v := range o.NodeSelector {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// parseOverrides parses the overrides of the service
+func (c *Config) parseOverrides(name string, data interface{}) ([]Override, error) {
+	items, ok := data.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("overrides of %v must be a list", name)
+	}
+	overrides := make([]Override, 0, len(items))
+	for i, item := range items {
+		var o Override
+		if err := c.UnmarshalSubConfig(item, &o); err != nil {
+			return nil, fmt.Errorf("invalid override %d of %v: %v", i, name, err)
+		}
+		if o.NodeName == "" && len(o.NodeSelector) == 0 {
+			return nil, fmt.Errorf("invalid override %d of %v: nodeName or nodeSelector is required", i, name)
+		}
+		// the values are taken from the parsed data to keep the numbers as they are written
+		if conf, ok := item.(map[string]interface{})["config"].(map[string]interface{}); ok {
+			o.Config = conf
+		}
+		overrides = append(overrides, o)
+	}
+	return overrides, nil
+}
+
+// ApplyOverrides merges the overrides selecting the node into the service configurations in order,
+// so the later override replaces the same field of the earlier one. The labels are only got if any
+// override selects nodes by labels. The overrides are checked and dropped if the node name is empty.
+func (c *Config) ApplyOverrides(nodeName string, nodeLabels NodeLabelsFunc) error {
+	var (
+		labels  map[string]string
+		fetched bool
+	)
+	for name, conf := range c.UnwrapServiceConfig() {
+		section, ok := conf.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		data, ok := section[overridesKey]
+		if !ok {
+			continue
+		}
+		overrides, err := c.parseOverrides(name, data)
+		if err != nil {
+			return err
+		}
+		merged := make(map[string]interface{}, len(section))
+		for k, v := range section {
+			if k != overridesKey {
+				merged[k] = v
+			}
+		}
+		for _, o := range overrides {
+			if nodeName == "" {
+				break
+			}
+			if len(o.NodeSelector) != 0 && !fetched {
+				if labels, err = nodeLabels(); err != nil {
+					return fmt.Errorf("failed to get labels of node %v: %v", nodeName, err)
+				}
+				fetched = true
+			}
+			if !o.matches(nodeName, labels) {
+				continue
+			}
+			for k, v := range o.Config {
+				merged[k] = v
+			}
+		}
+		c.Fields[name] = merged
+	}
+	return nil
+}
@@ -30,6 +30,8 @@ type (
 const (
 	// JSON represents the json type parser
 	JSON parserType = iota
+	// YAML represents the yaml type parser
+	YAML
 )
 
 // defaultParserFactory is globally unique parser factory
@@ -40,6 +42,8 @@ func (factory *parserFactory) getParser(pType parserType) ConfigParser {
 	switch pType {
 	case JSON:
 		return getJsonParser()
+	case YAML:
+		return getYamlParser()
 	default:
 		return getJsonParser()
 	}
@@ -0,0 +1,43 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file contains parsing functions for the yaml language
+
+package config
+
+import (
+	"sigs.k8s.io/yaml"
+)
+
+// defaultYamlParser is globally unique yaml parser
+var defaultYamlParser *yamlParser
+
+// yamlParser is used to parse yaml, the parsed configuration is handled in the same way as json
+type yamlParser struct {
+	*jsonParser
+}
+
+// getYamlParser gets the globally unique yaml parser
+func getYamlParser() *yamlParser {
+	if defaultYamlParser == nil {
+		defaultYamlParser = &yamlParser{jsonParser: getJsonParser()}
+	}
+	return defaultYamlParser
+}
+
+// ParseConfig parses yaml data as map[string]interface{}, json data is also accepted since json is a subset of yaml
+func (parser *yamlParser) ParseConfig(data []byte) (map[string]interface{}, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return parser.jsonParser.ParseConfig(jsonData)
+}
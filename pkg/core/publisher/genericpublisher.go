@@ -27,7 +27,7 @@ type (
 	// subscriberIDs records subscriber's ID
 	subscriberIDs map[string]struct{}
 	// NotifyFunc is used to notify subscribers of events
-	NotifyFunc func(typedef.EventType, typedef.Event)
+	NotifyFunc func(typedef.Event)
 )
 
 // defaultPublisher is the default is a globally unique generic publisher entity
@@ -40,6 +40,9 @@ type genericPublisher struct {
 	topicSubscribersMap map[typedef.EventType]subscriberIDs
 	// subscribers is the set of notification methods divided by ID
 	subscribers map[string]NotifyFunc
+	// queues deliver the events to the subscribers in the background, so that a slow subscriber
+	// does not block the publisher and the other subscribers
+	queues map[string]*Queue
 }
 
 // newGenericPublisher creates the genericPublisher instance
@@ -47,6 +50,7 @@ func newGenericPublisher() *genericPublisher {
 	pub := &genericPublisher{
 		subscribers:         make(map[string]NotifyFunc, 0),
 		topicSubscribersMap: make(map[typedef.EventType]subscriberIDs, 0),
+		queues:              make(map[string]*Queue, 0),
 	}
 	return pub
 }
@@ -82,11 +86,13 @@ func (pub *genericPublisher) Subscribe(s api.Subscriber) error {
 		log.Debugf("%s subscribes topic %s", id, topic)
 	}
 	pub.subscribers[id] = s.NotifyFunc
+	pub.queues[id] = NewQueue(id, s.NotifyFunc)
 	pub.Unlock()
 	return nil
 }
 
-// Unsubscribe unsubscribes the indicated subscriber
+// Unsubscribe unsubscribes the indicated subscriber, and returns after the subscriber finishes handling
+// the event being delivered
 func (pub *genericPublisher) Unsubscribe(s api.Subscriber) {
 	id := s.ID()
 	if !pub.subscriberExisted(id) {
@@ -99,15 +105,30 @@ func (pub *genericPublisher) Unsubscribe(s api.Subscriber) {
 		log.Debugf("%s unsubscribes topic %s", id, topic)
 	}
 	delete(pub.subscribers, id)
+	q, ok := pub.queues[id]
+	delete(pub.queues, id)
 	pub.Unlock()
+	// the queue is closed without holding the lock, since the handler may publish events
+	if ok {
+		q.Close()
+	}
 }
 
-// Publish publishes Event to subscribers interested in specified topic
-func (pub *genericPublisher) Publish(eventType typedef.EventType, data typedef.Event) {
+// Publish publishes Event to subscribers interested in specified topic.
+// The events are handled by each subscriber in the order they are published, Publish returns
+// after the events are queued except for the full pods, which are returned after they are handled.
+func (pub *genericPublisher) Publish(data typedef.Event) {
+	eventType := data.Type()
 	log.Debugf("publish %s event", eventType.String())
 	pub.RLock()
+	var queues = make([]*Queue, 0, len(pub.topicSubscribersMap[eventType]))
 	for id := range pub.topicSubscribersMap[eventType] {
-		pub.subscribers[id](eventType, data)
+		if q, ok := pub.queues[id]; ok {
+			queues = append(queues, q)
+		}
 	}
 	pub.RUnlock()
+	for _, q := range queues {
+		q.Push(data)
+	}
 }
@@ -32,7 +32,7 @@ func (s *mockSubscriber) ID() string {
 }
 
 // NotifyFunc notifys subscriber event
-func (s *mockSubscriber) NotifyFunc(eventType typedef.EventType, event typedef.Event) {}
+func (s *mockSubscriber) NotifyFunc(event typedef.Event) {}
 
 // TopicsFunc returns the topics that the subscriber is interested in
 func (s *mockSubscriber) TopicsFunc() []typedef.EventType {
@@ -88,7 +88,7 @@ func Test_genericPublisher_Subscribe(t *testing.T) {
 			if err := pub.Subscribe(tt.args.s); (err != nil) != tt.wantErr {
 				t.Errorf("genericPublisher.Subscribe() error = %v, wantErr %v", err, tt.wantErr)
 			}
-			pub.Publish(typedef.RAWPODADD, "a")
+			pub.Publish(typedef.RawPodAddEvent{Pod: &typedef.RawPod{}})
 		})
 	}
 }
@@ -0,0 +1,373 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements the bounded event queue delivering events in the background
+
+package publisher
+
+import (
+	"fmt"
+	"sync"
+
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/common/log"
+	"isula.org/rubik/pkg/common/metrics"
+	"isula.org/rubik/pkg/core/typedef"
+)
+
+// OverflowPolicy decides which events are discarded when the queue is full
+type OverflowPolicy string
+
+const (
+	// DropOldest discards the oldest event
+	DropOldest OverflowPolicy = "dropOldest"
+	// Coalesce merges the event into the pending event of the same pod, the oldest event is discarded
+	// if there is no pending event of the same pod
+	Coalesce OverflowPolicy = "coalesce"
+)
+
+const (
+	minQueueSize = 16
+	maxQueueSize = 65536
+)
+
+// the reasons of discarding the events
+const (
+	reasonDropped   = "dropped"
+	reasonCoalesced = "coalesced"
+	// reasonOverflowed is the reason of dropping the event which is never dropped by the overflow policy
+	// since the queue reaches its hard limit
+	reasonOverflowed = "overflowed"
+)
+
+// hardLimitFactor times the size of the queue is the hard limit of the number of the pending events
+const hardLimitFactor = 2
+
+var (
+	queueSize   = constant.DefaultEventQueueSize
+	queuePolicy = OverflowPolicy(constant.DefaultEventOverflowPolicy)
+	queueLock   sync.RWMutex
+)
+
+// CheckQueueConfig checks the size and the overflow policy of the event queues
+func CheckQueueConfig(size int, policy string) error {
+	if size < minQueueSize || size > maxQueueSize {
+		return fmt.Errorf("invalid event queue size: %d (valid range is %d-%d)", size, minQueueSize, maxQueueSize)
+	}
+	switch OverflowPolicy(policy) {
+	case DropOldest, Coalesce:
+		return nil
+	default:
+		return fmt.Errorf("invalid event overflow policy: %v (valid policies are %v and %v)",
+			policy, DropOldest, Coalesce)
+	}
+}
+
+// InitQueue sets the size and the overflow policy of the event queues created afterwards
+func InitQueue(size int, policy string) error {
+	if err := CheckQueueConfig(size, policy); err != nil {
+		return err
+	}
+	queueLock.Lock()
+	queueSize, queuePolicy = size, OverflowPolicy(policy)
+	queueLock.Unlock()
+	return nil
+}
+
+// syncEvents are the full pods, which are never discarded and published until they are handled
+var syncEvents = map[typedef.EventType]struct{}{
+	typedef.RAWPODSYNCALL:       {},
+	typedef.NRIPODSYNCALL:       {},
+	typedef.NRICONTAINERSYNCALL: {},
+}
+
+type eventKind int8
+
+const (
+	addKind eventKind = iota
+	updateKind
+	deleteKind
+)
+
+// eventKinds are the kinds of the events of the same object which can be coalesced
+var eventKinds = map[typedef.EventType]struct {
+	object string
+	kind   eventKind
+}{
+	typedef.RAWPODADD:          {"raw", addKind},
+	typedef.RAWPODUPDATE:       {"raw", updateKind},
+	typedef.RAWPODDELETE:       {"raw", deleteKind},
+	typedef.INFOADD:            {"info", addKind},
+	typedef.INFOUPDATE:         {"info", updateKind},
+	typedef.INFODELETE:         {"info", deleteKind},
+	typedef.NRIPODADD:          {"nripod", addKind},
+	typedef.NRIPODDELETE:       {"nripod", deleteKind},
+	typedef.NRICONTAINERSTART:  {"nricontainer", addKind},
+	typedef.NRICONTAINERREMOVE: {"nricontainer", deleteKind},
+}
+
+// queuedEvent is an event waiting to be handled
+type queuedEvent struct {
+	event typedef.Event
+	// done is closed after the sync event is handled, it is nil for the other events
+	done chan struct{}
+}
+
+// key returns the object of the event, which is empty if the event can not be coalesced
+func (e *queuedEvent) key() string {
+	k, ok := eventKinds[e.event.Type()]
+	if !ok {
+		return ""
+	}
+	var id string
+	switch event := e.event.(type) {
+	case typedef.RawPodAddEvent:
+		id = string(event.Pod.UID)
+	case typedef.RawPodUpdateEvent:
+		id = string(event.New.UID)
+	case typedef.RawPodDeleteEvent:
+		id = string(event.Pod.UID)
+	case typedef.PodAddEvent:
+		id = event.Pod.UID
+	case typedef.PodUpdateEvent:
+		id = event.New.UID
+	case typedef.PodDeleteEvent:
+		id = event.Pod.UID
+	case typedef.NRIPodAddEvent:
+		id = event.Pod.Uid
+	case typedef.NRIPodDeleteEvent:
+		id = event.Pod.Uid
+	case typedef.NRIContainerStartEvent:
+		id = event.Container.Id
+	case typedef.NRIContainerRemoveEvent:
+		id = event.Container.Id
+	}
+	if id == "" {
+		return ""
+	}
+	return k.object + "/" + id
+}
+
+// droppable returns true if the event can be dropped when the queue is full. The sync events and the events
+// adding or deleting the objects are never dropped, otherwise the subscriber misses or leaks the objects
+// since nothing resyncs them afterwards.
+func (e *queuedEvent) droppable() bool {
+	k, ok := eventKinds[e.event.Type()]
+	return e.done == nil && ok && k.kind == updateKind
+}
+
+// coalesce merges the next event of the same object into the event
+func (e *queuedEvent) coalesce(next *queuedEvent) {
+	prev, cur := eventKinds[e.event.Type()].kind, eventKinds[next.event.Type()].kind
+	switch {
+	case prev == addKind && cur == updateKind:
+		// the added object is replaced by the updated one
+		switch update := next.event.(type) {
+		case typedef.RawPodUpdateEvent:
+			e.event = typedef.RawPodAddEvent{Pod: update.New}
+		case typedef.PodUpdateEvent:
+			e.event = typedef.PodAddEvent{Pod: update.New}
+		default:
+			e.event = next.event
+		}
+	case prev == updateKind && cur == updateKind:
+		// the old object of the first update is kept
+		switch update := next.event.(type) {
+		case typedef.RawPodUpdateEvent:
+			update.Old = e.event.(typedef.RawPodUpdateEvent).Old
+			e.event = update
+		case typedef.PodUpdateEvent:
+			update.Old = e.event.(typedef.PodUpdateEvent).Old
+			e.event = update
+		default:
+			e.event = next.event
+		}
+	default:
+		// the deletion of the added object is still delivered, so that the subscriber waiting for
+		// the deletion is notified
+		e.event = next.event
+	}
+}
+
+// Queue delivers the events to the handler one by one in the order they are pushed.
+// Pushing never blocks except for the sync events, the update events are discarded by the overflow policy
+// if the handler can not keep up with them. The queue grows beyond its size if there is no update event
+// to discard, up to the hard limit of twice its size. Beyond the hard limit, the events adding or deleting
+// the objects are also dropped and counted as overflowed, while the sync events are still queued since
+// their pushers are blocked until they are handled.
+type Queue struct {
+	name    string
+	handler NotifyFunc
+	size    int
+	policy  OverflowPolicy
+
+	lock   sync.Mutex
+	cond   *sync.Cond
+	events []*queuedEvent
+	closed bool
+	// overflowed is true after the queue is full until it is drained, which avoids flooding the log
+	overflowed bool
+	// exceeded is true after the queue reaches the hard limit until it is drained
+	exceeded bool
+	// done is closed after the queue stops delivering the events
+	done chan struct{}
+}
+
+// NewQueue creates the queue named by the name and starts delivering the events to the handler
+func NewQueue(name string, handler NotifyFunc) *Queue {
+	queueLock.RLock()
+	size, policy := queueSize, queuePolicy
+	queueLock.RUnlock()
+	return newQueue(name, handler, size, policy)
+}
+
+func newQueue(name string, handler NotifyFunc, size int, policy OverflowPolicy) *Queue {
+	q := &Queue{name: name, handler: handler, size: size, policy: policy, done: make(chan struct{})}
+	q.cond = sync.NewCond(&q.lock)
+	go q.run()
+	return q
+}
+
+// Push appends the event to the queue, the sync event is returned after it is handled.
+// False is returned if the event is dropped since the queue is closed or reaches its hard limit.
+func (q *Queue) Push(event typedef.Event) bool {
+	e := &queuedEvent{event: event}
+	if _, ok := syncEvents[event.Type()]; ok {
+		e.done = make(chan struct{})
+	}
+	q.lock.Lock()
+	if q.closed {
+		q.lock.Unlock()
+		return false
+	}
+	var queued = true
+	switch {
+	case len(q.events) < q.size || e.done != nil:
+		q.events = append(q.events, e)
+	case q.discard(e):
+	case len(q.events) >= hardLimitFactor*q.size:
+		queued = false
+		q.exceed(e)
+	default:
+		q.events = append(q.events, e)
+	}
+	metrics.EventQueueDepth.Set(float64(len(q.events)), q.name)
+	q.cond.Signal()
+	q.lock.Unlock()
+	if e.done != nil {
+		<-e.done
+	}
+	return queued
+}
+
+// exceed drops the event since the queue reaches its hard limit
+func (q *Queue) exceed(e *queuedEvent) {
+	metrics.EventsDiscarded.Inc(q.name, reasonOverflowed)
+	if !q.exceeded {
+		q.exceeded = true
+		log.Errorf("event queue %v reaches its limit of %d events, drop the %v events until it is drained",
+			q.name, hardLimitFactor*q.size, e.event.Type().String())
+	}
+}
+
+// discard makes room for the event in the full queue by merging it into the pending event of the same object
+// or dropping the oldest update event, true is returned if the event is merged and needs not to be appended
+func (q *Queue) discard(e *queuedEvent) bool {
+	if !q.overflowed {
+		q.overflowed = true
+		log.Warnf("event queue %v is full, discard the events by the %v policy", q.name, q.policy)
+	}
+	if key := e.key(); q.policy == Coalesce && key != "" {
+		for i := len(q.events) - 1; i >= 0; i-- {
+			// the event after the sync event is not merged into the event before it
+			if q.events[i].done != nil {
+				break
+			}
+			if q.events[i].key() != key {
+				continue
+			}
+			metrics.EventsDiscarded.Inc(q.name, reasonCoalesced)
+			q.events[i].coalesce(e)
+			return true
+		}
+	}
+	for i, pending := range q.events {
+		if pending.droppable() {
+			q.events = append(q.events[:i], q.events[i+1:]...)
+			metrics.EventsDiscarded.Inc(q.name, reasonDropped)
+			break
+		}
+	}
+	return false
+}
+
+// Stop stops delivering the events without waiting for the event being handled, the pending events
+// are discarded and the pushers waiting for the sync events are released. It is used when the handler
+// may wait for the lock held by the caller.
+func (q *Queue) Stop() {
+	q.lock.Lock()
+	q.closed = true
+	q.dropLocked()
+	q.cond.Signal()
+	q.lock.Unlock()
+}
+
+// Close stops delivering the events as Stop and waits for the event being handled,
+// it must not be called by the handler
+func (q *Queue) Close() {
+	q.Stop()
+	<-q.done
+}
+
+func (q *Queue) dropLocked() {
+	for _, e := range q.events {
+		if e.done != nil {
+			close(e.done)
+		}
+	}
+	q.events = nil
+	metrics.EventQueueDepth.Set(0, q.name)
+}
+
+// run delivers the events until the queue is closed
+func (q *Queue) run() {
+	defer close(q.done)
+	for {
+		q.lock.Lock()
+		for len(q.events) == 0 && !q.closed {
+			q.overflowed, q.exceeded = false, false
+			q.cond.Wait()
+		}
+		if q.closed {
+			q.lock.Unlock()
+			return
+		}
+		e := q.events[0]
+		q.events[0] = nil
+		q.events = q.events[1:]
+		metrics.EventQueueDepth.Set(float64(len(q.events)), q.name)
+		q.lock.Unlock()
+		q.handle(e)
+	}
+}
+
+// handle delivers the event to the handler, the panic of the handler does not stop the queue
+func (q *Queue) handle(e *queuedEvent) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Errorf("event queue %v catch a panic while handling %v: %v", q.name, e.event.Type().String(), err)
+		}
+		if e.done != nil {
+			close(e.done)
+		}
+	}()
+	q.handler(e.event)
+}
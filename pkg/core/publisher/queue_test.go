@@ -0,0 +1,236 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file tests the bounded event queue
+
+package publisher
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"isula.org/rubik/pkg/core/typedef"
+)
+
+const blockUID = "block"
+
+// recorder records the handled events, the handler is blocked by the pod blockUID until it is released
+type recorder struct {
+	sync.Mutex
+	started chan struct{}
+	release chan struct{}
+	events  []string
+}
+
+func newRecorder() *recorder {
+	return &recorder{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (r *recorder) handle(event typedef.Event) {
+	var desc string
+	switch e := event.(type) {
+	case typedef.PodAddEvent:
+		if e.Pod.UID == blockUID {
+			close(r.started)
+			<-r.release
+		}
+		desc = e.Pod.UID
+	case typedef.PodUpdateEvent:
+		desc = e.Old.Name + "->" + e.New.Name
+	case typedef.PodDeleteEvent:
+		desc = e.Pod.UID
+	}
+	r.Lock()
+	r.events = append(r.events, describe(event.Type(), desc))
+	r.Unlock()
+}
+
+func describe(eventType typedef.EventType, desc string) string {
+	return eventType.String() + ":" + desc
+}
+
+func (r *recorder) handled() []string {
+	r.Lock()
+	defer r.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+// newTestQueue creates the queue whose handler is blocked so that the events are pending
+func newTestQueue(t *testing.T, size int, policy OverflowPolicy) (*Queue, *recorder) {
+	r := newRecorder()
+	q := newQueue(t.Name(), r.handle, size, policy)
+	q.Push(podAdd(blockUID))
+	<-r.started
+	return q, r
+}
+
+func podAdd(uid string) typedef.PodAddEvent {
+	return typedef.PodAddEvent{Pod: &typedef.PodInfo{UID: uid}}
+}
+
+func podUpdate(uid, oldName, newName string) typedef.PodUpdateEvent {
+	return typedef.PodUpdateEvent{
+		Old: &typedef.PodInfo{UID: uid, Name: oldName},
+		New: &typedef.PodInfo{UID: uid, Name: newName},
+	}
+}
+
+func podDelete(uid string) typedef.PodDeleteEvent {
+	return typedef.PodDeleteEvent{Pod: &typedef.PodInfo{UID: uid}}
+}
+
+// waitHandled releases the handler and waits for the expected events
+func waitHandled(t *testing.T, r *recorder, want []string) {
+	close(r.release)
+	assert.Eventually(t, func() bool { return len(r.handled()) >= len(want) }, time.Second, time.Millisecond)
+	assert.Equal(t, want, r.handled())
+}
+
+// TestCheckQueueConfig tests checking the size and the overflow policy of the queues
+func TestCheckQueueConfig(t *testing.T) {
+	assert.NoError(t, CheckQueueConfig(minQueueSize, string(DropOldest)))
+	assert.NoError(t, CheckQueueConfig(maxQueueSize, string(Coalesce)))
+	assert.Error(t, CheckQueueConfig(minQueueSize-1, string(Coalesce)))
+	assert.Error(t, CheckQueueConfig(maxQueueSize+1, string(Coalesce)))
+	assert.Error(t, CheckQueueConfig(minQueueSize, "dropNewest"))
+}
+
+// TestQueue_DropOldest tests the events are delivered in order and the oldest update events are dropped
+func TestQueue_DropOldest(t *testing.T) {
+	q, r := newTestQueue(t, 2, DropOldest)
+	defer q.Close()
+	q.Push(podUpdate("a", "a0", "a1"))
+	q.Push(podAdd("b"))
+	q.Push(podUpdate("c", "c0", "c1"))
+	q.Push(podDelete("d"))
+	// there is no update event to drop, the additions and deletions are never dropped
+	q.Push(podAdd("e"))
+	waitHandled(t, r, []string{
+		describe(typedef.INFOADD, blockUID),
+		describe(typedef.INFOADD, "b"),
+		describe(typedef.INFODELETE, "d"),
+		describe(typedef.INFOADD, "e"),
+	})
+}
+
+// TestQueue_Coalesce tests the events of the same pod are merged when the queue is full
+func TestQueue_Coalesce(t *testing.T) {
+	q, r := newTestQueue(t, 2, Coalesce)
+	defer q.Close()
+	q.Push(podAdd("a"))
+	q.Push(podAdd("b"))
+	// the added pod is replaced by the updated one
+	q.Push(podUpdate("a", "a0", "a1"))
+	// the addition of the pod deleted is replaced by the deletion
+	q.Push(podDelete("b"))
+	q.Push(podUpdate("c", "c0", "c1"))
+	// there is no pending event of pod d, so the oldest update event is dropped
+	q.Push(podAdd("d"))
+	// there is no update event to drop, so the queue grows
+	q.Push(podUpdate("e", "e0", "e1"))
+	// the old pod of the first update is kept
+	q.Push(podUpdate("e", "e1", "e2"))
+	waitHandled(t, r, []string{
+		describe(typedef.INFOADD, blockUID),
+		describe(typedef.INFOADD, "a"),
+		describe(typedef.INFODELETE, "b"),
+		describe(typedef.INFOADD, "d"),
+		describe(typedef.INFOUPDATE, "e0->e2"),
+	})
+}
+
+// TestQueue_Sync tests the sync events are never discarded and pushed until they are handled
+func TestQueue_Sync(t *testing.T) {
+	q, r := newTestQueue(t, 2, DropOldest)
+	defer q.Close()
+	q.Push(podUpdate("a", "a0", "a1"))
+	q.Push(podUpdate("b", "b0", "b1"))
+	pushed := make(chan struct{})
+	go func() {
+		q.Push(typedef.RawPodSyncAllEvent{})
+		close(pushed)
+	}()
+	assert.Eventually(t, func() bool {
+		q.lock.Lock()
+		defer q.lock.Unlock()
+		return len(q.events) == 3
+	}, time.Second, time.Millisecond)
+	q.Push(podUpdate("c", "c0", "c1"))
+	q.Push(podUpdate("d", "d0", "d1"))
+	select {
+	case <-pushed:
+		t.Fatal("the sync event is pushed before it is handled")
+	default:
+	}
+	waitHandled(t, r, []string{
+		describe(typedef.INFOADD, blockUID),
+		describe(typedef.RAWPODSYNCALL, ""),
+		describe(typedef.INFOUPDATE, "c0->c1"),
+		describe(typedef.INFOUPDATE, "d0->d1"),
+	})
+	<-pushed
+}
+
+// TestQueue_HardLimit tests the events never dropped by the overflow policy are dropped beyond the hard limit
+func TestQueue_HardLimit(t *testing.T) {
+	q, r := newTestQueue(t, 2, DropOldest)
+	defer q.Close()
+	for _, uid := range []string{"a", "b", "c", "d"} {
+		assert.True(t, q.Push(podAdd(uid)))
+	}
+	assert.False(t, q.Push(podDelete("a")))
+	waitHandled(t, r, []string{
+		describe(typedef.INFOADD, blockUID),
+		describe(typedef.INFOADD, "a"),
+		describe(typedef.INFOADD, "b"),
+		describe(typedef.INFOADD, "c"),
+		describe(typedef.INFOADD, "d"),
+	})
+	// the queue accepts the events again after it is drained
+	assert.True(t, q.Push(podDelete("a")))
+}
+
+// TestQueue_Close tests the pending events are discarded after the queue is closed
+// and Close returns after the event being handled
+func TestQueue_Close(t *testing.T) {
+	q, r := newTestQueue(t, 2, DropOldest)
+	q.Push(podAdd("a"))
+	pushed := make(chan struct{})
+	go func() {
+		q.Push(typedef.RawPodSyncAllEvent{})
+		close(pushed)
+	}()
+	assert.Eventually(t, func() bool {
+		q.lock.Lock()
+		defer q.lock.Unlock()
+		return len(q.events) == 2
+	}, time.Second, time.Millisecond)
+	closed := make(chan struct{})
+	go func() {
+		q.Close()
+		close(closed)
+	}()
+	// the sync event is released without being handled
+	<-pushed
+	assert.False(t, q.Push(podAdd("b")))
+	time.Sleep(10 * time.Millisecond)
+	select {
+	case <-closed:
+		t.Fatal("the queue is closed before the event being handled")
+	default:
+	}
+	close(r.release)
+	<-closed
+	assert.Equal(t, []string{describe(typedef.INFOADD, blockUID)}, r.handled())
+}
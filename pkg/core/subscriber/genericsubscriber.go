@@ -16,6 +16,7 @@ package subscriber
 
 import (
 	"isula.org/rubik/pkg/api"
+	"isula.org/rubik/pkg/common/metrics"
 	"isula.org/rubik/pkg/core/typedef"
 )
 
@@ -38,8 +39,9 @@ func (pub *genericSubscriber) ID() string {
 }
 
 // NotifyFunc notifys subscriber event
-func (pub *genericSubscriber) NotifyFunc(eventType typedef.EventType, event typedef.Event) {
-	pub.HandleEvent(eventType, event)
+func (pub *genericSubscriber) NotifyFunc(event typedef.Event) {
+	pub.HandleEvent(event)
+	metrics.EventsHandled.Inc(pub.id, event.Type().String())
 }
 
 // TopicsFunc returns the topics that the subscriber is interested in
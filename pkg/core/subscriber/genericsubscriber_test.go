@@ -20,13 +20,14 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"isula.org/rubik/pkg/api"
+	"isula.org/rubik/pkg/common/metrics"
 	"isula.org/rubik/pkg/core/typedef"
 )
 
 type mockEventHandler struct{}
 
 // HandleEvent handles the event from publisher
-func (h *mockEventHandler) HandleEvent(eventType typedef.EventType, event typedef.Event) {}
+func (h *mockEventHandler) HandleEvent(event typedef.Event) {}
 
 // EventTypes returns the intersted event types
 func (h *mockEventHandler) EventTypes() []typedef.EventType {
@@ -55,7 +56,8 @@ func TestNewGenericSubscriber(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			got := NewGenericSubscriber(tt.args.handler, tt.args.id)
 			assert.Equal(t, "rubik", got.ID())
-			got.NotifyFunc(typedef.INFOADD, nil)
+			got.NotifyFunc(typedef.PodAddEvent{})
+			assert.Equal(t, float64(1), metrics.EventsHandled.Value("rubik", typedef.INFOADD.String()))
 			got.TopicsFunc()
 		})
 	}
@@ -0,0 +1,138 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements dispatching the events to the typed handlers
+
+package subscriber
+
+import "isula.org/rubik/pkg/core/typedef"
+
+// Handlers are the handlers of the event types, each receives the payload struct of its event type,
+// so that the subscriber and the publisher agree on the payload at compile time.
+// The event types without a handler are not subscribed.
+type Handlers struct {
+	OnRawPodAdd           func(typedef.RawPodAddEvent)
+	OnRawPodUpdate        func(typedef.RawPodUpdateEvent)
+	OnRawPodDelete        func(typedef.RawPodDeleteEvent)
+	OnRawPodSyncAll       func(typedef.RawPodSyncAllEvent)
+	OnPodAdd              func(typedef.PodAddEvent)
+	OnPodUpdate           func(typedef.PodUpdateEvent)
+	OnPodDelete           func(typedef.PodDeleteEvent)
+	OnNRIPodAdd           func(typedef.NRIPodAddEvent)
+	OnNRIPodDelete        func(typedef.NRIPodDeleteEvent)
+	OnNRIContainerStart   func(typedef.NRIContainerStartEvent)
+	OnNRIContainerRemove  func(typedef.NRIContainerRemoveEvent)
+	OnNRIPodSyncAll       func(typedef.NRIPodSyncAllEvent)
+	OnNRIContainerSyncAll func(typedef.NRIContainerSyncAllEvent)
+}
+
+// Dispatch calls the handler of the event, it returns false if the event type has no handler
+func (h *Handlers) Dispatch(event typedef.Event) bool {
+	switch e := event.(type) {
+	case typedef.RawPodAddEvent:
+		if h.OnRawPodAdd != nil {
+			h.OnRawPodAdd(e)
+			return true
+		}
+	case typedef.RawPodUpdateEvent:
+		if h.OnRawPodUpdate != nil {
+			h.OnRawPodUpdate(e)
+			return true
+		}
+	case typedef.RawPodDeleteEvent:
+		if h.OnRawPodDelete != nil {
+			h.OnRawPodDelete(e)
+			return true
+		}
+	case typedef.RawPodSyncAllEvent:
+		if h.OnRawPodSyncAll != nil {
+			h.OnRawPodSyncAll(e)
+			return true
+		}
+	case typedef.PodAddEvent:
+		if h.OnPodAdd != nil {
+			h.OnPodAdd(e)
+			return true
+		}
+	case typedef.PodUpdateEvent:
+		if h.OnPodUpdate != nil {
+			h.OnPodUpdate(e)
+			return true
+		}
+	case typedef.PodDeleteEvent:
+		if h.OnPodDelete != nil {
+			h.OnPodDelete(e)
+			return true
+		}
+	case typedef.NRIPodAddEvent:
+		if h.OnNRIPodAdd != nil {
+			h.OnNRIPodAdd(e)
+			return true
+		}
+	case typedef.NRIPodDeleteEvent:
+		if h.OnNRIPodDelete != nil {
+			h.OnNRIPodDelete(e)
+			return true
+		}
+	case typedef.NRIContainerStartEvent:
+		if h.OnNRIContainerStart != nil {
+			h.OnNRIContainerStart(e)
+			return true
+		}
+	case typedef.NRIContainerRemoveEvent:
+		if h.OnNRIContainerRemove != nil {
+			h.OnNRIContainerRemove(e)
+			return true
+		}
+	case typedef.NRIPodSyncAllEvent:
+		if h.OnNRIPodSyncAll != nil {
+			h.OnNRIPodSyncAll(e)
+			return true
+		}
+	case typedef.NRIContainerSyncAllEvent:
+		if h.OnNRIContainerSyncAll != nil {
+			h.OnNRIContainerSyncAll(e)
+			return true
+		}
+	}
+	return false
+}
+
+// EventTypes returns the event types with handlers, which are the topics to subscribe
+func (h *Handlers) EventTypes() []typedef.EventType {
+	var (
+		types   []typedef.EventType
+		handled = []struct {
+			eventType typedef.EventType
+			set       bool
+		}{
+			{typedef.RAWPODADD, h.OnRawPodAdd != nil},
+			{typedef.RAWPODUPDATE, h.OnRawPodUpdate != nil},
+			{typedef.RAWPODDELETE, h.OnRawPodDelete != nil},
+			{typedef.RAWPODSYNCALL, h.OnRawPodSyncAll != nil},
+			{typedef.INFOADD, h.OnPodAdd != nil},
+			{typedef.INFOUPDATE, h.OnPodUpdate != nil},
+			{typedef.INFODELETE, h.OnPodDelete != nil},
+			{typedef.NRIPODADD, h.OnNRIPodAdd != nil},
+			{typedef.NRIPODDELETE, h.OnNRIPodDelete != nil},
+			{typedef.NRICONTAINERSTART, h.OnNRIContainerStart != nil},
+			{typedef.NRICONTAINERREMOVE, h.OnNRIContainerRemove != nil},
+			{typedef.NRIPODSYNCALL, h.OnNRIPodSyncAll != nil},
+			{typedef.NRICONTAINERSYNCALL, h.OnNRIContainerSyncAll != nil},
+		}
+	)
+	for _, t := range handled {
+		if t.set {
+			types = append(types, t.eventType)
+		}
+	}
+	return types
+}
@@ -0,0 +1,45 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file tests dispatching the events to the typed handlers
+
+package subscriber
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"isula.org/rubik/pkg/core/typedef"
+)
+
+// TestHandlers tests the events are dispatched to the handlers of their types
+func TestHandlers(t *testing.T) {
+	var (
+		added   *typedef.PodInfo
+		updated *typedef.PodInfo
+	)
+	h := &Handlers{
+		OnPodAdd:    func(e typedef.PodAddEvent) { added = e.Pod },
+		OnPodUpdate: func(e typedef.PodUpdateEvent) { updated = e.New },
+	}
+	assert.Equal(t, []typedef.EventType{typedef.INFOADD, typedef.INFOUPDATE}, h.EventTypes())
+
+	pod := &typedef.PodInfo{UID: "testPod1"}
+	assert.True(t, h.Dispatch(typedef.PodAddEvent{Pod: pod}))
+	assert.Equal(t, pod, added)
+	assert.Nil(t, updated)
+	assert.True(t, h.Dispatch(typedef.PodUpdateEvent{Old: pod, New: pod}))
+	assert.Equal(t, pod, updated)
+	// the event type without a handler is not handled
+	assert.False(t, h.Dispatch(typedef.PodDeleteEvent{Pod: pod}))
+	assert.Empty(t, (&Handlers{}).EventTypes())
+}
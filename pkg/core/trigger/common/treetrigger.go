@@ -50,6 +50,9 @@ func (t *TreeTrigger) Activate(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to execute %v: %v", t.name, err)
 	}
+	if res != nil {
+		res = context.WithValue(res, TRIGGER, t.name)
+	}
 
 	for _, next := range t.subTriggers {
 		if err := next.Activate(res); err != nil {
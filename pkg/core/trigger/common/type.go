@@ -22,6 +22,8 @@ type (
 
 const (
 	TARGETPODS Factor = iota
+	// TRIGGER is the name of the trigger activating the current trigger
+	TRIGGER
 )
 
 // Descriptor defines methods for describing triggers
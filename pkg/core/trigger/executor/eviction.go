@@ -17,16 +17,22 @@ import (
 	"context"
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"isula.org/rubik/pkg/common/audit"
 	"isula.org/rubik/pkg/common/log"
+	"isula.org/rubik/pkg/common/metrics"
 	"isula.org/rubik/pkg/common/util"
 	"isula.org/rubik/pkg/core/trigger/common"
 	"isula.org/rubik/pkg/core/typedef"
 	"isula.org/rubik/pkg/lib/kubernetes"
 )
 
+// reasonEvicted is the reason of the event on the evicted pod
+const reasonEvicted = "Evicted"
+
 func EvictPod(ctx context.Context) error {
 	var errs error
 	client, err := kubernetes.GetClient()
@@ -37,6 +43,8 @@ func EvictPod(ctx context.Context) error {
 	if !ok {
 		return fmt.Errorf("failed to get target pods")
 	}
+	// the trigger is used to distinguish the evictions in metrics
+	trigger, _ := ctx.Value(common.TRIGGER).(string)
 	eviction := &policyv1beta1.Eviction{
 		ObjectMeta:    metav1.ObjectMeta{},
 		DeleteOptions: &metav1.DeleteOptions{},
@@ -45,13 +53,23 @@ func EvictPod(ctx context.Context) error {
 		log.Infof("evicting pod \"%v\"", name)
 		if err := inevictable(pod); err != nil {
 			errs = util.AppendErr(errs, fmt.Errorf("failed to evict pod \"%v\": %v", pod.Name, err))
+			metrics.Evictions.Inc(trigger, metrics.ResultFailed)
+			continue
+		}
+		if audit.Discard(audit.Origin{PodUID: pod.UID}, "evict pod %v/%v triggered by %v",
+			pod.Namespace, pod.Name, trigger) {
 			continue
 		}
 		eviction.ObjectMeta.Name = pod.Name
 		eviction.ObjectMeta.Namespace = pod.Namespace
 		if err := client.CoreV1().Pods(pod.Namespace).Evict(context.TODO(), eviction); err != nil {
 			errs = util.AppendErr(errs, fmt.Errorf("failed to evict pod \"%v\": %v", pod.Name, err))
+			metrics.Evictions.Inc(trigger, metrics.ResultFailed)
+			continue
 		}
+		metrics.Evictions.Inc(trigger, metrics.ResultSucceeded)
+		kubernetes.RecordEvent(kubernetes.PodReference(pod.Namespace, pod.Name, pod.UID), corev1.EventTypeWarning,
+			reasonEvicted, "the pod is evicted by rubik due to the pressure detected by %v", trigger)
 	}
 	return errs
 }
@@ -20,6 +20,7 @@ import (
 	"strconv"
 	"strings"
 
+	"isula.org/rubik/pkg/common/audit"
 	"isula.org/rubik/pkg/common/constant"
 	"isula.org/rubik/pkg/common/util"
 )
@@ -28,11 +29,14 @@ import (
 type Config struct {
 	RootDir      string
 	CgroupDriver Driver
+	// Version is the cgroup hierarchy version, it is detected from RootDir during Init if it is empty
+	Version string
 }
 
 var conf = &Config{
 	RootDir:      constant.DefaultCgroupRoot,
 	CgroupDriver: defaultDriver(),
+	Version:      constant.CgroupVersionV1,
 }
 
 type option func(c *Config) error
@@ -55,6 +59,18 @@ func WithDriver(driverType string) option {
 	}
 }
 
+// WithVersion sets the cgroup version, the version is detected from the mount point if it is empty
+func WithVersion(version string) option {
+	return func(c *Config) error {
+		switch version {
+		case constant.CgroupVersionAuto, constant.CgroupVersionV1, constant.CgroupVersionV2:
+			c.Version = version
+			return nil
+		}
+		return fmt.Errorf("invalid cgroup version: %v", version)
+	}
+}
+
 // Init sets the mount directory of the cgroup file system & driver
 func Init(opts ...option) error {
 	for _, opt := range opts {
@@ -62,23 +78,77 @@ func Init(opts ...option) error {
 			return fmt.Errorf("failed to init cgroup: %v", err)
 		}
 	}
+	if conf.Version == constant.CgroupVersionAuto {
+		version, err := detectVersion(conf.RootDir)
+		if err != nil {
+			return fmt.Errorf("failed to detect cgroup version: %v", err)
+		}
+		conf.Version = version
+	}
 	return nil
 }
 
-// AbsoluteCgroupPath returns the absolute path of the cgroup
+// AbsoluteCgroupPath returns the absolute path of the cgroup.
+// The first element is the subsystem which is ignored in cgroup v2,
+// and the last element is converted to the file name of the unified hierarchy.
 func AbsoluteCgroupPath(elem ...string) string {
-	elem = append([]string{conf.RootDir}, elem...)
+	return absolutePath(conf.RootDir, elem...)
+}
+
+func absolutePath(mountPoint string, elem ...string) string {
+	if !IsUnified() || len(elem) == 0 {
+		return filepath.Join(append([]string{mountPoint}, elem...)...)
+	}
+	elem = append([]string{mountPoint}, elem[1:]...)
+	elem[len(elem)-1] = unifiedFileName(elem[len(elem)-1])
 	return filepath.Join(elem...)
 }
 
 // ReadCgroupFile reads data from cgroup files
 func ReadCgroupFile(elem ...string) ([]byte, error) {
-	return readCgroupFile(AbsoluteCgroupPath(elem...))
+	if !IsUnified() {
+		return readCgroupFile(AbsoluteCgroupPath(elem...))
+	}
+	key, path, err := splitCgroupElem(elem)
+	if err != nil {
+		return nil, err
+	}
+	return readUnifiedFile(conf.RootDir, path, key)
 }
 
 // WriteCgroupFile writes data to cgroup file
 func WriteCgroupFile(content string, elem ...string) error {
-	return writeCgroupFile(AbsoluteCgroupPath(elem...), content)
+	return WriteCgroupFileAs(audit.Origin{}, content, elem...)
+}
+
+// WriteCgroupFileAs writes data to cgroup file on behalf of the service and the pod in origin
+func WriteCgroupFileAs(origin audit.Origin, content string, elem ...string) error {
+	key, path, err := splitCgroupElem(elem)
+	if err != nil {
+		return err
+	}
+	if IsUnified() {
+		err = writeUnifiedFile(origin, conf.RootDir, path, key, content)
+	} else {
+		err = writeCgroupFile(origin, AbsoluteCgroupPath(elem...), content)
+	}
+	if err != nil {
+		return err
+	}
+	if !audit.DryRun() {
+		recordWrite(origin.Service, path, key, content)
+	}
+	return nil
+}
+
+// splitCgroupElem splits the path elements in the format of subsystem, cgroup path and file name
+func splitCgroupElem(elem []string) (*Key, string, error) {
+	const minElemNum = 2
+	if len(elem) < minElemNum {
+		return nil, "", fmt.Errorf("invalid cgroup file: %v", elem)
+	}
+	key := &Key{SubSys: elem[0], FileName: elem[len(elem)-1]}
+	return key, filepath.Join(elem[1 : len(elem)-1]...), nil
 }
 
 func readCgroupFile(cgPath string) ([]byte, error) {
@@ -88,11 +158,11 @@ func readCgroupFile(cgPath string) ([]byte, error) {
 	return util.ReadFile(cgPath)
 }
 
-func writeCgroupFile(cgPath, content string) error {
+func writeCgroupFile(origin audit.Origin, cgPath, content string) error {
 	if !util.PathExist(cgPath) {
 		return fmt.Errorf("%v: no such file or directory", cgPath)
 	}
-	return util.WriteFile(cgPath, content)
+	return audit.WriteFile(origin, cgPath, content)
 }
 
 // GetMountDir returns the mount point path of the cgroup
@@ -197,6 +267,10 @@ func (attr *Attr) PSI() (*Pressure, error) {
 type Hierarchy struct {
 	MountPoint string `json:"mountPoint,omitempty"`
 	Path       string `json:"cgroupPath"`
+	// Owner is the service writing the cgroup files through the hierarchy
+	Owner string `json:"-"`
+	// PodUID is the pod which the cgroup belongs to, it is recorded with the writes in the audit journal
+	PodUID string `json:"-"`
 }
 
 // NewHierarchy creates a Hierarchy instance
@@ -212,11 +286,21 @@ func (h *Hierarchy) SetCgroupAttr(key *Key, value string) error {
 	if err := validateCgroupKey(key); err != nil {
 		return err
 	}
-	var mountPoint = conf.RootDir
-	if len(h.MountPoint) > 0 {
-		mountPoint = h.MountPoint
+	var err error
+	if IsUnified() {
+		err = writeUnifiedFile(h.origin(), h.mountPoint(), h.Path, key, value)
+	} else {
+		err = writeCgroupFile(h.origin(), filepath.Join(h.mountPoint(), key.SubSys, h.Path, key.FileName), value)
+	}
+	// the values are not written in dry-run mode
+	if err == nil && !audit.DryRun() {
+		recordWrite(h.Owner, h.Path, key, value)
 	}
-	return writeCgroupFile(filepath.Join(mountPoint, key.SubSys, h.Path, key.FileName), value)
+	return err
+}
+
+func (h *Hierarchy) origin() audit.Origin {
+	return audit.Origin{Service: h.Owner, PodUID: h.PodUID}
 }
 
 // GetCgroupAttr gets cgroup file content
@@ -224,17 +308,33 @@ func (h *Hierarchy) GetCgroupAttr(key *Key) *Attr {
 	if err := validateCgroupKey(key); err != nil {
 		return &Attr{Err: err}
 	}
-	var mountPoint = conf.RootDir
-	if len(h.MountPoint) > 0 {
-		mountPoint = h.MountPoint
+	var (
+		data []byte
+		err  error
+	)
+	if IsUnified() {
+		data, err = readUnifiedFile(h.mountPoint(), h.Path, key)
+	} else {
+		data, err = readCgroupFile(filepath.Join(h.mountPoint(), key.SubSys, h.Path, key.FileName))
 	}
-	data, err := readCgroupFile(filepath.Join(mountPoint, key.SubSys, h.Path, key.FileName))
 	if err != nil {
 		return &Attr{Err: err}
 	}
 	return &Attr{Value: strings.TrimSpace(string(data)), Err: nil}
 }
 
+// AbsolutePath returns the absolute path of the hierarchy in the subsystem
+func (h *Hierarchy) AbsolutePath(subsys string) string {
+	return absolutePath(h.mountPoint(), subsys, h.Path)
+}
+
+func (h *Hierarchy) mountPoint() string {
+	if len(h.MountPoint) > 0 {
+		return h.MountPoint
+	}
+	return conf.RootDir
+}
+
 // validateCgroupKey is used to verify the validity of the cgroup key
 func validateCgroupKey(key *Key) error {
 	if key == nil {
@@ -245,3 +345,16 @@ func validateCgroupKey(key *Key) error {
 	}
 	return nil
 }
+
+// CheckKubepodsFile checks whether the cgroup file of the key exists in the kubepods cgroup,
+// which tells whether the kernel supports the file
+func CheckKubepodsFile(key *Key) error {
+	if err := validateCgroupKey(key); err != nil {
+		return err
+	}
+	path := AbsoluteCgroupPath(key.SubSys, KubepodsCgroupPath(), key.FileName)
+	if !util.PathExist(path) {
+		return fmt.Errorf("cgroup file %v does not exist", path)
+	}
+	return nil
+}
@@ -15,6 +15,7 @@ package cgroup
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"isula.org/rubik/pkg/core/typedef/cgroup/cgroupfs"
 	"isula.org/rubik/pkg/core/typedef/cgroup/systemd"
@@ -62,3 +63,8 @@ func GetNRIContainerCgroupPath(nriCgroupPath string) string {
 func ConcatContainerCgroup(podCgroupPath, prefix, containerID string) string {
 	return conf.CgroupDriver.ConcatContainerCgroup(podCgroupPath, prefix, containerID)
 }
+
+// KubepodsCgroupPath returns the cgroup path of the parent of all pods
+func KubepodsCgroupPath() string {
+	return filepath.Dir(ConcatPodCgroupPath("", ""))
+}
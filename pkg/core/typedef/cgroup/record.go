@@ -0,0 +1,96 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file records the latest values written to the cgroup files
+
+package cgroup
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WriteRecord is the latest value written to a cgroup file
+type WriteRecord struct {
+	Time time.Time `json:"time"`
+	// Owner is the service writing the value, which is empty if unknown
+	Owner  string `json:"service,omitempty"`
+	Path   string `json:"cgroupPath"`
+	SubSys string `json:"subsys"`
+	File   string `json:"file"`
+	Value  string `json:"value"`
+}
+
+// writeRecords holds the latest write of each cgroup file, indexed by the cgroup path and the file
+var writeRecords = struct {
+	sync.RWMutex
+	records map[string]map[Key]*WriteRecord
+}{records: make(map[string]map[Key]*WriteRecord)}
+
+// recordWrite records the value written to the cgroup file successfully
+func recordWrite(owner, path string, key *Key, value string) {
+	path = filepath.Clean(path)
+	writeRecords.Lock()
+	defer writeRecords.Unlock()
+	files, ok := writeRecords.records[path]
+	if !ok {
+		files = make(map[Key]*WriteRecord)
+		writeRecords.records[path] = files
+	}
+	files[*key] = &WriteRecord{
+		Time:   time.Now(),
+		Owner:  owner,
+		Path:   path,
+		SubSys: key.SubSys,
+		File:   key.FileName,
+		Value:  value,
+	}
+}
+
+// isUnder returns true if the cgroup path is the parent path or its descendant
+func isUnder(path, parent string) bool {
+	return path == parent || strings.HasPrefix(path, parent+"/")
+}
+
+// RecentWrites returns the latest writes to the cgroup path and its descendants sorted by time
+func RecentWrites(path string) []WriteRecord {
+	path = filepath.Clean(path)
+	var res = make([]WriteRecord, 0)
+	writeRecords.RLock()
+	for p, files := range writeRecords.records {
+		if !isUnder(p, path) {
+			continue
+		}
+		for _, r := range files {
+			res = append(res, *r)
+		}
+	}
+	writeRecords.RUnlock()
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Time.Before(res[j].Time)
+	})
+	return res
+}
+
+// ForgetWrites drops the records of the cgroup path and its descendants, which is used when the cgroup is removed
+func ForgetWrites(path string) {
+	path = filepath.Clean(path)
+	writeRecords.Lock()
+	for p := range writeRecords.records {
+		if isUnder(p, path) {
+			delete(writeRecords.records, p)
+		}
+	}
+	writeRecords.Unlock()
+}
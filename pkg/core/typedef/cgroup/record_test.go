@@ -0,0 +1,62 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file tests the records of cgroup writes
+
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/common/util"
+)
+
+// TestRecentWrites tests recording the writes and ForgetWrites
+func TestRecentWrites(t *testing.T) {
+	const (
+		podPath       = "kubepods/podrecord"
+		containerPath = podPath + "/container"
+		otherPath     = podPath + "2"
+	)
+	defer os.RemoveAll(constant.TmpTestDir)
+	defer ForgetWrites("kubepods")
+	key := &Key{SubSys: "cpu", FileName: constant.CPUCgroupFileName}
+	for _, p := range []string{podPath, containerPath, otherPath} {
+		assert.NoError(t, util.WriteFile(filepath.Join(constant.TmpTestDir, "cpu", p, key.FileName), "0"))
+	}
+
+	pod := &Hierarchy{MountPoint: constant.TmpTestDir, Path: podPath, Owner: "preemption"}
+	assert.NoError(t, pod.SetCgroupAttr(key, "-1"))
+	assert.NoError(t, pod.SetCgroupAttr(key, "1"))
+	assert.Error(t, pod.SetCgroupAttr(&Key{SubSys: "cpu", FileName: "missing"}, "1"))
+	container := &Hierarchy{MountPoint: constant.TmpTestDir, Path: containerPath + "/"}
+	assert.NoError(t, container.SetCgroupAttr(key, "-1"))
+	other := &Hierarchy{MountPoint: constant.TmpTestDir, Path: otherPath}
+	assert.NoError(t, other.SetCgroupAttr(key, "-1"))
+
+	writes := RecentWrites(podPath)
+	assert.Len(t, writes, 2)
+	// only the latest value of each file is kept
+	assert.Equal(t, "preemption", writes[0].Owner)
+	assert.Equal(t, podPath, writes[0].Path)
+	assert.Equal(t, "1", writes[0].Value)
+	assert.Equal(t, "", writes[1].Owner)
+	assert.Equal(t, containerPath, writes[1].Path)
+
+	ForgetWrites(podPath)
+	assert.Empty(t, RecentWrites(podPath))
+	assert.Len(t, RecentWrites(otherPath), 1)
+}
@@ -0,0 +1,311 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file maps cgroup v1 files to the cgroup v2 unified hierarchy
+
+package cgroup
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"isula.org/rubik/pkg/common/audit"
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/common/util"
+)
+
+const (
+	// unlimited is the value of an unlimited resource in cgroup v2
+	unlimited = "max"
+	// unlimitedV1 is the value of an unlimited cpu quota in cgroup v1
+	unlimitedV1 = "-1"
+	// nsPerUs is the number of nanoseconds per microsecond
+	nsPerUs = 1000
+	// ioMax is the unified file of the blkio throttle files
+	ioMax = "io.max"
+)
+
+func init() {
+	// io.max is written device by device, so the devices absent in the original value are reset
+	// before restoring the original value
+	audit.RegisterRestorer(ioMax, restoreIOMax)
+}
+
+// unifiedFile describes how a cgroup v1 file is represented in the unified hierarchy.
+// Services always use the cgroup v1 file names and value formats, the conversion is done here.
+type unifiedFile struct {
+	// name is the file name in the unified hierarchy
+	name string
+	// parse converts the content of the unified file to the cgroup v1 format
+	parse func(data string) (string, error)
+	// format converts the cgroup v1 value to the contents written to the unified file one by one,
+	// read returns the current content of the unified file
+	format func(value string, read func() (string, error)) ([]string, error)
+}
+
+// unifiedFiles maps the cgroup v1 file name to the unified file,
+// the file whose name is not in the map keeps its name and value format in cgroup v2
+var unifiedFiles = map[string]*unifiedFile{
+	"cpu.cfs_quota_us":                 {name: "cpu.max", parse: parseCPUMaxField(0), format: formatCPUMaxQuota},
+	"cpu.cfs_period_us":                {name: "cpu.max", parse: parseCPUMaxField(1), format: formatCPUMaxPeriod},
+	"cpu.cfs_burst_us":                 {name: "cpu.max.burst"},
+	"cpuacct.usage":                    {name: "cpu.stat", parse: parseCPUUsage},
+	"cpu.stat":                         {name: "cpu.stat", parse: parseCPUStat},
+	"blkio.throttle.read_bps_device":   ioMaxFile("rbps"),
+	"blkio.throttle.write_bps_device":  ioMaxFile("wbps"),
+	"blkio.throttle.read_iops_device":  ioMaxFile("riops"),
+	"blkio.throttle.write_iops_device": ioMaxFile("wiops"),
+	"blkio.cost.weight":                {name: "io.weight", parse: parseIOWeight},
+	"blkio.cost.qos":                   {name: "io.cost.qos"},
+	"blkio.cost.model":                 {name: "io.cost.model"},
+}
+
+// unsupportedFiles are the cgroup v1 files which have no counterpart in the unified hierarchy
+var unsupportedFiles = map[string]struct{}{
+	constant.NetCgroupFileName: {},
+	"memory.wb_blkio_ino":      {},
+}
+
+// IsUnified returns true if the cgroup v2 unified hierarchy is in use
+func IsUnified() bool {
+	return conf.Version == constant.CgroupVersionV2
+}
+
+// detectVersion determines the cgroup version by the file system type of the mount point
+func detectVersion(mountPoint string) (string, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(mountPoint, &st); err != nil {
+		return "", fmt.Errorf("failed to stat %v: %v", mountPoint, err)
+	}
+	if st.Type == unix.CGROUP2_SUPER_MAGIC {
+		return constant.CgroupVersionV2, nil
+	}
+	return constant.CgroupVersionV1, nil
+}
+
+// unifiedFileName returns the name of the file in the unified hierarchy
+func unifiedFileName(fileName string) string {
+	if f, ok := unifiedFiles[fileName]; ok {
+		return f.name
+	}
+	return fileName
+}
+
+// unifiedPath returns the absolute path of the key in the unified hierarchy, the subsystem is ignored
+func unifiedPath(mountPoint, path string, key *Key) (string, *unifiedFile, error) {
+	if _, ok := unsupportedFiles[key.FileName]; ok {
+		return "", nil, fmt.Errorf("%v is not supported in cgroup v2", key.FileName)
+	}
+	f, ok := unifiedFiles[key.FileName]
+	if !ok {
+		f = &unifiedFile{name: key.FileName}
+	}
+	return filepath.Join(mountPoint, path, f.name), f, nil
+}
+
+func readUnifiedFile(mountPoint, path string, key *Key) ([]byte, error) {
+	cgPath, f, err := unifiedPath(mountPoint, path, key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readCgroupFile(cgPath)
+	if err != nil || f.parse == nil {
+		return data, err
+	}
+	value, err := f.parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %v: %v", cgPath, err)
+	}
+	return []byte(value), nil
+}
+
+func writeUnifiedFile(origin audit.Origin, mountPoint, path string, key *Key, value string) error {
+	cgPath, f, err := unifiedPath(mountPoint, path, key)
+	if err != nil {
+		return err
+	}
+	if f.format == nil {
+		return writeCgroupFile(origin, cgPath, value)
+	}
+	contents, err := f.format(value, func() (string, error) {
+		data, err := readCgroupFile(cgPath)
+		return string(data), err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to convert %v for %v: %v", value, cgPath, err)
+	}
+	for _, content := range contents {
+		if err := writeCgroupFile(origin, cgPath, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseCPUMaxField returns the function getting the field of cpu.max which is in the format "$MAX $PERIOD"
+func parseCPUMaxField(index int) func(string) (string, error) {
+	return func(data string) (string, error) {
+		fields := strings.Fields(data)
+		const cpuMaxFieldNum = 2
+		if len(fields) != cpuMaxFieldNum {
+			return "", fmt.Errorf("invalid cpu.max: %v", data)
+		}
+		if fields[index] == unlimited {
+			return unlimitedV1, nil
+		}
+		return fields[index], nil
+	}
+}
+
+// formatCPUMaxQuota converts the cfs quota to cpu.max, the period keeps unchanged
+func formatCPUMaxQuota(value string, _ func() (string, error)) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if value == unlimitedV1 {
+		return []string{unlimited}, nil
+	}
+	if _, err := util.ParseInt64(value); err != nil {
+		return nil, err
+	}
+	return []string{value}, nil
+}
+
+// formatCPUMaxPeriod converts the cfs period to cpu.max, the quota keeps unchanged
+func formatCPUMaxPeriod(value string, read func() (string, error)) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if _, err := util.ParseInt64(value); err != nil {
+		return nil, err
+	}
+	data, err := read()
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(data)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("invalid cpu.max: %v", data)
+	}
+	return []string{fields[0] + " " + value}, nil
+}
+
+// parseCPUUsage gets the cpu usage in nanoseconds like cpuacct.usage from cpu.stat
+func parseCPUUsage(data string) (string, error) {
+	stat, err := util.ParseInt64Map(data)
+	if err != nil {
+		return "", err
+	}
+	usage, ok := stat["usage_usec"]
+	if !ok {
+		return "", fmt.Errorf("usage_usec not found")
+	}
+	return util.FormatInt64(usage * nsPerUs), nil
+}
+
+// parseCPUStat appends the throttled time in nanoseconds to cpu.stat as cgroup v1 does
+func parseCPUStat(data string) (string, error) {
+	stat, err := util.ParseInt64Map(data)
+	if err != nil {
+		return "", err
+	}
+	throttled, ok := stat["throttled_usec"]
+	if !ok {
+		return data, nil
+	}
+	return strings.TrimRight(data, "\n") + "\nthrottled_time " + util.FormatInt64(throttled*nsPerUs), nil
+}
+
+// ioMaxFile returns the io.max file whose field named by the key maps to a blkio.throttle file
+func ioMaxFile(field string) *unifiedFile {
+	return &unifiedFile{
+		name: ioMax,
+		// io.max is in the format "MAJ:MIN rbps=max wbps=max riops=max wiops=max"
+		// while the throttle file of cgroup v1 is in the format "MAJ:MIN VALUE"
+		parse: func(data string) (string, error) {
+			var lines []string
+			prefix := field + "="
+			for _, line := range strings.Split(data, "\n") {
+				fields := strings.Fields(line)
+				if len(fields) == 0 {
+					continue
+				}
+				for _, kv := range fields[1:] {
+					if !strings.HasPrefix(kv, prefix) {
+						continue
+					}
+					if value := strings.TrimPrefix(kv, prefix); value != unlimited {
+						lines = append(lines, fields[0]+" "+value)
+					}
+				}
+			}
+			return strings.Join(lines, "\n"), nil
+		},
+		// the kernel only accepts one device per write and 0 means no limit in cgroup v1
+		format: func(value string, _ func() (string, error)) ([]string, error) {
+			var contents []string
+			for _, line := range strings.Split(value, "\n") {
+				fields := strings.Fields(line)
+				if len(fields) == 0 {
+					continue
+				}
+				const throttleFieldNum = 2
+				if len(fields) != throttleFieldNum {
+					return nil, fmt.Errorf("invalid throttle config: %v", line)
+				}
+				limit := fields[1]
+				if limit == "0" {
+					limit = unlimited
+				}
+				contents = append(contents, fmt.Sprintf("%s %s=%s", fields[0], field, limit))
+			}
+			return contents, nil
+		},
+	}
+}
+
+// parseIOWeight gets the default weight from io.weight which is in the format "default $WEIGHT"
+func parseIOWeight(data string) (string, error) {
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		const weightFieldNum = 2
+		if len(fields) == weightFieldNum && fields[0] == "default" {
+			return fields[1], nil
+		}
+	}
+	return "", fmt.Errorf("default weight not found")
+}
+
+// restoreIOMax returns the values restoring io.max, the devices throttled currently but not originally
+// are reset to unlimited, and the devices throttled originally are restored one by one
+func restoreIOMax(original, current string) []string {
+	var (
+		values    []string
+		resets    []string
+		throttled = make(map[string]struct{})
+	)
+	for _, line := range strings.Split(original, "\n") {
+		if fields := strings.Fields(line); len(fields) != 0 {
+			throttled[fields[0]] = struct{}{}
+			values = append(values, strings.Join(fields, " "))
+		}
+	}
+	for _, line := range strings.Split(current, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if _, ok := throttled[fields[0]]; !ok {
+			resets = append(resets, fmt.Sprintf("%s rbps=%s wbps=%s riops=%s wiops=%s",
+				fields[0], unlimited, unlimited, unlimited, unlimited))
+		}
+	}
+	return append(resets, values...)
+}
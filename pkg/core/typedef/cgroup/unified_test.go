@@ -0,0 +1,192 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file tests the cgroup v2 unified hierarchy
+
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"isula.org/rubik/pkg/common/audit"
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/common/util"
+)
+
+const testPodPath = "kubepods/podXXX"
+
+func initUnifiedTestDir(t *testing.T, files map[string]string) {
+	assert.NoError(t, os.RemoveAll(constant.TmpTestDir))
+	for name, content := range files {
+		assert.NoError(t, util.WriteFile(filepath.Join(constant.TmpTestDir, testPodPath, name), content))
+	}
+	assert.NoError(t, Init(WithRoot(constant.TmpTestDir), WithVersion(constant.CgroupVersionV2)))
+}
+
+func resetUnifiedTestDir() {
+	os.RemoveAll(constant.TmpTestDir)
+	Init(WithRoot(constant.DefaultCgroupRoot), WithVersion(constant.CgroupVersionV1))
+}
+
+// TestWithVersion tests WithVersion and the detection of cgroup version
+func TestWithVersion(t *testing.T) {
+	defer resetUnifiedTestDir()
+	assert.NoError(t, os.MkdirAll(constant.TmpTestDir, constant.DefaultDirMode))
+
+	assert.Error(t, Init(WithVersion("v3")))
+	assert.NoError(t, Init(WithRoot(constant.TmpTestDir), WithVersion(constant.CgroupVersionAuto)))
+	assert.False(t, IsUnified())
+	assert.NoError(t, Init(WithVersion(constant.CgroupVersionV2)))
+	assert.True(t, IsUnified())
+	assert.Error(t, Init(WithRoot(filepath.Join(constant.TmpTestDir, "missing")),
+		WithVersion(constant.CgroupVersionAuto)))
+}
+
+// TestUnifiedGetCgroupAttr tests GetCgroupAttr in cgroup v2
+func TestUnifiedGetCgroupAttr(t *testing.T) {
+	defer resetUnifiedTestDir()
+	initUnifiedTestDir(t, map[string]string{
+		"cpu.max":         "max 100000\n",
+		"cpu.max.burst":   "0\n",
+		"cpu.stat":        "usage_usec 10\nnr_periods 3\nnr_throttled 2\nthrottled_usec 5\n",
+		"cpu.pressure":    "some avg10=1.00 avg60=2.00 avg300=3.00 total=4\n",
+		"io.max":          "8:0 rbps=1024 wbps=max riops=max wiops=max\n8:16 rbps=max wbps=max riops=10 wiops=max\n",
+		"io.weight":       "default 100\n",
+		"memory.high":     "max\n",
+		"cpu.qos_level":   "-1\n",
+		"net_cls.classid": "0\n",
+	})
+	h := NewHierarchy("", testPodPath)
+	tests := []struct {
+		name    string
+		key     *Key
+		want    string
+		wantErr bool
+	}{
+		{name: "TC1-unlimited quota", key: &Key{"cpu", "cpu.cfs_quota_us"}, want: "-1"},
+		{name: "TC2-period", key: &Key{"cpu", "cpu.cfs_period_us"}, want: "100000"},
+		{name: "TC3-burst", key: &Key{"cpu", "cpu.cfs_burst_us"}, want: "0"},
+		{name: "TC4-cpu usage in nanoseconds", key: &Key{"cpuacct", "cpuacct.usage"}, want: "10000"},
+		{name: "TC5-psi of cpuacct", key: &Key{"cpuacct", "cpu.pressure"},
+			want: "some avg10=1.00 avg60=2.00 avg300=3.00 total=4"},
+		{name: "TC6-read bps", key: &Key{"blkio", "blkio.throttle.read_bps_device"}, want: "8:0 1024"},
+		{name: "TC7-write bps", key: &Key{"blkio", "blkio.throttle.write_bps_device"}, want: ""},
+		{name: "TC8-read iops", key: &Key{"blkio", "blkio.throttle.read_iops_device"}, want: "8:16 10"},
+		{name: "TC9-iocost weight", key: &Key{"blkio", "blkio.cost.weight"}, want: "100"},
+		{name: "TC10-same file name", key: &Key{"memory", "memory.high"}, want: "max"},
+		{name: "TC11-qos level", key: &Key{"cpu", constant.CPUCgroupFileName}, want: "-1"},
+		{name: "TC12-unsupported file", key: &Key{"net_cls", constant.NetCgroupFileName}, wantErr: true},
+		{name: "TC13-missing file", key: &Key{"memory", "memory.qos_level"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attr := h.GetCgroupAttr(tt.key)
+			if tt.wantErr {
+				assert.Error(t, attr.Err)
+				return
+			}
+			assert.NoError(t, attr.Err)
+			assert.Equal(t, tt.want, attr.Value)
+		})
+	}
+
+	stat, err := h.GetCgroupAttr(&Key{"cpu", "cpu.stat"}).CPUStat()
+	assert.NoError(t, err)
+	assert.Equal(t, &CPUStat{NrPeriods: 3, NrThrottled: 2, ThrottledTime: 5000}, stat)
+}
+
+// TestUnifiedSetCgroupAttr tests SetCgroupAttr and WriteCgroupFile in cgroup v2
+func TestUnifiedSetCgroupAttr(t *testing.T) {
+	defer resetUnifiedTestDir()
+	readFile := func(name string) string {
+		data, err := util.ReadFile(filepath.Join(constant.TmpTestDir, testPodPath, name))
+		assert.NoError(t, err)
+		return string(data)
+	}
+	h := NewHierarchy("", testPodPath)
+	tests := []struct {
+		name    string
+		key     *Key
+		value   string
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{name: "TC1-unlimited quota", key: &Key{"cpu", "cpu.cfs_quota_us"}, value: "-1", file: "cpu.max", want: "max"},
+		{name: "TC2-quota", key: &Key{"cpu", "cpu.cfs_quota_us"}, value: "50000", file: "cpu.max", want: "50000"},
+		{name: "TC3-invalid quota", key: &Key{"cpu", "cpu.cfs_quota_us"}, value: "a", wantErr: true},
+		{name: "TC4-period keeps quota", key: &Key{"cpu", "cpu.cfs_period_us"}, value: "200000",
+			file: "cpu.max", want: "max 200000"},
+		{name: "TC5-reset write bps", key: &Key{"blkio", "blkio.throttle.write_bps_device"}, value: "8:0 0",
+			file: "io.max", want: "8:0 wbps=max"},
+		{name: "TC6-invalid write bps", key: &Key{"blkio", "blkio.throttle.write_bps_device"}, value: "8:0",
+			wantErr: true},
+		{name: "TC7-same file name", key: &Key{"cpu", constant.CPUCgroupFileName}, value: "-1",
+			file: constant.CPUCgroupFileName, want: "-1"},
+		{name: "TC8-unsupported file", key: &Key{"net_cls", constant.NetCgroupFileName}, value: "0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initUnifiedTestDir(t, map[string]string{
+				"cpu.max":                  "max 100000\n",
+				"io.max":                   "",
+				constant.CPUCgroupFileName: "0",
+			})
+			err := h.SetCgroupAttr(tt.key, tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, readFile(tt.file))
+		})
+	}
+
+	initUnifiedTestDir(t, map[string]string{"io.max": ""})
+	assert.NoError(t, WriteCgroupFile("8:0 1024\n8:16 2048", "blkio", testPodPath, "blkio.throttle.read_iops_device"))
+	// every device is written separately, the fake file only keeps the last one
+	assert.Equal(t, "8:16 riops=2048", readFile("io.max"))
+	data, err := ReadCgroupFile("blkio", testPodPath, "blkio.throttle.read_iops_device")
+	assert.NoError(t, err)
+	assert.Equal(t, "8:16 2048", string(data))
+	assert.Error(t, WriteCgroupFile("0", "io.max"))
+	assert.Equal(t, filepath.Join(constant.TmpTestDir, testPodPath, "cpu.max"),
+		AbsoluteCgroupPath("cpu", testPodPath, "cpu.cfs_quota_us"))
+	assert.Equal(t, filepath.Join(constant.TmpTestDir, testPodPath), h.AbsolutePath("perf_event"))
+}
+
+// TestUnifiedRevert tests the blkio throttles written to io.max are reverted to the original values
+func TestUnifiedRevert(t *testing.T) {
+	defer resetUnifiedTestDir()
+	const service = "ioLimit"
+	initUnifiedTestDir(t, map[string]string{ioMax: ""})
+	readFile := func(name string) string {
+		data, err := util.ReadFile(filepath.Join(constant.TmpTestDir, testPodPath, name))
+		assert.NoError(t, err)
+		return string(data)
+	}
+	origin := audit.Origin{Service: service, PodUID: "podXXX"}
+	assert.NoError(t, WriteCgroupFileAs(origin, "8:16 1024", "blkio", testPodPath, "blkio.throttle.read_bps_device"))
+	assert.Equal(t, "8:16 rbps=1024", readFile(ioMax))
+	assert.NoError(t, audit.Revert(service))
+	assert.Equal(t, "8:16 rbps=max wbps=max riops=max wiops=max", readFile(ioMax))
+
+	assert.Equal(t, []string{
+		"8:16 rbps=max wbps=max riops=max wiops=max",
+		"8:0 rbps=1024 wbps=max riops=max wiops=max",
+	}, restoreIOMax("8:0 rbps=1024 wbps=max riops=max wiops=max\n",
+		"8:0 rbps=2048 wbps=max riops=max wiops=max\n8:16 rbps=max wbps=10 riops=max wiops=max\n"))
+	assert.Empty(t, restoreIOMax("", ""))
+}
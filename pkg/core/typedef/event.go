@@ -17,8 +17,12 @@ package typedef
 type (
 	// EventType is the type of event published by generic publisher
 	EventType int8
-	// Event is the event published by generic publisher
-	Event interface{}
+	// Event is the event published by generic publisher, each event type has its own payload struct
+	// so that the publisher and the subscribers agree on the payload at compile time
+	Event interface {
+		// Type returns the type of the event, which is the topic the event is published to
+		Type() EventType
+	}
 )
 
 const (
@@ -48,6 +52,8 @@ const (
 	NRIPODSYNCALL
 	// NRICONTAINERSYNCALL means sync all Containers event
 	NRICONTAINERSYNCALL
+	// RECONCILE means the service reconciles the settings of the pods event
+	RECONCILE
 )
 
 const undefinedType = "undefined"
@@ -66,6 +72,7 @@ var eventTypeToString = map[EventType]string{
 	NRICONTAINERREMOVE:  "removenricontainer",
 	NRIPODSYNCALL:       "syncallnrirawpods",
 	NRICONTAINERSYNCALL: "syncallnrirawcontainers",
+	RECONCILE:           "reconcile",
 }
 
 // String returns the string of the current event type
@@ -75,3 +82,103 @@ func (t EventType) String() string {
 	}
 	return undefinedType
 }
+
+type (
+	// RawPodAddEvent is published by the apiserver informer when a pod is added
+	RawPodAddEvent struct {
+		Pod *RawPod
+	}
+	// RawPodUpdateEvent is published by the apiserver informer when a pod is updated
+	RawPodUpdateEvent struct {
+		Old, New *RawPod
+	}
+	// RawPodDeleteEvent is published by the apiserver informer when a pod is deleted
+	RawPodDeleteEvent struct {
+		Pod *RawPod
+	}
+	// RawPodSyncAllEvent is published by the apiserver informer with all pods on the node
+	RawPodSyncAllEvent struct {
+		Pods []*RawPod
+	}
+	// PodAddEvent is published by the pod manager when the information of a pod is added
+	PodAddEvent struct {
+		Pod *PodInfo
+	}
+	// PodUpdateEvent is published by the pod manager when the information of a pod is updated
+	PodUpdateEvent struct {
+		Old, New *PodInfo
+	}
+	// PodDeleteEvent is published by the pod manager when the information of a pod is deleted
+	PodDeleteEvent struct {
+		Pod *PodInfo
+	}
+	// NRIPodAddEvent is published by the nri informer when a pod sandbox starts
+	NRIPodAddEvent struct {
+		Pod *NRIRawPod
+	}
+	// NRIPodDeleteEvent is published by the nri informer when a pod sandbox is removed
+	NRIPodDeleteEvent struct {
+		Pod *NRIRawPod
+	}
+	// NRIContainerStartEvent is published by the nri informer when a container starts
+	NRIContainerStartEvent struct {
+		Container *NRIRawContainer
+	}
+	// NRIContainerRemoveEvent is published by the nri informer when a container stops or is removed
+	NRIContainerRemoveEvent struct {
+		Container *NRIRawContainer
+	}
+	// NRIPodSyncAllEvent is published by the nri informer with all pod sandboxes
+	NRIPodSyncAllEvent struct {
+		Pods []*NRIRawPod
+	}
+	// NRIContainerSyncAllEvent is published by the nri informer with all containers
+	NRIContainerSyncAllEvent struct {
+		Containers []*NRIRawContainer
+	}
+	// ReconcileEvent is queued by the service manager to the service, so that the settings of the pods
+	// are reconciled in order with the pod events handled by the service
+	ReconcileEvent struct{}
+)
+
+// Type returns RAWPODADD
+func (RawPodAddEvent) Type() EventType { return RAWPODADD }
+
+// Type returns RAWPODUPDATE
+func (RawPodUpdateEvent) Type() EventType { return RAWPODUPDATE }
+
+// Type returns RAWPODDELETE
+func (RawPodDeleteEvent) Type() EventType { return RAWPODDELETE }
+
+// Type returns RAWPODSYNCALL
+func (RawPodSyncAllEvent) Type() EventType { return RAWPODSYNCALL }
+
+// Type returns INFOADD
+func (PodAddEvent) Type() EventType { return INFOADD }
+
+// Type returns INFOUPDATE
+func (PodUpdateEvent) Type() EventType { return INFOUPDATE }
+
+// Type returns INFODELETE
+func (PodDeleteEvent) Type() EventType { return INFODELETE }
+
+// Type returns NRIPODADD
+func (NRIPodAddEvent) Type() EventType { return NRIPODADD }
+
+// Type returns NRIPODDELETE
+func (NRIPodDeleteEvent) Type() EventType { return NRIPODDELETE }
+
+// Type returns NRICONTAINERSTART
+func (NRIContainerStartEvent) Type() EventType { return NRICONTAINERSTART }
+
+// Type returns NRICONTAINERREMOVE
+func (NRIContainerRemoveEvent) Type() EventType { return NRICONTAINERREMOVE }
+
+// Type returns NRIPODSYNCALL
+func (NRIPodSyncAllEvent) Type() EventType { return NRIPODSYNCALL }
+
+// Type returns NRICONTAINERSYNCALL
+func (NRIContainerSyncAllEvent) Type() EventType { return NRICONTAINERSYNCALL }
+
+// Type returns RECONCILE
+func (ReconcileEvent) Type() EventType { return RECONCILE }
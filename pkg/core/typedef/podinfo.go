@@ -17,7 +17,6 @@ package typedef
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	"isula.org/rubik/pkg/common/constant"
 	"isula.org/rubik/pkg/core/typedef/cgroup"
 )
 
@@ -35,11 +34,20 @@ type PodInfo struct {
 	HostNetwork         bool                      `json:"hostNetwork,omitempty"`
 	nriContainerRequest map[string]ResourceMap
 	nriContainerLimit   map[string]ResourceMap
+	// PriorityClassName, QOSClass and the owner managing the pod are used to match the pod rules
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	QOSClass          string `json:"qosClass,omitempty"`
+	ControllerKind    string `json:"controllerKind,omitempty"`
+	ControllerName    string `json:"controllerName,omitempty"`
+	// Rule is the name of the pod rule matching the pod,
+	// RuleValues are the annotations set by the rule which are not set by the pod explicitly
+	Rule       string            `json:"rule,omitempty"`
+	RuleValues map[string]string `json:"ruleValues,omitempty"`
 }
 
 // NewPodInfo creates the PodInfo instance
 func NewPodInfo(pod *RawPod) *PodInfo {
-	return &PodInfo{
+	podInfo := &PodInfo{
 		Name:            pod.Name,
 		Namespace:       pod.Namespace,
 		UID:             pod.ID(),
@@ -50,6 +58,11 @@ func NewPodInfo(pod *RawPod) *PodInfo {
 		StartTime:       pod.Status.StartTime,
 		HostNetwork:     pod.Spec.HostNetwork,
 	}
+	podInfo.PriorityClassName, podInfo.QOSClass = pod.Spec.PriorityClassName, string(pod.Status.QOSClass)
+	if owner := metav1.GetControllerOfNoCopy(&pod.ObjectMeta); owner != nil {
+		podInfo.ControllerKind, podInfo.ControllerName = owner.Kind, owner.Name
+	}
+	return podInfo
 }
 
 // DeepCopy returns deepcopy object
@@ -84,6 +97,14 @@ func (pod *PodInfo) DeepCopy() *PodInfo {
 		copy.Labels = labelMap
 	}
 
+	if pod.RuleValues != nil {
+		values := make(map[string]string, len(pod.RuleValues))
+		for k, v := range pod.RuleValues {
+			values[k] = v
+		}
+		copy.RuleValues = values
+	}
+
 	if pod.nriContainerLimit != nil {
 		limits := make(map[string]ResourceMap)
 		for k, v := range pod.nriContainerLimit {
@@ -106,19 +127,7 @@ func (pod *PodInfo) DeepCopy() *PodInfo {
 
 // Offline is used to determine whether the pod is offline
 func (pod *PodInfo) Offline() bool {
-	var anno string
-	var label string
-
-	if pod.Annotations != nil {
-		anno = pod.Annotations[constant.PriorityAnnotationKey]
-	}
-
-	if pod.Labels != nil {
-		label = pod.Labels[constant.PriorityAnnotationKey]
-	}
-
-	// Annotations have a higher priority than labels
-	return anno == "true" || label == "true"
+	return pod.Priority().Offline()
 }
 
 // Online is used to determine whether the pod is online
@@ -133,3 +142,13 @@ func (pod *PodInfo) GetNriContainerRequest() map[string]ResourceMap {
 func (pod *PodInfo) GetNriContainerLimit() map[string]ResourceMap {
 	return pod.nriContainerLimit
 }
+
+// SetOwner sets the service writing the cgroup files of the pod and its containers
+func (pod *PodInfo) SetOwner(owner string) {
+	pod.Owner = owner
+	pod.PodUID = pod.UID
+	for _, cont := range pod.IDContainersMap {
+		cont.Owner = owner
+		cont.PodUID = pod.UID
+	}
+}
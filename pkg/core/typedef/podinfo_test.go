@@ -82,3 +82,57 @@ func TestPodInfo_DeepCopy(t *testing.T) {
 	assert.Equal(t, copyPod, oldNilMapPod)
 
 }
+
+// TestPodInfo_Priority tests resolving the priority tier of the pod
+func TestPodInfo_Priority(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		labels      map[string]string
+		want        Priority
+	}{
+		{name: "TC1-no priority", want: Online},
+		{
+			name:        "TC2-preemptable pod",
+			annotations: map[string]string{constant.PriorityAnnotationKey: "true"},
+			want:        Batch,
+		},
+		{
+			name:   "TC3-preemptable label",
+			labels: map[string]string{constant.PriorityAnnotationKey: "true"},
+			want:   Batch,
+		},
+		{
+			name: "TC4-tier takes precedence over preemptable",
+			annotations: map[string]string{
+				constant.PriorityAnnotationKey:     "true",
+				constant.PriorityTierAnnotationKey: "best-effort",
+			},
+			want: BestEffort,
+		},
+		{
+			name:        "TC5-annotation takes precedence over label",
+			annotations: map[string]string{constant.PriorityTierAnnotationKey: "latency-critical"},
+			labels:      map[string]string{constant.PriorityTierAnnotationKey: "batch"},
+			want:        LatencyCritical,
+		},
+		{
+			name:        "TC6-invalid tier",
+			annotations: map[string]string{constant.PriorityTierAnnotationKey: "offline"},
+			labels:      map[string]string{constant.PriorityTierAnnotationKey: "burstable-online"},
+			want:        BurstableOnline,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &PodInfo{Annotations: tt.annotations, Labels: tt.labels}
+			assert.Equal(t, tt.want, pod.Priority())
+			assert.Equal(t, tt.want.Offline(), pod.Offline())
+		})
+	}
+	for _, p := range Priorities() {
+		parsed, err := ParsePriority(p.String())
+		assert.NoError(t, err)
+		assert.Equal(t, p, parsed)
+	}
+}
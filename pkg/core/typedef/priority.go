@@ -0,0 +1,91 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file defines the priority tiers of the pods
+
+package typedef
+
+import (
+	"fmt"
+
+	"isula.org/rubik/pkg/common/constant"
+)
+
+// Priority is the tier of the pod, the larger the value, the lower the priority
+type Priority int8
+
+const (
+	// LatencyCritical is the tier of the pods most sensitive to the interference
+	LatencyCritical Priority = iota
+	// Online is the tier of the online pods, which is the default tier
+	Online
+	// BurstableOnline is the tier of the online pods which tolerate some interference
+	BurstableOnline
+	// Batch is the tier of the offline pods, such as the pods marked preemptable
+	Batch
+	// BestEffort is the tier of the pods using the idle resources only
+	BestEffort
+)
+
+var priorityNames = []string{
+	LatencyCritical: "latency-critical",
+	Online:          "online",
+	BurstableOnline: "burstable-online",
+	Batch:           "batch",
+	BestEffort:      "best-effort",
+}
+
+// Priorities returns all priority tiers from the highest to the lowest
+func Priorities() []Priority {
+	var priorities = make([]Priority, 0, len(priorityNames))
+	for p := range priorityNames {
+		priorities = append(priorities, Priority(p))
+	}
+	return priorities
+}
+
+// String returns the name of the priority
+func (p Priority) String() string {
+	if p < 0 || int(p) >= len(priorityNames) {
+		return fmt.Sprintf("priority(%d)", p)
+	}
+	return priorityNames[p]
+}
+
+// Offline returns true if the pods of the priority are offline
+func (p Priority) Offline() bool {
+	return p >= Batch
+}
+
+// ParsePriority returns the priority named by the name
+func ParsePriority(name string) (Priority, error) {
+	for p, n := range priorityNames {
+		if n == name {
+			return Priority(p), nil
+		}
+	}
+	return Online, fmt.Errorf("invalid priority %q, valid priorities are %v", name, priorityNames)
+}
+
+// Priority resolves the priority tier of the pod.
+// The tier is given by PriorityTierAnnotationKey in the annotations or labels, annotations have a higher priority.
+// The pods without a valid tier are Batch if they are marked by PriorityAnnotationKey, otherwise Online.
+func (pod *PodInfo) Priority() Priority {
+	for _, m := range []map[string]string{pod.Annotations, pod.Labels} {
+		if p, err := ParsePriority(m[constant.PriorityTierAnnotationKey]); err == nil {
+			return p
+		}
+	}
+	if pod.Annotations[constant.PriorityAnnotationKey] == "true" || pod.Labels[constant.PriorityAnnotationKey] == "true" {
+		return Batch
+	}
+	return Online
+}
@@ -18,8 +18,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/informers"
@@ -32,11 +34,21 @@ import (
 	"isula.org/rubik/pkg/lib/kubernetes"
 )
 
+const (
+	// the watch is considered dead if it fails maxWatchFailures times in watchFailureWindow
+	maxWatchFailures   = 5
+	watchFailureWindow = 5 * time.Minute
+)
+
 // APIServerInformer interacts with k8s api server and forward data to the internal
 type APIServerInformer struct {
 	api.Publisher
 	client   *kubernetes.Client
 	nodeName string
+	// watchFailures is the time of the recent failures of the watch, and watchErr is the last failure
+	watchFailures []time.Time
+	watchErr      error
+	failureLock   sync.Mutex
 }
 
 // NewAPIServerInformer creates an PIServerInformer instance
@@ -67,47 +79,127 @@ func (informer *APIServerInformer) Start(ctx context.Context) error {
 	const specNodeNameField = "spec.nodeName"
 	// set options to return only pods on the current node.
 	var fieldSelector = fields.OneTermEqualSelector(specNodeNameField, informer.nodeName).String()
-	informer.listFunc(fieldSelector)
-	informer.watchFunc(ctx, fieldSelector)
+	listed := informer.listFunc(fieldSelector)
+	informer.watchFunc(ctx, fieldSelector, listed)
 	return nil
 }
 
-func (informer *APIServerInformer) listFunc(fieldSelector string) {
+func (informer *APIServerInformer) listFunc(fieldSelector string) bool {
 	pods, err := informer.client.CoreV1().Pods("").List(context.Background(),
 		metav1.ListOptions{FieldSelector: fieldSelector})
 	if err != nil {
 		log.Errorf("failed to get pod list from APIServer informer: %v", err)
-		return
+		return false
 	}
-	informer.Publish(typedef.RAWPODSYNCALL, pods.Items)
+	informer.Publish(typedef.RawPodSyncAllEvent{Pods: toRawPods(pods.Items)})
+	return true
 }
 
-func (informer *APIServerInformer) watchFunc(ctx context.Context, fieldSelector string) {
+func (informer *APIServerInformer) watchFunc(ctx context.Context, fieldSelector string, listed bool) {
 	const reSyncTime = 30
 	kubeInformerFactory := informers.NewSharedInformerFactoryWithOptions(informer.client,
 		time.Duration(reSyncTime)*time.Second,
 		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
 			options.FieldSelector = fieldSelector
 		}))
-	kubeInformerFactory.Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+	podInformer := kubeInformerFactory.Core().V1().Pods().Informer()
+	podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc:    informer.AddFunc,
 		UpdateFunc: informer.UpdateFunc,
 		DeleteFunc: informer.DeleteFunc,
 	})
+	if err := podInformer.SetWatchErrorHandler(informer.watchErrorHandler); err != nil {
+		log.Warnf("failed to set watch error handler: %v", err)
+	}
 	kubeInformerFactory.Start(ctx.Done())
+	if listed {
+		return
+	}
+	// the full pods are published after the watch synchronizes if they failed to be listed
+	go func() {
+		if !cache.WaitForCacheSync(ctx.Done(), podInformer.HasSynced) {
+			return
+		}
+		var pods []*typedef.RawPod
+		for _, obj := range podInformer.GetStore().List() {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				pods = append(pods, (*typedef.RawPod)(pod))
+			}
+		}
+		informer.Publish(typedef.RawPodSyncAllEvent{Pods: pods})
+	}()
+}
+
+// watchErrorHandler records the failure of the watch, the watch is retried with backoff by the informer
+func (informer *APIServerInformer) watchErrorHandler(r *cache.Reflector, err error) {
+	cache.DefaultWatchErrorHandler(r, err)
+	now := time.Now()
+	informer.failureLock.Lock()
+	informer.watchFailures = append(informer.recentFailures(now), now)
+	informer.watchErr = err
+	informer.failureLock.Unlock()
+}
+
+// recentFailures returns the failures of the watch in the window
+func (informer *APIServerInformer) recentFailures(now time.Time) []time.Time {
+	var i int
+	for i < len(informer.watchFailures) && now.Sub(informer.watchFailures[i]) > watchFailureWindow {
+		i++
+	}
+	return informer.watchFailures[i:]
+}
+
+// Healthy returns an error if the watch of the pods keeps failing
+func (informer *APIServerInformer) Healthy() error {
+	informer.failureLock.Lock()
+	defer informer.failureLock.Unlock()
+	if failures := informer.recentFailures(time.Now()); len(failures) >= maxWatchFailures {
+		return fmt.Errorf("pod watch failed %d times in %v: %v", len(failures), watchFailureWindow, informer.watchErr)
+	}
+	return nil
 }
 
 // AddFunc handles the raw pod increase event
 func (informer *APIServerInformer) AddFunc(obj interface{}) {
-	informer.Publish(typedef.RAWPODADD, obj)
+	if pod := toRawPod(obj); pod != nil {
+		informer.Publish(typedef.RawPodAddEvent{Pod: pod})
+	}
 }
 
 // UpdateFunc handles the raw pod update event
 func (informer *APIServerInformer) UpdateFunc(oldObj, newObj interface{}) {
-	informer.Publish(typedef.RAWPODUPDATE, newObj)
+	oldPod, newPod := toRawPod(oldObj), toRawPod(newObj)
+	if oldPod != nil && newPod != nil {
+		informer.Publish(typedef.RawPodUpdateEvent{Old: oldPod, New: newPod})
+	}
 }
 
 // DeleteFunc handles the raw pod deletion event
 func (informer *APIServerInformer) DeleteFunc(obj interface{}) {
-	informer.Publish(typedef.RAWPODDELETE, obj)
+	// the pod is deleted while the watch is disconnected
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if pod := toRawPod(obj); pod != nil {
+		informer.Publish(typedef.RawPodDeleteEvent{Pod: pod})
+	}
+}
+
+// toRawPod converts the object received from the informer to the raw pod, nil is returned if it is not a pod
+func toRawPod(obj interface{}) *typedef.RawPod {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod == nil {
+		log.Warnf("receive invalid pod object: %T", obj)
+		return nil
+	}
+	return (*typedef.RawPod)(pod)
+}
+
+// toRawPods converts the listed pods to the raw pods
+func toRawPods(pods []corev1.Pod) []*typedef.RawPod {
+	rawPods := make([]*typedef.RawPod, 0, len(pods))
+	for i := range pods {
+		rawPods = append(rawPods, (*typedef.RawPod)(&pods[i]))
+	}
+	return rawPods
 }
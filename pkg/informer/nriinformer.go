@@ -38,6 +38,8 @@ type NRIInformer struct {
 	nodeName     string
 	stub         stub.Stub
 	finishedSync chan struct{}
+	// closed is closed when the connection to the runtime is lost
+	closed chan struct{}
 }
 
 // NewNRIInformer create an rubik nri plugin
@@ -46,6 +48,7 @@ func NewNRIInformer(publisher rubikapi.Publisher) (rubikapi.Informer, error) {
 		Publisher:    publisher,
 		nodeName:     os.Getenv(constant.NodeNameEnvKey),
 		finishedSync: make(chan struct{}),
+		closed:       make(chan struct{}),
 	}
 
 	options := []stub.Option{
@@ -70,15 +73,34 @@ func (plugin NRIInformer) Start(ctx context.Context) error {
 	go func() {
 		plugin.stub.Wait()
 		plugin.stub.Stop()
+		close(plugin.closed)
 	}()
 	return nil
 }
 
+// Healthy returns an error if the connection to the runtime is lost
+func (plugin NRIInformer) Healthy() error {
+	select {
+	case <-plugin.closed:
+		return fmt.Errorf("nri connection is closed")
+	default:
+		return nil
+	}
+}
+
 // Synchronize syncs the nri containers & sandboxes
 func (plugin NRIInformer) Synchronize(ctx context.Context, pods []*api.PodSandbox, containers []*api.Container) (
 	[]*api.ContainerUpdate, error) {
-	plugin.Publish(typedef.NRIPODSYNCALL, pods)
-	plugin.Publish(typedef.NRICONTAINERSYNCALL, containers)
+	rawPods := make([]*typedef.NRIRawPod, 0, len(pods))
+	for _, pod := range pods {
+		rawPods = append(rawPods, (*typedef.NRIRawPod)(pod))
+	}
+	rawContainers := make([]*typedef.NRIRawContainer, 0, len(containers))
+	for _, container := range containers {
+		rawContainers = append(rawContainers, (*typedef.NRIRawContainer)(container))
+	}
+	plugin.Publish(typedef.NRIPodSyncAllEvent{Pods: rawPods})
+	plugin.Publish(typedef.NRIContainerSyncAllEvent{Containers: rawContainers})
 	// notify service handler to start
 	close(plugin.finishedSync)
 	return nil, nil
@@ -86,7 +108,7 @@ func (plugin NRIInformer) Synchronize(ctx context.Context, pods []*api.PodSandbo
 
 // RunPodSandbox will be called when sandbox starts.
 func (plugin NRIInformer) RunPodSandbox(ctx context.Context, pod *api.PodSandbox) error {
-	plugin.Publish(typedef.NRIPODADD, pod)
+	plugin.Publish(typedef.NRIPodAddEvent{Pod: (*typedef.NRIRawPod)(pod)})
 	return nil
 }
 
@@ -97,7 +119,7 @@ func (plugin NRIInformer) StopPodSandbox(ctx context.Context, pod *api.PodSandbo
 
 // RemovePodSandbox will be called when sandbox is removed.
 func (plugin NRIInformer) RemovePodSandbox(ctx context.Context, pod *api.PodSandbox) error {
-	plugin.Publish(typedef.NRIPODDELETE, pod)
+	plugin.Publish(typedef.NRIPodDeleteEvent{Pod: (*typedef.NRIRawPod)(pod)})
 	return nil
 }
 
@@ -109,7 +131,7 @@ func (plugin NRIInformer) CreateContainer(context.Context, *api.PodSandbox, *api
 
 // StartContainer will be called when container starts
 func (plugin NRIInformer) StartContainer(ctx context.Context, pod *api.PodSandbox, container *api.Container) error {
-	plugin.Publish(typedef.NRICONTAINERSTART, container)
+	plugin.Publish(typedef.NRIContainerStartEvent{Container: (*typedef.NRIRawContainer)(container)})
 	return nil
 }
 
@@ -120,13 +142,13 @@ func (plugin NRIInformer) UpdateContainer(ctx context.Context, pod *api.PodSandb
 
 // StopContainer will be called when container stops
 func (plugin NRIInformer) StopContainer(ctx context.Context, pod *api.PodSandbox, container *api.Container) ([]*api.ContainerUpdate, error) {
-	plugin.Publish(typedef.NRICONTAINERREMOVE, container)
+	plugin.Publish(typedef.NRIContainerRemoveEvent{Container: (*typedef.NRIRawContainer)(container)})
 	return nil, nil
 }
 
 // RemoveContainer will be called when it removes container
 func (plugin NRIInformer) RemoveContainer(ctx context.Context, pod *api.PodSandbox, container *api.Container) error {
-	plugin.Publish(typedef.NRICONTAINERREMOVE, container)
+	plugin.Publish(typedef.NRIContainerRemoveEvent{Container: (*typedef.NRIRawContainer)(container)})
 	return nil
 }
 
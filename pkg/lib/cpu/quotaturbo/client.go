@@ -36,6 +36,9 @@ type ClientAPI interface {
 	AddCgroup(string, float64) error
 	RemoveCgroup(string) error
 	AllCgroups() []string
+	// Usage history management
+	Histories() map[string][]UsageRecord
+	RestoreHistories(map[string][]UsageRecord)
 	// Parameter management
 	WithOptions(...Option) error
 	ConfigViewer
@@ -81,6 +84,9 @@ func (c *Client) AdjustQuota() error {
 	if err := c.updateCPUUtils(); err != nil {
 		return fmt.Errorf("failed to get current cpu utilization: %v", err)
 	}
+	// the status is locked while adjusting so that the histories can be read concurrently
+	c.StatusStore.Lock()
+	defer c.StatusStore.Unlock()
 	if len(c.cpuQuotas) == 0 {
 		return nil
 	}
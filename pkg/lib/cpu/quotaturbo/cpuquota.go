@@ -159,6 +159,30 @@ func (c *CPUQuota) updateUsage() error {
 	return nil
 }
 
+// restoreUsages puts the usage records saved before restart in front of the current usage,
+// the records earlier than one minute ago do not reflect the recent usage and are dropped
+func (c *CPUQuota) restoreUsages(records []UsageRecord) {
+	if len(c.cpuUsages) == 0 {
+		return
+	}
+	var (
+		latest   = c.cpuUsages[len(c.cpuUsages)-1]
+		earliest = latest.timestamp - int64(time.Minute)
+		usages   = make([]cpuUsage, 0, numberOfRestrictedCycles)
+	)
+	for _, r := range records {
+		if r.Timestamp < earliest || r.Timestamp >= latest.timestamp || r.Usage > latest.usage {
+			continue
+		}
+		usages = append(usages, cpuUsage{timestamp: r.Timestamp, usage: r.Usage})
+	}
+	usages = append(usages, c.cpuUsages...)
+	if over := len(usages) - (numberOfRestrictedCycles - 1); over > 0 {
+		usages = usages[over:]
+	}
+	c.cpuUsages = usages
+}
+
 func writeQuota(mountPoint string, paths []string, delta int64) error {
 	type cgroupQuotaPair struct {
 		h     *cgroup.Hierarchy
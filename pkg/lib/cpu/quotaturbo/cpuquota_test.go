@@ -19,6 +19,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -303,3 +304,32 @@ func TestCPUQuota_WriteQuota(t *testing.T) {
 		})
 	}
 }
+
+// TestCPUQuota_RestoreUsages tests restoring the usage records saved before restart
+func TestCPUQuota_RestoreUsages(t *testing.T) {
+	const now = int64(10 * time.Minute)
+	c := &CPUQuota{cpuUsages: []cpuUsage{{timestamp: now, usage: 1000}}}
+	c.restoreUsages([]UsageRecord{
+		// the record earlier than one minute ago is dropped
+		{Timestamp: now - int64(2*time.Minute), Usage: 100},
+		{Timestamp: now - int64(30*time.Second), Usage: 500},
+		// the record with larger usage is not the history of the container
+		{Timestamp: now - int64(20*time.Second), Usage: 2000},
+		{Timestamp: now - int64(10*time.Second), Usage: 800},
+	})
+	assert.Equal(t, []cpuUsage{
+		{timestamp: now - int64(30*time.Second), usage: 500},
+		{timestamp: now - int64(10*time.Second), usage: 800},
+		{timestamp: now, usage: 1000},
+	}, c.cpuUsages)
+
+	// the number of the usages does not exceed the restricted cycles
+	var records []UsageRecord
+	for i := int64(numberOfRestrictedCycles); i > 0; i-- {
+		records = append(records, UsageRecord{Timestamp: now - i*int64(time.Second), Usage: 0})
+	}
+	c.cpuUsages = []cpuUsage{{timestamp: now, usage: 1000}}
+	c.restoreUsages(records)
+	assert.Len(t, c.cpuUsages, numberOfRestrictedCycles-1)
+	assert.Equal(t, now, c.cpuUsages[len(c.cpuUsages)-1].timestamp)
+}
@@ -16,11 +16,11 @@ package quotaturbo
 
 import (
 	"fmt"
-	"path/filepath"
 	"runtime"
 	"sync"
 	"time"
 
+	"isula.org/rubik/pkg/common/metrics"
 	"isula.org/rubik/pkg/common/util"
 	"isula.org/rubik/pkg/core/typedef/cgroup"
 )
@@ -43,6 +43,14 @@ type StatusStore struct {
 	cpuUtils []cpuUtil
 	// /proc/stat of the previous period
 	lastProcStat ProcStat
+	// cpu usage histories of the cgroups to be added, which are restored after restart
+	histories map[string][]UsageRecord
+}
+
+// UsageRecord is the cpu time in nanoseconds used by the cgroup at the time in Unix nanoseconds
+type UsageRecord struct {
+	Timestamp int64 `json:"timestamp"`
+	Usage     int64 `json:"usage"`
 }
 
 // NewStatusStore returns a pointer to StatusStore
@@ -75,6 +83,10 @@ func (store *StatusStore) AddCgroup(cgroupPath string, cpuLimit float64) error {
 		return fmt.Errorf("error creating cpu quota: %v", err)
 	}
 	store.Lock()
+	if records, ok := store.histories[cgroupPath]; ok {
+		c.restoreUsages(records)
+		delete(store.histories, cgroupPath)
+	}
 	store.cpuQuotas[cgroupPath] = c
 	store.Unlock()
 	return nil
@@ -92,10 +104,11 @@ func (store *StatusStore) RemoveCgroup(cgroupPath string) error {
 		store.Lock()
 		delete(store.cpuQuotas, id)
 		store.Unlock()
+		metrics.QuotaTurboQuota.Delete(id)
 		return nil
 	}
 
-	if !util.PathExist(filepath.Join(cq.MountPoint, "cpu", cq.Path)) {
+	if !util.PathExist(cq.AbsolutePath("cpu")) {
 		return safeDel(cgroupPath)
 	}
 	if err := cq.recoverQuota(); err != nil {
@@ -113,6 +126,29 @@ func (store *StatusStore) AllCgroups() []string {
 	return res
 }
 
+// Histories returns the cpu usage histories of the cgroups that are adjusting quota
+func (store *StatusStore) Histories() map[string][]UsageRecord {
+	store.RLock()
+	defer store.RUnlock()
+	var res = make(map[string][]UsageRecord, len(store.cpuQuotas))
+	for path, cq := range store.cpuQuotas {
+		records := make([]UsageRecord, 0, len(cq.cpuUsages))
+		for _, u := range cq.cpuUsages {
+			records = append(records, UsageRecord{Timestamp: u.timestamp, Usage: u.usage})
+		}
+		res[path] = records
+	}
+	return res
+}
+
+// RestoreHistories sets the cpu usage histories saved before restart,
+// which are restored when the cgroups are added
+func (store *StatusStore) RestoreHistories(histories map[string][]UsageRecord) {
+	store.Lock()
+	store.histories = histories
+	store.Unlock()
+}
+
 // getLastCPUUtil obtain the latest cpu utilization
 func (store *StatusStore) getLastCPUUtil() float64 {
 	if len(store.cpuUtils) == 0 {
@@ -171,6 +207,7 @@ func (store *StatusStore) writeQuota() error {
 		if err := c.writeQuota(); err != nil {
 			errs = appendErr(errs, fmt.Errorf("failed to write cgroup quota %v: %v", id, err))
 		}
+		metrics.QuotaTurboQuota.Set(float64(c.curQuota), id)
 	}
 	return errs
 }
@@ -178,7 +215,7 @@ func (store *StatusStore) writeQuota() error {
 // isAdjustmentAllowed judges whether quota adjustment is allowed
 func isAdjustmentAllowed(h *cgroup.Hierarchy, cpuLimit float64) bool {
 	// 1. containers whose cgroup path does not exist are not considered.
-	if !util.PathExist(filepath.Join(h.MountPoint, "cpu", h.Path)) {
+	if !util.PathExist(h.AbsolutePath("cpu")) {
 		return false
 	}
 
@@ -16,9 +16,12 @@
 package kubernetes
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -63,3 +66,21 @@ func GetClient() (*Client, error) {
 	defaultClient = c
 	return defaultClient, nil
 }
+
+// requestTimeout is the maximum time to wait for the response of the apiserver
+const requestTimeout = 10 * time.Second
+
+// NodeLabels gets the labels of the node from the apiserver
+func NodeLabels(name string) (map[string]string, error) {
+	c, err := GetClient()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	node, err := c.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node %v: %v", name, err)
+	}
+	return node.Labels, nil
+}
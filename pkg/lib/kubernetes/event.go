@@ -0,0 +1,214 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements the recorder emitting the Kubernetes Events of rubik on pods
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"isula.org/rubik/pkg/common/log"
+)
+
+const (
+	// eventSource is the component reporting the events
+	eventSource = "rubik"
+	// eventQueueSize is the maximum number of the events waiting to be emitted
+	eventQueueSize = 1024
+	// eventAggregateWindow is the period in which the same events on an object are counted in one event
+	eventAggregateWindow = 10 * time.Minute
+	// eventBurst is the maximum number of the events emitted on an object at once
+	eventBurst = 25
+	// eventRefillPeriod is the period to allow one more event on an object after the burst
+	eventRefillPeriod = 5 * time.Minute
+	// eventCacheSize is the number of the cached objects and events beyond which the expired ones are pruned
+	eventCacheSize = 4096
+)
+
+// eventKey identifies the same events which are aggregated
+type eventKey struct {
+	uid       types.UID
+	eventType string
+	reason    string
+	message   string
+}
+
+// objectLimiter limits the events emitted on an object
+type objectLimiter struct {
+	*rate.Limiter
+	last time.Time
+}
+
+// EventRecorder emits the events on the objects asynchronously.
+// The events on an object are rate-limited, and the same events are aggregated by increasing the count.
+type EventRecorder struct {
+	client typedcorev1.EventsGetter
+	host   string
+	queue  chan *corev1.Event
+	now    func() time.Time
+	// limiters and events are only accessed by the goroutine emitting the events
+	limiters map[types.UID]*objectLimiter
+	events   map[eventKey]*corev1.Event
+}
+
+// NewEventRecorder returns the recorder emitting the events by the client, host is the node reporting the events
+func NewEventRecorder(client typedcorev1.EventsGetter, host string) *EventRecorder {
+	return &EventRecorder{
+		client:   client,
+		host:     host,
+		queue:    make(chan *corev1.Event, eventQueueSize),
+		now:      time.Now,
+		limiters: make(map[types.UID]*objectLimiter),
+		events:   make(map[eventKey]*corev1.Event),
+	}
+}
+
+// PodReference returns the reference of the pod as the object of the events
+func PodReference(namespace, name, uid string) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		Kind:       "Pod",
+		APIVersion: "v1",
+		Namespace:  namespace,
+		Name:       name,
+		UID:        types.UID(uid),
+	}
+}
+
+// Eventf queues the event on the object without blocking, the event is dropped if the queue is full.
+// eventType is corev1.EventTypeNormal or corev1.EventTypeWarning, reason is a short CamelCase word.
+func (r *EventRecorder) Eventf(ref *corev1.ObjectReference, eventType, reason, format string, args ...interface{}) {
+	ev := &corev1.Event{
+		InvolvedObject: *ref,
+		Type:           eventType,
+		Reason:         reason,
+		Message:        fmt.Sprintf(format, args...),
+		Source:         corev1.EventSource{Component: eventSource, Host: r.host},
+	}
+	select {
+	case r.queue <- ev:
+	default:
+		log.Warnf("event queue is full, drop event %v on %v/%v: %v", reason, ref.Namespace, ref.Name, ev.Message)
+	}
+}
+
+// Run emits the queued events until the context is done
+func (r *EventRecorder) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-r.queue:
+			if err := r.emit(ev); err != nil {
+				log.Errorf("failed to emit event %v on %v/%v: %v", ev.Reason, ev.InvolvedObject.Namespace,
+					ev.InvolvedObject.Name, err)
+			}
+		}
+	}
+}
+
+// emit creates the event, or updates the count of the same event emitted in the aggregation window
+func (r *EventRecorder) emit(ev *corev1.Event) error {
+	now := r.now()
+	r.prune(now)
+	uid := ev.InvolvedObject.UID
+	limiter, ok := r.limiters[uid]
+	if !ok {
+		limiter = &objectLimiter{Limiter: rate.NewLimiter(rate.Every(eventRefillPeriod), eventBurst)}
+		r.limiters[uid] = limiter
+	}
+	limiter.last = now
+	if !limiter.AllowN(now, 1) {
+		log.Debugf("too many events on %v/%v, drop event %v", ev.InvolvedObject.Namespace,
+			ev.InvolvedObject.Name, ev.Reason)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	key := eventKey{uid: uid, eventType: ev.Type, reason: ev.Reason, message: ev.Message}
+	if last, ok := r.events[key]; ok && now.Sub(last.LastTimestamp.Time) < eventAggregateWindow {
+		updated := last.DeepCopy()
+		updated.Count++
+		updated.LastTimestamp = metav1.NewTime(now)
+		result, err := r.client.Events(updated.Namespace).Update(ctx, updated, metav1.UpdateOptions{})
+		if err == nil {
+			r.events[key] = result
+			return nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		// the event has been removed by the apiserver after its TTL, so it is created again
+	}
+
+	ev.Name = fmt.Sprintf("%v.%x", ev.InvolvedObject.Name, now.UnixNano())
+	ev.Namespace = ev.InvolvedObject.Namespace
+	ev.FirstTimestamp = metav1.NewTime(now)
+	ev.LastTimestamp = ev.FirstTimestamp
+	ev.Count = 1
+	result, err := r.client.Events(ev.Namespace).Create(ctx, ev, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	r.events[key] = result
+	return nil
+}
+
+// prune removes the aggregated events out of the window and the limiters which have been refilled
+func (r *EventRecorder) prune(now time.Time) {
+	if len(r.events) > eventCacheSize {
+		for key, ev := range r.events {
+			if now.Sub(ev.LastTimestamp.Time) >= eventAggregateWindow {
+				delete(r.events, key)
+			}
+		}
+	}
+	if len(r.limiters) > eventCacheSize {
+		for uid, limiter := range r.limiters {
+			if now.Sub(limiter.last) >= eventRefillPeriod*eventBurst {
+				delete(r.limiters, uid)
+			}
+		}
+	}
+}
+
+var (
+	defaultRecorder *EventRecorder
+	recorderSync    sync.RWMutex
+)
+
+// SetEventRecorder sets the recorder used by RecordEvent, the events are not emitted if it is nil
+func SetEventRecorder(r *EventRecorder) {
+	recorderSync.Lock()
+	defaultRecorder = r
+	recorderSync.Unlock()
+}
+
+// RecordEvent emits the event on the object by the recorder set by SetEventRecorder
+func RecordEvent(ref *corev1.ObjectReference, eventType, reason, format string, args ...interface{}) {
+	recorderSync.RLock()
+	r := defaultRecorder
+	recorderSync.RUnlock()
+	if r != nil {
+		r.Eventf(ref, eventType, reason, format, args...)
+	}
+}
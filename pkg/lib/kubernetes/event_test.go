@@ -0,0 +1,131 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file tests the event recorder
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// fakeEvents stores the events of all namespaces in memory
+type fakeEvents struct {
+	typedcorev1.EventInterface
+	events  map[string]*corev1.Event
+	created int
+}
+
+func (f *fakeEvents) Events(namespace string) typedcorev1.EventInterface {
+	return f
+}
+
+func (f *fakeEvents) Create(ctx context.Context, ev *corev1.Event, opts metav1.CreateOptions) (*corev1.Event, error) {
+	f.created++
+	f.events[ev.Name] = ev.DeepCopy()
+	return ev, nil
+}
+
+func (f *fakeEvents) Update(ctx context.Context, ev *corev1.Event, opts metav1.UpdateOptions) (*corev1.Event, error) {
+	if _, ok := f.events[ev.Name]; !ok {
+		return nil, apierrors.NewNotFound(corev1.Resource("events"), ev.Name)
+	}
+	f.events[ev.Name] = ev.DeepCopy()
+	return ev, nil
+}
+
+// TestEventRecorder tests aggregating and rate-limiting the events
+func TestEventRecorder(t *testing.T) {
+	client := &fakeEvents{events: make(map[string]*corev1.Event)}
+	r := NewEventRecorder(client, "node1")
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	pod := PodReference("default", "pod1", "uid1")
+	emit := func(ref *corev1.ObjectReference, message string) {
+		r.Eventf(ref, corev1.EventTypeWarning, "Evicted", message)
+		assert.NoError(t, r.emit(<-r.queue))
+	}
+
+	// the same events are counted in one event
+	emit(pod, "evicted")
+	emit(pod, "evicted")
+	assert.Equal(t, 1, client.created)
+	for _, ev := range client.events {
+		assert.Equal(t, int32(2), ev.Count)
+		assert.Equal(t, "Pod", ev.InvolvedObject.Kind)
+		assert.Equal(t, "default", ev.Namespace)
+		assert.Equal(t, corev1.EventSource{Component: eventSource, Host: "node1"}, ev.Source)
+	}
+
+	// the event with another message is a new event
+	emit(pod, "evicted again")
+	assert.Equal(t, 2, client.created)
+
+	// the event is created again after the aggregation window or after it is removed
+	now = now.Add(eventAggregateWindow)
+	emit(pod, "evicted")
+	assert.Equal(t, 3, client.created)
+	client.events = make(map[string]*corev1.Event)
+	emit(pod, "evicted")
+	assert.Equal(t, 4, client.created)
+
+	// the events on a pod are limited after the burst, which does not affect other pods
+	limited := PodReference("default", "pod3", "uid3")
+	created := client.created
+	for i := 0; i < eventBurst; i++ {
+		emit(limited, fmt.Sprintf("evicted %d", i))
+	}
+	assert.Equal(t, created+eventBurst, client.created)
+	emit(limited, "dropped")
+	assert.Equal(t, created+eventBurst, client.created)
+	emit(PodReference("default", "pod2", "uid2"), "evicted")
+	assert.Equal(t, created+eventBurst+1, client.created)
+	now = now.Add(eventRefillPeriod)
+	emit(limited, "allowed")
+	assert.Equal(t, created+eventBurst+2, client.created)
+}
+
+// TestRecordEvent tests emitting the events by the default recorder
+func TestRecordEvent(t *testing.T) {
+	pod := PodReference("default", "pod1", "uid1")
+	// nothing happens without the recorder
+	RecordEvent(pod, corev1.EventTypeNormal, "Test", "message")
+
+	client := &fakeEvents{events: make(map[string]*corev1.Event)}
+	r := NewEventRecorder(client, "node1")
+	SetEventRecorder(r)
+	defer SetEventRecorder(nil)
+	for i := 0; i < eventQueueSize+1; i++ {
+		RecordEvent(pod, corev1.EventTypeNormal, "Test", "message %d", i)
+	}
+	// the event is dropped when the queue is full
+	assert.Equal(t, eventQueueSize, len(r.queue))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return len(r.queue) == 0 }, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+	assert.Equal(t, eventBurst, client.created)
+}
@@ -0,0 +1,200 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file defines the RubikPolicy custom resource and its client
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// PolicyConditionApplied is the condition type telling whether the policy is applied on the node
+	PolicyConditionApplied = "Applied"
+	// fieldManagerPrefix is the prefix of the field manager applying the status of a node,
+	// which is followed by the node name so that each node owns its own entry
+	fieldManagerPrefix = "rubik-"
+	policyKind         = "RubikPolicy"
+)
+
+// PolicyGVR is the resource of RubikPolicy
+var PolicyGVR = schema.GroupVersionResource{Group: "rubik.isula.org", Version: "v1alpha1", Resource: "rubikpolicies"}
+
+// RubikPolicy is the cluster-scoped policy configuring the services of rubik on the selected nodes
+type RubikPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              RubikPolicySpec   `json:"spec"`
+	Status            RubikPolicyStatus `json:"status,omitempty"`
+}
+
+// RubikPolicySpec is the specification of RubikPolicy
+type RubikPolicySpec struct {
+	// NodeSelector selects the nodes having all the labels, all nodes are selected if it is empty
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Services is the service configurations indexed by the service name as the configuration file
+	Services json.RawMessage `json:"services,omitempty"`
+}
+
+// RubikPolicyStatus is the status of RubikPolicy reported by the nodes
+type RubikPolicyStatus struct {
+	Nodes []NodePolicyStatus `json:"nodes,omitempty"`
+}
+
+// NodePolicyStatus is the status of the policy on a node
+type NodePolicyStatus struct {
+	NodeName string `json:"nodeName"`
+	// ObservedGeneration is the generation of the policy handled by the node
+	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	Conditions         []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// Selects returns true if the policy selects the node with the labels
+func (p *RubikPolicy) Selects(labels map[string]string) bool {
+	for k, v := range p.Spec.NodeSelector {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// PolicyFromUnstructured converts the object got from the apiserver to RubikPolicy
+func PolicyFromUnstructured(obj interface{}) (*RubikPolicy, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+	data, err := u.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var p RubikPolicy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to decode policy %v: %v", u.GetName(), err)
+	}
+	return &p, nil
+}
+
+// PolicyClient reads RubikPolicy and writes its status
+type PolicyClient struct {
+	resource dynamic.ResourceInterface
+}
+
+// NewPolicyClient returns the client operating RubikPolicy by the resource interface
+func NewPolicyClient(resource dynamic.ResourceInterface) *PolicyClient {
+	return &PolicyClient{resource: resource}
+}
+
+// GetPolicyClient returns the client of RubikPolicy in the cluster
+func GetPolicyClient() (*PolicyClient, error) {
+	conf, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := dynamic.NewForConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+	return NewPolicyClient(client.Resource(PolicyGVR)), nil
+}
+
+// ListWatch returns the ListerWatcher of RubikPolicy for the informer
+func (c *PolicyClient) ListWatch() cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return c.resource.List(context.Background(), options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return c.resource.Watch(context.Background(), options)
+		},
+	}
+}
+
+// UpdateNodeStatus sets the condition of the node in the status of the policy,
+// the node is removed from the status if the condition is nil.
+// Only the entry of the node is applied by the field manager of the node, so that the nodes
+// reporting the status of the same policy at the same time never conflict with each other.
+func (c *PolicyClient) UpdateNodeStatus(name, nodeName string, generation int64, cond *metav1.Condition) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	var nodes []NodePolicyStatus
+	if cond != nil {
+		node, err := c.nodeStatus(ctx, name, nodeName)
+		if err != nil {
+			return err
+		}
+		node.ObservedGeneration = generation
+		meta.SetStatusCondition(&node.Conditions, *cond)
+		nodes = append(nodes, node)
+	}
+	// the entry applied before is removed by the apiserver if it is no longer in the applied nodes
+	data, err := json.Marshal(&policyStatusApply{
+		APIVersion: PolicyGVR.GroupVersion().String(),
+		Kind:       policyKind,
+		Metadata:   map[string]string{"name": name},
+		Status:     RubikPolicyStatus{Nodes: nodes},
+	})
+	if err != nil {
+		return err
+	}
+	force := true
+	_, err = c.resource.Patch(ctx, name, types.ApplyPatchType, data,
+		metav1.PatchOptions{FieldManager: fieldManagerPrefix + nodeName, Force: &force}, "status")
+	if apierrors.IsNotFound(err) && cond == nil {
+		return nil
+	}
+	return err
+}
+
+// policyStatusApply is the configuration of the status applied by a node
+type policyStatusApply struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   map[string]string `json:"metadata"`
+	Status     RubikPolicyStatus `json:"status"`
+}
+
+// nodeStatus returns the current status of the node in the policy
+// to keep the transition time of the condition whose status is not changed
+func (c *PolicyClient) nodeStatus(ctx context.Context, name, nodeName string) (NodePolicyStatus, error) {
+	node := NodePolicyStatus{NodeName: nodeName}
+	obj, err := c.resource.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return node, err
+	}
+	p, err := PolicyFromUnstructured(obj)
+	if err != nil {
+		return node, err
+	}
+	for _, n := range p.Status.Nodes {
+		if n.NodeName == nodeName {
+			node = n
+			break
+		}
+	}
+	return node, nil
+}
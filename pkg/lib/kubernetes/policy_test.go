@@ -0,0 +1,167 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file tests the RubikPolicy client
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// fakePolicies stores the policies in memory and merges the applied status as the apiserver,
+// where the entries of the nodes are owned by the field managers applying them
+type fakePolicies struct {
+	dynamic.ResourceInterface
+	objects map[string]*unstructured.Unstructured
+	// applied is the entries of the nodes applied by the field managers of the policies
+	applied map[string]map[string][]NodePolicyStatus
+	patches []string
+}
+
+func (f *fakePolicies) Get(ctx context.Context, name string, options metav1.GetOptions,
+	subresources ...string) (*unstructured.Unstructured, error) {
+	obj, ok := f.objects[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(PolicyGVR.GroupResource(), name)
+	}
+	return obj.DeepCopy(), nil
+}
+
+func (f *fakePolicies) Patch(ctx context.Context, name string, pt types.PatchType, data []byte,
+	options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if pt != types.ApplyPatchType || len(subresources) != 1 || subresources[0] != "status" ||
+		options.FieldManager == "" || options.Force == nil || !*options.Force {
+		return nil, fmt.Errorf("unexpected patch %v of %v", pt, subresources)
+	}
+	obj, ok := f.objects[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(PolicyGVR.GroupResource(), name)
+	}
+	f.patches = append(f.patches, string(data))
+	var applied policyStatusApply
+	if err := json.Unmarshal(data, &applied); err != nil {
+		return nil, err
+	}
+	if f.applied[name] == nil {
+		f.applied[name] = make(map[string][]NodePolicyStatus)
+	}
+	f.applied[name][options.FieldManager] = applied.Status.Nodes
+	var nodes []interface{}
+	for _, entries := range f.applied[name] {
+		for _, n := range entries {
+			u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&n)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, u)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].(map[string]interface{})["nodeName"].(string) <
+			nodes[j].(map[string]interface{})["nodeName"].(string)
+	})
+	obj.Object["status"] = map[string]interface{}{"nodes": nodes}
+	return obj.DeepCopy(), nil
+}
+
+func newPolicy(name string, generation int64, nodeSelector map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": PolicyGVR.GroupVersion().String(),
+		"kind":       "RubikPolicy",
+		"spec": map[string]interface{}{
+			"nodeSelector": nodeSelector,
+			"services":     map[string]interface{}{"preemption": map[string]interface{}{"resource": []interface{}{"cpu"}}},
+		},
+	}}
+	obj.SetName(name)
+	obj.SetGeneration(generation)
+	return obj
+}
+
+// TestPolicyFromUnstructured tests converting the object and selecting the nodes
+func TestPolicyFromUnstructured(t *testing.T) {
+	p, err := PolicyFromUnstructured(newPolicy("batch", 2, map[string]interface{}{"pool": "batch"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "batch", p.Name)
+	assert.Equal(t, int64(2), p.Generation)
+	assert.JSONEq(t, `{"preemption": {"resource": ["cpu"]}}`, string(p.Spec.Services))
+	assert.True(t, p.Selects(map[string]string{"pool": "batch", "zone": "a"}))
+	assert.False(t, p.Selects(map[string]string{"pool": "online"}))
+	assert.False(t, p.Selects(nil))
+
+	p, err = PolicyFromUnstructured(newPolicy("all", 1, nil))
+	assert.NoError(t, err)
+	assert.True(t, p.Selects(nil))
+
+	_, err = PolicyFromUnstructured("policy")
+	assert.Error(t, err)
+}
+
+// TestUpdateNodeStatus tests writing the status of the node
+func TestUpdateNodeStatus(t *testing.T) {
+	fake := &fakePolicies{
+		objects: map[string]*unstructured.Unstructured{"batch": newPolicy("batch", 1, nil)},
+		applied: make(map[string]map[string][]NodePolicyStatus),
+	}
+	c := NewPolicyClient(fake)
+	cond := &metav1.Condition{Type: PolicyConditionApplied, Status: metav1.ConditionTrue, Reason: "Applied"}
+	getStatus := func() RubikPolicyStatus {
+		p, err := PolicyFromUnstructured(fake.objects["batch"])
+		assert.NoError(t, err)
+		return p.Status
+	}
+
+	// TC1: each node applies only its own entry
+	assert.NoError(t, c.UpdateNodeStatus("batch", "node-2", 1, cond))
+	assert.NoError(t, c.UpdateNodeStatus("batch", "node-1", 1, cond))
+	assert.NotContains(t, fake.patches[1], "node-2")
+	status := getStatus()
+	assert.Len(t, status.Nodes, 2)
+	assert.Equal(t, "node-1", status.Nodes[0].NodeName)
+	assert.Equal(t, int64(1), status.Nodes[0].ObservedGeneration)
+	assert.Equal(t, metav1.ConditionTrue, status.Nodes[0].Conditions[0].Status)
+	transition := status.Nodes[0].Conditions[0].LastTransitionTime
+
+	// TC2: the condition of the node is replaced, the transition time is kept if the status is not changed
+	assert.NoError(t, c.UpdateNodeStatus("batch", "node-1", 2, cond))
+	assert.True(t, transition.Equal(&getStatus().Nodes[0].Conditions[0].LastTransitionTime))
+	failed := &metav1.Condition{Type: PolicyConditionApplied, Status: metav1.ConditionFalse, Reason: "ApplyFailed",
+		Message: "invalid"}
+	assert.NoError(t, c.UpdateNodeStatus("batch", "node-1", 3, failed))
+	status = getStatus()
+	assert.Len(t, status.Nodes[0].Conditions, 1)
+	assert.Equal(t, int64(3), status.Nodes[0].ObservedGeneration)
+	assert.Equal(t, "invalid", status.Nodes[0].Conditions[0].Message)
+
+	// TC3: the node is removed
+	assert.NoError(t, c.UpdateNodeStatus("batch", "node-1", 0, nil))
+	assert.NotContains(t, fake.patches[len(fake.patches)-1], "node")
+	status = getStatus()
+	assert.Len(t, status.Nodes, 1)
+	assert.Equal(t, "node-2", status.Nodes[0].NodeName)
+
+	// TC4: the deleted policy needs no removal
+	assert.NoError(t, c.UpdateNodeStatus("deleted", "node-1", 0, nil))
+	assert.Error(t, c.UpdateNodeStatus("deleted", "node-1", 1, cond))
+}
@@ -0,0 +1,118 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file translates the annotations of other schedulers into the settings of rubik
+
+package podmanager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"isula.org/rubik/pkg/config"
+	"isula.org/rubik/pkg/core/typedef"
+)
+
+type translation struct {
+	// key is the annotation of the setting
+	key     string
+	sources []config.AnnotationSource
+}
+
+// Translator sets the annotations of the settings by the configured annotations or labels of the pods
+type Translator struct {
+	translations []translation
+}
+
+// NewTranslator checks the annotation sources indexed by the name of the setting
+func NewTranslator(sources map[string][]config.AnnotationSource) (*Translator, error) {
+	var names = make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	// the settings are translated in a fixed order
+	sort.Strings(names)
+	var t = &Translator{translations: make([]translation, 0, len(names))}
+	for _, name := range names {
+		key, ok := settingKeys[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported setting %v", name)
+		}
+		for _, source := range sources[name] {
+			if errs := validation.IsQualifiedName(source.Key); len(errs) != 0 {
+				return nil, fmt.Errorf("invalid key %q of %v: %v", source.Key, name, strings.Join(errs, "; "))
+			}
+			for from, to := range source.Values {
+				if err := checkSetting(key, to); err != nil {
+					return nil, fmt.Errorf("invalid value of %v translated from %v=%v: %v", name, source.Key, from, err)
+				}
+			}
+		}
+		t.translations = append(t.translations, translation{key: key, sources: sources[name]})
+	}
+	return t, nil
+}
+
+// lookup returns the value of the setting translated from the first source set by the pod
+func (t *translation) lookup(pod *typedef.PodInfo) (string, bool) {
+	for _, source := range t.sources {
+		var m = pod.Annotations
+		if source.Label {
+			m = pod.Labels
+		}
+		value, ok := m[source.Key]
+		if !ok {
+			continue
+		}
+		if len(source.Values) != 0 {
+			if value, ok = source.Values[value]; !ok {
+				continue
+			}
+		} else if checkSetting(t.key, value) != nil {
+			continue
+		}
+		return value, true
+	}
+	return "", false
+}
+
+// Apply sets the annotations of the settings not annotated by the pod,
+// which are taken as annotated by the pod and take precedence over the pod rules
+func (t *Translator) Apply(pod *typedef.PodInfo) {
+	if t == nil || pod == nil {
+		return
+	}
+	// the settings annotated by the pod are checked before any annotation is set
+	var values = make(map[string]string)
+	for i := range t.translations {
+		if annotated(pod, t.translations[i].key) {
+			continue
+		}
+		if value, ok := t.translations[i].lookup(pod); ok {
+			values[t.translations[i].key] = value
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+	// the annotations may be shared with the raw pod, so they are copied before being changed
+	var annotations = make(map[string]string, len(pod.Annotations)+len(values))
+	for k, v := range pod.Annotations {
+		annotations[k] = v
+	}
+	for k, v := range values {
+		annotations[k] = v
+	}
+	pod.Annotations = annotations
+}
@@ -0,0 +1,143 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file tests the translation of the annotations of other schedulers
+
+package podmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/config"
+	"isula.org/rubik/pkg/core/typedef"
+)
+
+const koordQoSClass = "koordinator.sh/qosClass"
+
+var testSources = map[string][]config.AnnotationSource{
+	"priority": {
+		{Key: "example.com/tier"},
+		{Key: koordQoSClass, Label: true, Values: map[string]string{
+			"LSE": "latency-critical", "LSR": "online", "LS": "burstable-online", "BE": "best-effort"}},
+	},
+	"cacheLimit": {{Key: "example.com/cache"}},
+}
+
+// TestNewTranslator tests checking the annotation sources
+func TestNewTranslator(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources map[string][]config.AnnotationSource
+		wantErr bool
+	}{
+		{
+			name:    "TC1-valid sources",
+			sources: testSources,
+		},
+		{
+			name:    "TC2-unsupported setting",
+			sources: map[string][]config.AnnotationSource{"cpuShares": {{Key: "example.com/shares"}}},
+			wantErr: true,
+		},
+		{
+			name:    "TC3-invalid key",
+			sources: map[string][]config.AnnotationSource{"cacheLimit": {{Key: "example.com/cache/limit"}}},
+			wantErr: true,
+		},
+		{
+			name: "TC4-invalid translated value",
+			sources: map[string][]config.AnnotationSource{"preemptable": {
+				{Key: koordQoSClass, Values: map[string]string{"BE": "yes"}}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTranslator(tt.sources)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+// TestTranslator_Apply tests the annotations of the settings are set by the first source set by the pod
+func TestTranslator_Apply(t *testing.T) {
+	translator, err := NewTranslator(testSources)
+	assert.NoError(t, err)
+	tests := []struct {
+		name        string
+		pod         *typedef.PodInfo
+		want        typedef.Priority
+		annotations map[string]string
+	}{
+		{
+			name: "TC1-translate the label",
+			pod: &typedef.PodInfo{Labels: map[string]string{koordQoSClass: "BE"},
+				Annotations: map[string]string{"example.com/cache": "low"}},
+			want: typedef.BestEffort,
+			annotations: map[string]string{"example.com/cache": "low", constant.CacheLimitAnnotationKey: "low",
+				constant.PriorityTierAnnotationKey: "best-effort"},
+		},
+		{
+			name: "TC2-the first source takes precedence",
+			pod: &typedef.PodInfo{Labels: map[string]string{koordQoSClass: "BE"},
+				Annotations: map[string]string{"example.com/tier": "batch"}},
+			want: typedef.Batch,
+			annotations: map[string]string{"example.com/tier": "batch",
+				constant.PriorityTierAnnotationKey: "batch"},
+		},
+		{
+			name: "TC3-invalid and untranslated values are ignored",
+			pod: &typedef.PodInfo{Labels: map[string]string{koordQoSClass: "SYSTEM"},
+				Annotations: map[string]string{"example.com/tier": "high"}},
+			want:        typedef.Online,
+			annotations: map[string]string{"example.com/tier": "high"},
+		},
+		{
+			name: "TC4-the built-in annotations take precedence",
+			pod: &typedef.PodInfo{Labels: map[string]string{koordQoSClass: "LSE"},
+				Annotations: map[string]string{constant.PriorityAnnotationKey: "true"}},
+			want:        typedef.Batch,
+			annotations: map[string]string{constant.PriorityAnnotationKey: "true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origin := tt.pod.Annotations
+			translator.Apply(tt.pod)
+			assert.Equal(t, tt.want, tt.pod.Priority())
+			assert.Equal(t, tt.annotations, tt.pod.Annotations)
+			// the annotations shared with the raw pod are never changed
+			if len(tt.annotations) != len(origin) {
+				assert.NotEqual(t, origin, tt.pod.Annotations)
+			}
+		})
+	}
+}
+
+// TestPodManager_Translator tests the translated annotations take precedence over the pod rules
+func TestPodManager_Translator(t *testing.T) {
+	translator, err := NewTranslator(testSources)
+	assert.NoError(t, err)
+	rules, err := NewRules(testRules[:1])
+	assert.NoError(t, err)
+	manager := NewPodManager(&recordPublisher{})
+	manager.SetTranslator(translator)
+	manager.SetRules(rules)
+
+	pod := &typedef.PodInfo{Namespace: "kube-system", Labels: map[string]string{koordQoSClass: "LS"}}
+	manager.resolve(pod)
+	assert.Equal(t, typedef.BurstableOnline, pod.Priority())
+	assert.Equal(t, "system", pod.Rule)
+	assert.Empty(t, pod.RuleValues)
+}
@@ -16,10 +16,10 @@ package podmanager
 
 import (
 	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
 
-	corev1 "k8s.io/api/core/v1"
-
-	nriapi "github.com/containerd/nri/pkg/api"
 	"isula.org/rubik/pkg/api"
 	"isula.org/rubik/pkg/common/log"
 	"isula.org/rubik/pkg/core/subscriber"
@@ -34,6 +34,14 @@ type PodManager struct {
 	api.Subscriber
 	api.Publisher
 	Pods *PodCache
+	// synced is set to 1 after the full pods are received from the informer
+	synced int32
+	// lock serializes handling the events and replacing the rules
+	lock       sync.Mutex
+	rules      *Rules
+	translator *Translator
+	// handlers dispatch the subscribed events
+	handlers *subscriber.Handlers
 }
 
 // NewPodManager returns a PodManager pointer
@@ -42,225 +50,39 @@ func NewPodManager(publisher api.Publisher) *PodManager {
 		Pods:      NewPodCache(),
 		Publisher: publisher,
 	}
+	manager.handlers = manager.newHandlers()
 	manager.Subscriber = subscriber.NewGenericSubscriber(manager, PodManagerName)
 	return manager
 }
 
 // HandleEvent handles the event from publisher
-func (manager *PodManager) HandleEvent(eventType typedef.EventType, event typedef.Event) {
-	switch eventType {
-	case typedef.RAWPODADD, typedef.RAWPODUPDATE, typedef.RAWPODDELETE:
-		manager.handleWatchEvent(eventType, event)
-	case typedef.RAWPODSYNCALL:
-		manager.handleListEvent(eventType, event)
-	case typedef.NRIPODADD, typedef.NRIPODDELETE:
-		manager.handleNRIPodEvent(eventType, event)
-	case typedef.NRICONTAINERSTART, typedef.NRICONTAINERREMOVE:
-		manager.handleNRIContainerEvent(eventType, event)
-	case typedef.NRIPODSYNCALL:
-		manager.handleSYNCNRIPodsEvent(eventType, event)
-	case typedef.NRICONTAINERSYNCALL:
-		manager.handleSYNCNRIContainersEvent(eventType, event)
-	default:
-		log.Infof("failed to process %s type event", eventType.String())
-	}
-}
-
-// handleWatchEvent handles the watch event
-func (manager *PodManager) handleWatchEvent(eventType typedef.EventType, event typedef.Event) {
-	pod, err := eventToRawPod(event)
-	if err != nil {
-		log.Warnf(err.Error())
-		return
-	}
-
-	switch eventType {
-	case typedef.RAWPODADD:
-		manager.addFunc(pod)
-	case typedef.RAWPODUPDATE:
-		manager.updateFunc(pod)
-	case typedef.RAWPODDELETE:
-		manager.deleteFunc(pod)
-	default:
-		log.Errorf("invalid event type...")
-	}
-}
-
-// handlenripodevent handles the nri pod event
-func (manager *PodManager) handleNRIPodEvent(eventType typedef.EventType, event typedef.Event) {
-	pod, err := eventToNRIRawPod(event)
-	if err != nil {
-		log.Warnf(err.Error())
-		return
-	}
-
-	switch eventType {
-	case typedef.NRIPODADD:
-		manager.addNRIPodFunc(pod)
-	case typedef.NRIPODDELETE:
-		manager.deleteNRIPodFunc(pod)
-	default:
-		log.Errorf("code problem, should not go here...")
-	}
-}
-
-// handlenricontainerevent handles the nri container event
-func (manager *PodManager) handleNRIContainerEvent(eventType typedef.EventType, event typedef.Event) {
-	container, err := eventToNRIRawContainer(event)
-	if err != nil {
-		log.Warnf(err.Error())
-		return
-	}
-	switch eventType {
-	case typedef.NRICONTAINERSTART:
-		manager.addNRIContainerFunc(container)
-	case typedef.NRICONTAINERREMOVE:
-		manager.removeNRIContainerFunc(container)
-	default:
-		log.Errorf("code Problem, should not go here...")
-	}
-
-}
-
-// handleListEvent handles the list event
-func (manager *PodManager) handleListEvent(eventType typedef.EventType, event typedef.Event) {
-	pods, err := eventToRawPods(event)
-	if err != nil {
-		log.Errorf(err.Error())
-		return
-	}
-	switch eventType {
-	case typedef.RAWPODSYNCALL:
-		manager.sync(pods)
-	default:
-		log.Errorf("invalid event type...")
-	}
-}
-
-// handleSYNCNRIPodsEvent handles sync pod event
-func (manager *PodManager) handleSYNCNRIPodsEvent(eventType typedef.EventType, event typedef.Event) {
-	pods, err := eventToNRIRawPods(event)
-	if err != nil {
-		log.Errorf(err.Error())
-		return
-	}
-	switch eventType {
-	case typedef.NRIPODSYNCALL:
-		manager.nripodssync(pods)
-	default:
-		log.Errorf("code problem, should not go here...")
-	}
-}
-
-// handleSYNCNRIContainersEvent handles sync container event
-func (manager *PodManager) handleSYNCNRIContainersEvent(eventType typedef.EventType, event typedef.Event) {
-	containers, err := eventToNRIRawContainers(event)
-	if err != nil {
-		log.Errorf(err.Error())
-		return
-	}
-	switch eventType {
-	case typedef.NRICONTAINERSYNCALL:
-		manager.nricontainerssync(containers)
-	default:
-		log.Errorf("code problem, should not go here...")
-	}
-}
-
-// eventToNRIRawContainers handles nri containers event
-func eventToNRIRawContainers(e typedef.Event) ([]*typedef.NRIRawContainer, error) {
-	containers, ok := e.([]*nriapi.Container)
-	if !ok {
-		return nil, fmt.Errorf("fail to get *typedef.NRIRawContainer which type is %T", e)
-	}
-	toRawContainerPointer := func(container nriapi.Container) *typedef.NRIRawContainer {
-		tmp := typedef.NRIRawContainer(container)
-		return &tmp
-	}
-	var pointerContainers []*typedef.NRIRawContainer
-	for _, container := range containers {
-		pointerContainers = append(pointerContainers, toRawContainerPointer(*container))
-	}
-	return pointerContainers, nil
-}
-
-// eventToNRIRawPods handles nri pods event
-func eventToNRIRawPods(e typedef.Event) ([]*typedef.NRIRawPod, error) {
-	pods, ok := e.([]*nriapi.PodSandbox)
-	if !ok {
-		return nil, fmt.Errorf("fail to get *typedef.NRIRawPod which type is %T", e)
-	}
-	toRawPodPointer := func(pod nriapi.PodSandbox) *typedef.NRIRawPod {
-		tmp := typedef.NRIRawPod(pod)
-		return &tmp
+func (manager *PodManager) HandleEvent(event typedef.Event) {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	if !manager.handlers.Dispatch(event) {
+		log.Infof("failed to process %s type event", event.Type().String())
 	}
-	var pointerPods []*typedef.NRIRawPod
-	for _, pod := range pods {
-		pointerPods = append(pointerPods, toRawPodPointer(*pod))
-	}
-	return pointerPods, nil
-}
-
-// eventToNRIRawPod handles nri pod event
-func eventToNRIRawPod(e typedef.Event) (*typedef.NRIRawPod, error) {
-	pod, ok := e.(*nriapi.PodSandbox)
-	if !ok {
-		return nil, fmt.Errorf("fail to get *typedef.NRIRawPod which type is %T", e)
-	}
-	nriRawPod := typedef.NRIRawPod(*pod)
-	return &nriRawPod, nil
-}
-
-// eventToNRIRawContainer handles nri container event
-func eventToNRIRawContainer(e typedef.Event) (*typedef.NRIRawContainer, error) {
-	container, ok := e.(*nriapi.Container)
-	if !ok {
-		return nil, fmt.Errorf("fail to get *typedef.NRIRawContainer which type is %T", e)
-	}
-	nriRawContainer := typedef.NRIRawContainer(*container)
-	return &nriRawContainer, nil
 }
 
 // EventTypes returns the intersted event types
 func (manager *PodManager) EventTypes() []typedef.EventType {
-	return []typedef.EventType{typedef.RAWPODADD,
-		typedef.RAWPODUPDATE,
-		typedef.RAWPODDELETE,
-		typedef.RAWPODSYNCALL,
-		typedef.NRIPODADD,
-		typedef.NRICONTAINERSTART,
-		typedef.NRIPODDELETE,
-		typedef.NRIPODSYNCALL,
-		typedef.NRICONTAINERSYNCALL,
-		typedef.NRICONTAINERREMOVE,
-	}
+	return manager.handlers.EventTypes()
 }
 
-// eventToRawPod converts the event interface to RawPod pointer
-func eventToRawPod(e typedef.Event) (*typedef.RawPod, error) {
-	pod, ok := e.(*corev1.Pod)
-	if !ok {
-		return nil, fmt.Errorf("failed to get raw pod information")
+// newHandlers returns the handlers of the raw pod events, which are called with the lock held
+func (manager *PodManager) newHandlers() *subscriber.Handlers {
+	return &subscriber.Handlers{
+		OnRawPodAdd:           func(e typedef.RawPodAddEvent) { manager.addFunc(e.Pod) },
+		OnRawPodUpdate:        func(e typedef.RawPodUpdateEvent) { manager.updateFunc(e.New) },
+		OnRawPodDelete:        func(e typedef.RawPodDeleteEvent) { manager.deleteFunc(e.Pod) },
+		OnRawPodSyncAll:       func(e typedef.RawPodSyncAllEvent) { manager.sync(e.Pods) },
+		OnNRIPodAdd:           func(e typedef.NRIPodAddEvent) { manager.addNRIPodFunc(e.Pod) },
+		OnNRIPodDelete:        func(e typedef.NRIPodDeleteEvent) { manager.deleteNRIPodFunc(e.Pod) },
+		OnNRIContainerStart:   func(e typedef.NRIContainerStartEvent) { manager.addNRIContainerFunc(e.Container) },
+		OnNRIContainerRemove:  func(e typedef.NRIContainerRemoveEvent) { manager.removeNRIContainerFunc(e.Container) },
+		OnNRIPodSyncAll:       func(e typedef.NRIPodSyncAllEvent) { manager.nripodssync(e.Pods) },
+		OnNRIContainerSyncAll: func(e typedef.NRIContainerSyncAllEvent) { manager.nricontainerssync(e.Containers) },
 	}
-	rawPod := typedef.RawPod(*pod)
-	return &rawPod, nil
-}
-
-// eventToRawPods converts the event interface to RawPod pointer slice
-func eventToRawPods(e typedef.Event) ([]*typedef.RawPod, error) {
-	pods, ok := e.([]corev1.Pod)
-	if !ok {
-		return nil, fmt.Errorf("failed to get raw pod information")
-	}
-	toRawPodPointer := func(pod corev1.Pod) *typedef.RawPod {
-		tmp := typedef.RawPod(pod)
-		return &tmp
-	}
-	var pointerPods []*typedef.RawPod
-	for _, pod := range pods {
-		pointerPods = append(pointerPods, toRawPodPointer(pod))
-	}
-	return pointerPods, nil
 }
 
 // addNRIPodFunc handles nri pod add event
@@ -281,6 +103,7 @@ func (manager *PodManager) addNRIPodFunc(pod *typedef.NRIRawPod) {
 		log.Errorf("fail to strip info from raw pod")
 		return
 	}
+	manager.resolve(podInfo)
 	// step2. add pod information
 	manager.tryAddNRIPod(podInfo)
 }
@@ -315,7 +138,7 @@ func (manager *PodManager) addNRIContainerFunc(container *typedef.NRIRawContaine
 	for _, pod := range manager.Pods.Pods {
 		if container.PodSandboxId == pod.ID {
 			pod.IDContainersMap[ci.ID] = ci
-			manager.Publish(typedef.INFOADD, pod.DeepCopy())
+			manager.Publish(typedef.PodAddEvent{Pod: pod.DeepCopy()})
 			break
 		}
 	}
@@ -349,6 +172,7 @@ func (manager *PodManager) addFunc(pod *typedef.RawPod) {
 		log.Errorf("failed to extract information from raw pod")
 		return
 	}
+	manager.resolve(podInfo)
 	// step2. add pod information
 	manager.tryAdd(podInfo)
 }
@@ -367,6 +191,7 @@ func (manager *PodManager) updateFunc(pod *typedef.RawPod) {
 		log.Errorf("failed to extract information from raw pod")
 		return
 	}
+	manager.resolve(podInfo)
 	// The calling order must be updated first and then added
 	// step2: process exited and running pod
 	manager.tryUpdate(podInfo)
@@ -389,7 +214,7 @@ func (manager *PodManager) tryAdd(podInfo *typedef.PodInfo) {
 	// only add when pod is not existed
 	if !manager.Pods.podExist(podInfo.UID) {
 		manager.Pods.addPod(podInfo)
-		manager.Publish(typedef.INFOADD, podInfo.DeepCopy())
+		manager.Publish(typedef.PodAddEvent{Pod: podInfo.DeepCopy()})
 	}
 }
 
@@ -407,7 +232,7 @@ func (manager *PodManager) tryUpdate(podInfo *typedef.PodInfo) {
 	if manager.Pods.podExist(podInfo.UID) {
 		oldPod := manager.Pods.getPod(podInfo.UID)
 		manager.Pods.updatePod(podInfo)
-		manager.Publish(typedef.INFOUPDATE, []*typedef.PodInfo{oldPod, podInfo.DeepCopy()})
+		manager.Publish(typedef.PodUpdateEvent{Old: oldPod, New: podInfo.DeepCopy()})
 	}
 }
 
@@ -417,7 +242,7 @@ func (manager *PodManager) tryDelete(id string) {
 	oldPod := manager.Pods.getPod(id)
 	if oldPod != nil {
 		manager.Pods.delPod(id)
-		manager.Publish(typedef.INFODELETE, oldPod)
+		manager.Publish(typedef.PodDeleteEvent{Pod: oldPod})
 	}
 }
 
@@ -428,9 +253,12 @@ func (manager *PodManager) sync(pods []*typedef.RawPod) {
 		if pod == nil || !pod.Running() {
 			continue
 		}
-		newPods = append(newPods, pod.ExtractPodInfo())
+		podInfo := pod.ExtractPodInfo()
+		manager.resolve(podInfo)
+		newPods = append(newPods, podInfo)
 	}
 	manager.Pods.substitute(newPods)
+	atomic.StoreInt32(&manager.synced, 1)
 }
 
 // nripodssync handles sync all pods
@@ -440,9 +268,48 @@ func (manager *PodManager) nripodssync(pods []*typedef.NRIRawPod) {
 		if pod == nil || !pod.Running() {
 			continue
 		}
-		newPods = append(newPods, pod.ConvertNRIRawPod2PodInfo())
+		podInfo := pod.ConvertNRIRawPod2PodInfo()
+		manager.resolve(podInfo)
+		newPods = append(newPods, podInfo)
 	}
 	manager.Pods.substitute(newPods)
+	atomic.StoreInt32(&manager.synced, 1)
+}
+
+// SetTranslator sets the translator of the annotations of other schedulers,
+// which needs to be set before handling any event
+func (manager *PodManager) SetTranslator(translator *Translator) {
+	manager.lock.Lock()
+	manager.translator = translator
+	manager.lock.Unlock()
+}
+
+// resolve sets the settings of the pod by the translated annotations and the pod rules
+func (manager *PodManager) resolve(podInfo *typedef.PodInfo) {
+	manager.translator.Apply(podInfo)
+	manager.rules.Apply(podInfo)
+}
+
+// SetRules replaces the pod rules and applies them to the cached pods,
+// the pods whose values set by the rules are changed are published as updated
+func (manager *PodManager) SetRules(rules *Rules) {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	manager.rules = rules
+	for _, oldPod := range manager.Pods.listPod() {
+		newPod := oldPod.DeepCopy()
+		rules.Apply(newPod)
+		if newPod.Rule == oldPod.Rule && reflect.DeepEqual(newPod.RuleValues, oldPod.RuleValues) {
+			continue
+		}
+		log.Infof("pod %v matches rule %q after the rules change", newPod.Name, newPod.Rule)
+		manager.tryUpdate(newPod)
+	}
+}
+
+// Synced returns true if the full pods have been received from the informer
+func (manager *PodManager) Synced() bool {
+	return atomic.LoadInt32(&manager.synced) == 1
 }
 
 // nricontainerssync handles sync all containers
@@ -18,6 +18,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"isula.org/rubik/pkg/api"
 	"isula.org/rubik/pkg/common/constant"
@@ -97,3 +99,52 @@ func TestPodManager_ListContainersWithOptions(t *testing.T) {
 		})
 	}
 }
+
+// recordPublisher records the published events
+type recordPublisher struct {
+	events []typedef.Event
+}
+
+// Subscribe does nothing
+func (p *recordPublisher) Subscribe(s api.Subscriber) error { return nil }
+
+// Unsubscribe does nothing
+func (p *recordPublisher) Unsubscribe(s api.Subscriber) {}
+
+// Publish records the event
+func (p *recordPublisher) Publish(event typedef.Event) {
+	p.events = append(p.events, event)
+}
+
+// TestPodManager_HandleEvent tests the raw pod events are converted into the pod information events
+func TestPodManager_HandleEvent(t *testing.T) {
+	rawPod := func(phase corev1.PodPhase) *typedef.RawPod {
+		return &typedef.RawPod{
+			ObjectMeta: metav1.ObjectMeta{UID: "testPod1", Name: "foo"},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	pub := &recordPublisher{}
+	manager := NewPodManager(pub)
+
+	manager.HandleEvent(typedef.RawPodSyncAllEvent{Pods: []*typedef.RawPod{rawPod(corev1.PodPending)}})
+	assert.True(t, manager.Synced())
+	assert.Nil(t, manager.Pods.getPod("testPod1"))
+	manager.HandleEvent(typedef.RawPodAddEvent{Pod: rawPod(corev1.PodRunning)})
+	manager.HandleEvent(typedef.RawPodUpdateEvent{Old: rawPod(corev1.PodRunning), New: rawPod(corev1.PodRunning)})
+	manager.HandleEvent(typedef.RawPodUpdateEvent{Old: rawPod(corev1.PodRunning), New: rawPod(corev1.PodSucceeded)})
+	// the pod has been deleted when it exits
+	manager.HandleEvent(typedef.RawPodDeleteEvent{Pod: rawPod(corev1.PodSucceeded)})
+
+	assert.Len(t, pub.events, 3)
+	add, ok := pub.events[0].(typedef.PodAddEvent)
+	assert.True(t, ok)
+	assert.Equal(t, "testPod1", add.Pod.UID)
+	update, ok := pub.events[1].(typedef.PodUpdateEvent)
+	assert.True(t, ok)
+	assert.Equal(t, "foo", update.Old.Name)
+	assert.Equal(t, "foo", update.New.Name)
+	_, ok = pub.events[2].(typedef.PodDeleteEvent)
+	assert.True(t, ok)
+	assert.Nil(t, manager.Pods.getPod("testPod1"))
+}
@@ -0,0 +1,236 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements the pod rules setting the values of the pods not annotated
+
+package podmanager
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/core/typedef"
+)
+
+// settingKeys are the settings of the pods set by the pod rules or the translated annotations,
+// and the annotations they stand for
+var settingKeys = map[string]string{
+	"priority":       constant.PriorityTierAnnotationKey,
+	"preemptable":    constant.PriorityAnnotationKey,
+	"cacheLimit":     constant.CacheLimitAnnotationKey,
+	"quotaBurstTime": constant.QuotaBurstAnnotationKey,
+	"blkioLimit":     constant.BlkioKey,
+	"quotaTurbo":     constant.QuotaAnnotationKey,
+	"cpi":            constant.CpiAnnotationKey,
+}
+
+var qosClasses = map[string]struct{}{
+	string(corev1.PodQOSGuaranteed): {},
+	string(corev1.PodQOSBurstable):  {},
+	string(corev1.PodQOSBestEffort): {},
+}
+
+// cronJobKind is never the controller of the pods, which are managed by the jobs created by the CronJob
+const cronJobKind = "CronJob"
+
+// RuleMatch selects the pods satisfying all the configured conditions, the empty match selects all pods
+type RuleMatch struct {
+	Namespaces         []string              `json:"namespaces,omitempty"`
+	LabelSelector      *metav1.LabelSelector `json:"labelSelector,omitempty"`
+	PriorityClassNames []string              `json:"priorityClassNames,omitempty"`
+	QOSClasses         []string              `json:"qosClasses,omitempty"`
+	// OwnerKinds are the kinds of the controllers managing the pods directly, such as Deployment and Job.
+	// The owners of the controllers are not resolved, so the pods of the CronJob are matched by Job.
+	OwnerKinds []string `json:"ownerKinds,omitempty"`
+}
+
+// Rule sets the values of the pods selected by the match, the values annotated by the pods take precedence
+type Rule struct {
+	Name  string            `json:"name"`
+	Match RuleMatch         `json:"match"`
+	Set   map[string]string `json:"set"`
+}
+
+type compiledRule struct {
+	*Rule
+	selector labels.Selector
+	// annotations are the annotations set by the rule
+	annotations map[string]string
+}
+
+// Rules are the pod rules matched in order, only the first matched rule is applied to the pod
+type Rules struct {
+	rules []*compiledRule
+}
+
+// NewRules checks and compiles the pod rules
+func NewRules(rules []Rule) (*Rules, error) {
+	var (
+		compiled = make([]*compiledRule, 0, len(rules))
+		names    = make(map[string]struct{}, len(rules))
+	)
+	for i := range rules {
+		r, err := compileRule(&rules[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pod rule %v: %v", rules[i].Name, err)
+		}
+		if _, existed := names[r.Name]; existed {
+			return nil, fmt.Errorf("duplicate pod rule %v", r.Name)
+		}
+		names[r.Name] = struct{}{}
+		compiled = append(compiled, r)
+	}
+	return &Rules{rules: compiled}, nil
+}
+
+func compileRule(rule *Rule) (*compiledRule, error) {
+	if rule.Name == "" {
+		return nil, fmt.Errorf("empty name")
+	}
+	if len(rule.Set) == 0 {
+		return nil, fmt.Errorf("no value is set")
+	}
+	var r = &compiledRule{Rule: rule, annotations: make(map[string]string, len(rule.Set))}
+	for name, value := range rule.Set {
+		key, ok := settingKeys[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported value %v", name)
+		}
+		if err := checkSetting(key, value); err != nil {
+			return nil, fmt.Errorf("invalid %v: %v", name, err)
+		}
+		r.annotations[key] = value
+	}
+	if contains(rule.Match.OwnerKinds, cronJobKind) {
+		return nil, fmt.Errorf("owner kind %v never manages the pods directly, use Job instead", cronJobKind)
+	}
+	for _, class := range rule.Match.QOSClasses {
+		if _, ok := qosClasses[class]; !ok {
+			return nil, fmt.Errorf("invalid qos class %v", class)
+		}
+	}
+	if rule.Match.LabelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(rule.Match.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector: %v", err)
+		}
+		r.selector = selector
+	}
+	return r, nil
+}
+
+// checkSetting checks the values which are not checked by the services when they are applied
+func checkSetting(key, value string) error {
+	switch key {
+	case constant.PriorityAnnotationKey:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%q is neither true nor false", value)
+		}
+	case constant.PriorityTierAnnotationKey:
+		_, err := typedef.ParsePriority(value)
+		return err
+	case constant.QuotaBurstAnnotationKey:
+		if v, err := strconv.ParseInt(value, 10, 64); err != nil || v < 0 {
+			return fmt.Errorf("%q is not a non-negative integer", value)
+		}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *compiledRule) match(pod *typedef.PodInfo) bool {
+	m := &r.Match
+	if len(m.Namespaces) != 0 && !contains(m.Namespaces, pod.Namespace) {
+		return false
+	}
+	if r.selector != nil && !r.selector.Matches(labels.Set(pod.Labels)) {
+		return false
+	}
+	if len(m.PriorityClassNames) != 0 && !contains(m.PriorityClassNames, pod.PriorityClassName) {
+		return false
+	}
+	if len(m.QOSClasses) != 0 && !contains(m.QOSClasses, pod.QOSClass) {
+		return false
+	}
+	if len(m.OwnerKinds) != 0 && !contains(m.OwnerKinds, pod.ControllerKind) {
+		return false
+	}
+	return true
+}
+
+// annotated returns true if the pod sets the value of the annotation by itself
+func annotated(pod *typedef.PodInfo, key string) bool {
+	if _, ok := pod.Annotations[key]; ok {
+		return true
+	}
+	switch key {
+	case constant.PriorityAnnotationKey:
+		_, ok := pod.Labels[key]
+		return ok
+	case constant.PriorityTierAnnotationKey:
+		// the priority is also set by the labels and the preemptable annotation
+		for _, k := range []string{constant.PriorityTierAnnotationKey, constant.PriorityAnnotationKey} {
+			if _, ok := pod.Labels[k]; ok {
+				return true
+			}
+		}
+		_, ok := pod.Annotations[constant.PriorityAnnotationKey]
+		return ok
+	}
+	return false
+}
+
+// Apply sets the values of the first rule matching the pod, the values set by the rules before are removed
+func (rules *Rules) Apply(pod *typedef.PodInfo) {
+	if pod == nil {
+		return
+	}
+	// the annotations may be shared with the raw pod, so they are copied before being changed
+	var annotations = make(map[string]string, len(pod.Annotations))
+	for k, v := range pod.Annotations {
+		if ruleValue, ok := pod.RuleValues[k]; !ok || ruleValue != v {
+			annotations[k] = v
+		}
+	}
+	pod.Annotations, pod.Rule, pod.RuleValues = annotations, "", nil
+	if rules == nil {
+		return
+	}
+	for _, r := range rules.rules {
+		if !r.match(pod) {
+			continue
+		}
+		pod.Rule = r.Name
+		for key, value := range r.annotations {
+			if annotated(pod, key) {
+				continue
+			}
+			if pod.RuleValues == nil {
+				pod.RuleValues = make(map[string]string, len(r.annotations))
+			}
+			pod.Annotations[key], pod.RuleValues[key] = value, value
+		}
+		return
+	}
+}
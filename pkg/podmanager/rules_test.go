@@ -0,0 +1,196 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file tests the pod rules
+
+package podmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/core/typedef"
+)
+
+var testRules = []Rule{
+	{
+		Name:  "system",
+		Match: RuleMatch{Namespaces: []string{"kube-system"}},
+		Set:   map[string]string{"priority": "latency-critical"},
+	},
+	{
+		Name:  "jobs",
+		Match: RuleMatch{OwnerKinds: []string{"Job"}, QOSClasses: []string{"BestEffort"}},
+		Set:   map[string]string{"priority": "best-effort", "cacheLimit": "max"},
+	},
+	{
+		Name: "batch",
+		Match: RuleMatch{
+			LabelSelector:      &metav1.LabelSelector{MatchLabels: map[string]string{"app": "spark"}},
+			PriorityClassNames: []string{"low"},
+		},
+		Set: map[string]string{"priority": "batch", "quotaBurstTime": "10000"},
+	},
+}
+
+// TestNewRules tests checking the pod rules
+func TestNewRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr bool
+	}{
+		{
+			name: "TC1-valid rule",
+			rule: testRules[2],
+		},
+		{
+			name:    "TC2-empty name",
+			rule:    Rule{Set: map[string]string{"priority": "batch"}},
+			wantErr: true,
+		},
+		{
+			name:    "TC3-no value",
+			rule:    Rule{Name: "test"},
+			wantErr: true,
+		},
+		{
+			name:    "TC4-unsupported value",
+			rule:    Rule{Name: "test", Set: map[string]string{"cpuShares": "2"}},
+			wantErr: true,
+		},
+		{
+			name:    "TC5-invalid priority",
+			rule:    Rule{Name: "test", Set: map[string]string{"priority": "low"}},
+			wantErr: true,
+		},
+		{
+			name:    "TC6-invalid quota burst time",
+			rule:    Rule{Name: "test", Set: map[string]string{"quotaBurstTime": "-1"}},
+			wantErr: true,
+		},
+		{
+			name: "TC7-invalid qos class",
+			rule: Rule{Name: "test", Match: RuleMatch{QOSClasses: []string{"Best-Effort"}},
+				Set: map[string]string{"priority": "batch"}},
+			wantErr: true,
+		},
+		{
+			name: "TC8-invalid label selector",
+			rule: Rule{Name: "test", Match: RuleMatch{LabelSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Like"}}}},
+				Set: map[string]string{"priority": "batch"}},
+			wantErr: true,
+		},
+		{
+			name: "TC9-cronjob never owns the pods",
+			rule: Rule{Name: "test", Match: RuleMatch{OwnerKinds: []string{"CronJob"}},
+				Set: map[string]string{"priority": "batch"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRules([]Rule{tt.rule})
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+	_, err := NewRules([]Rule{testRules[0], testRules[0]})
+	assert.Error(t, err)
+}
+
+// TestRules_Apply tests the first matched rule sets the values not annotated by the pod
+func TestRules_Apply(t *testing.T) {
+	rules, err := NewRules(testRules)
+	assert.NoError(t, err)
+	tests := []struct {
+		name     string
+		pod      *typedef.PodInfo
+		wantRule string
+		want     map[string]string
+	}{
+		{
+			name:     "TC1-match namespace",
+			pod:      &typedef.PodInfo{Namespace: "kube-system", Labels: map[string]string{"app": "spark"}},
+			wantRule: "system",
+			want:     map[string]string{constant.PriorityTierAnnotationKey: "latency-critical"},
+		},
+		{
+			name: "TC2-match the owner kind",
+			pod: &typedef.PodInfo{ControllerKind: "Job", ControllerName: "backup-28312345",
+				QOSClass: string(corev1.PodQOSBestEffort)},
+			wantRule: "jobs",
+			want: map[string]string{constant.PriorityTierAnnotationKey: "best-effort",
+				constant.CacheLimitAnnotationKey: "max"},
+		},
+		{
+			name:     "TC3-owner kind not matched",
+			pod:      &typedef.PodInfo{ControllerKind: "ReplicaSet", ControllerName: "backup", QOSClass: "BestEffort"},
+			wantRule: "",
+		},
+		{
+			name: "TC4-the annotations of the pod take precedence",
+			pod: &typedef.PodInfo{PriorityClassName: "low", Labels: map[string]string{"app": "spark"},
+				Annotations: map[string]string{constant.PriorityAnnotationKey: "false"}},
+			wantRule: "batch",
+			want:     map[string]string{constant.QuotaBurstAnnotationKey: "10000"},
+		},
+		{
+			name:     "TC5-label selector not matched",
+			pod:      &typedef.PodInfo{PriorityClassName: "low", Labels: map[string]string{"app": "flink"}},
+			wantRule: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules.Apply(tt.pod)
+			assert.Equal(t, tt.wantRule, tt.pod.Rule)
+			assert.Equal(t, tt.want, tt.pod.RuleValues)
+			for k, v := range tt.want {
+				assert.Equal(t, v, tt.pod.Annotations[k])
+			}
+		})
+	}
+}
+
+// TestPodManager_SetRules tests the values set by the old rules are replaced by the new rules
+func TestPodManager_SetRules(t *testing.T) {
+	annotations := map[string]string{constant.CpiAnnotationKey: "true"}
+	pod := &typedef.PodInfo{UID: "testPod1", Namespace: "kube-system", Annotations: annotations}
+	pub := &recordPublisher{}
+	manager := NewPodManager(pub)
+	manager.Pods.addPod(pod)
+
+	rules, err := NewRules(testRules[:1])
+	assert.NoError(t, err)
+	manager.SetRules(rules)
+	assert.Len(t, pub.events, 1)
+	got := manager.Pods.getPod(pod.UID)
+	assert.Equal(t, "system", got.Rule)
+	assert.Equal(t, typedef.LatencyCritical, got.Priority())
+	// the annotations shared with the raw pod are never changed
+	assert.Len(t, annotations, 1)
+
+	// applying the same rules publishes nothing
+	manager.SetRules(rules)
+	assert.Len(t, pub.events, 1)
+
+	manager.SetRules(nil)
+	assert.Len(t, pub.events, 2)
+	got = manager.Pods.getPod(pod.UID)
+	assert.Equal(t, "", got.Rule)
+	assert.Equal(t, annotations, got.Annotations)
+	assert.Equal(t, typedef.Online, got.Priority())
+}
@@ -0,0 +1,57 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file reports the liveness and readiness of the agent
+
+package rubik
+
+import (
+	"isula.org/rubik/pkg/admin"
+	"isula.org/rubik/pkg/api"
+)
+
+const (
+	informerCheck = "informer"
+	servicesCheck = "services"
+)
+
+// LivenessChecks returns the checks failed if the informer stops watching pods
+// or any service keeps panicking, in which case rubik should be restarted
+func (a *Agent) LivenessChecks() []admin.HealthCheck {
+	var informerErr error
+	if checker, ok := a.informer.(api.HealthChecker); ok {
+		informerErr = checker.Healthy()
+	}
+	return []admin.HealthCheck{
+		newHealthCheck(informerCheck, informerErr, "watching pods"),
+		newHealthCheck(servicesCheck, a.servicesManager.CheckRestartBudget(), "within restart budget"),
+	}
+}
+
+// ReadinessChecks returns the checks passed once the pods are synchronized and all services are pre-started,
+// rubik is not ready if it is not alive
+func (a *Agent) ReadinessChecks() []admin.HealthCheck {
+	checks := a.LivenessChecks()
+	if checks[0].Healthy && !a.podManager.Synced() {
+		checks[0] = admin.HealthCheck{Name: informerCheck, Message: "pods are not synchronized"}
+	}
+	if checks[1].Healthy && !a.servicesManager.PreStarted() {
+		checks[1] = admin.HealthCheck{Name: servicesCheck, Message: "services are not pre-started"}
+	}
+	return checks
+}
+
+func newHealthCheck(name string, err error, message string) admin.HealthCheck {
+	if err != nil {
+		return admin.HealthCheck{Name: name, Message: err.Error()}
+	}
+	return admin.HealthCheck{Name: name, Message: message, Healthy: true}
+}
@@ -22,5 +22,4 @@ import (
 	_ "isula.org/rubik/pkg/services/preemption"
 	_ "isula.org/rubik/pkg/services/quotaburst"
 	_ "isula.org/rubik/pkg/services/quotaturbo"
-	_ "isula.org/rubik/pkg/version"
 )
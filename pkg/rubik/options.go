@@ -0,0 +1,98 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file parses the command-line options of rubik
+
+package rubik
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/config"
+	"isula.org/rubik/pkg/lib/kubernetes"
+)
+
+// options is the command-line options of rubik
+type options struct {
+	// configFile is the path of the configuration file
+	configFile string
+	// logLevel overrides the log level of the configuration file if it is not empty
+	logLevel string
+	// validate checks the configuration and exits without running the agent
+	validate bool
+	// version prints the version and exits
+	version bool
+}
+
+// defaultOptions returns the options used when no argument is passed
+func defaultOptions() *options {
+	return &options{configFile: constant.ConfigFile}
+}
+
+// parseOptions parses the arguments excluding the program name, the usage is printed to output on error
+func parseOptions(args []string, output io.Writer) (*options, error) {
+	opts := defaultOptions()
+	flags := flag.NewFlagSet("rubik", flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.StringVar(&opts.configFile, "config", opts.configFile, "the path of the configuration file")
+	flags.StringVar(&opts.logLevel, "log-level", "",
+		"override the log level of the configuration file, one of debug, info, warn and error")
+	flags.BoolVar(&opts.validate, "validate", false,
+		"check the configuration and the kernel capabilities of the enabled features, then exit")
+	flags.BoolVar(&opts.version, "version", false, "print the version and exit")
+	flags.BoolVar(&opts.version, "v", false, "shorthand for -version")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() != 0 {
+		err := fmt.Errorf("unexpected arguments: %v", flags.Args())
+		fmt.Fprintln(output, err)
+		flags.Usage()
+		return nil, err
+	}
+	if opts.configFile == "" {
+		err := fmt.Errorf("the path of the configuration file cannot be empty")
+		fmt.Fprintln(output, err)
+		return nil, err
+	}
+	return opts, nil
+}
+
+// loadConfig loads the configuration file, applies the overrides selecting the node
+// and the overrides of the command-line options
+func (opts *options) loadConfig() (*config.Config, error) {
+	c := config.NewConfig(config.JSON)
+	if err := c.LoadConfig(opts.configFile); err != nil {
+		return nil, fmt.Errorf("failed to load agent config: %v", err)
+	}
+	if err := applyNodeOverrides(c); err != nil {
+		return nil, err
+	}
+	if opts.logLevel != "" {
+		c.Agent.LogLevel = opts.logLevel
+	}
+	return c, nil
+}
+
+// applyNodeOverrides applies the overrides of the service configurations selecting the node where rubik runs
+func applyNodeOverrides(c *config.Config) error {
+	nodeName := os.Getenv(constant.NodeNameEnvKey)
+	if err := c.ApplyOverrides(nodeName, func() (map[string]string, error) {
+		return kubernetes.NodeLabels(nodeName)
+	}); err != nil {
+		return fmt.Errorf("failed to apply overrides: %v", err)
+	}
+	return nil
+}
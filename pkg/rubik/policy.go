@@ -0,0 +1,192 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file applies the RubikPolicy selecting the node to the configuration
+
+package rubik
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+
+	"isula.org/rubik/pkg/common/log"
+	"isula.org/rubik/pkg/config"
+	"isula.org/rubik/pkg/lib/kubernetes"
+)
+
+// policyResyncPeriod is the period to handle all policies again, which also finds the changes of the node labels
+const policyResyncPeriod = 5 * time.Minute
+
+const (
+	// reasonApplied means the policy is applied on the node
+	reasonApplied = "Applied"
+	// reasonFailed means the configuration with the policy fails to be applied on the node
+	reasonFailed = "ApplyFailed"
+)
+
+// policyManager watches RubikPolicy and merges the policies selecting the node into the configuration
+type policyManager struct {
+	sync.Mutex
+	client     *kubernetes.PolicyClient
+	nodeName   string
+	nodeLabels config.NodeLabelsFunc
+	store      cache.Store
+	// changed receives the notifications of the changes of the policies
+	changed chan struct{}
+	// reload receives the requests to reload the configuration when the selected policies change
+	reload chan<- struct{}
+	// selected is the policies selecting the node sorted by name
+	selected []*kubernetes.RubikPolicy
+	// reported is the names of the policies whose status contains the node
+	reported map[string]struct{}
+}
+
+// newPolicyManager returns the policy manager of the node
+func newPolicyManager(client *kubernetes.PolicyClient, nodeName string, nodeLabels config.NodeLabelsFunc,
+	reload chan<- struct{}) *policyManager {
+	return &policyManager{
+		client:     client,
+		nodeName:   nodeName,
+		nodeLabels: nodeLabels,
+		store:      cache.NewStore(cache.MetaNamespaceKeyFunc),
+		changed:    make(chan struct{}, 1),
+		reload:     reload,
+		reported:   make(map[string]struct{}),
+	}
+}
+
+// run watches the policies until the context is done
+func (m *policyManager) run(ctx context.Context) {
+	notify := func() { triggerReload(m.changed) }
+	store, controller := cache.NewInformer(m.client.ListWatch(), &unstructured.Unstructured{}, policyResyncPeriod,
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(interface{}) { notify() },
+			UpdateFunc: func(interface{}, interface{}) { notify() },
+			DeleteFunc: func(interface{}) { notify() },
+		})
+	m.store = store
+	go controller.Run(ctx.Done())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.changed:
+			m.refresh()
+		}
+	}
+}
+
+// refresh selects the policies by the labels of the node and requests to reload if the selected policies change
+func (m *policyManager) refresh() {
+	labels, err := m.nodeLabels()
+	if err != nil {
+		log.Errorf("failed to get labels of node %v: %v", m.nodeName, err)
+		return
+	}
+	var selected []*kubernetes.RubikPolicy
+	for _, obj := range m.store.List() {
+		p, err := kubernetes.PolicyFromUnstructured(obj)
+		if err != nil {
+			log.Errorf("failed to handle policy: %v", err)
+			continue
+		}
+		if p.Selects(labels) {
+			selected = append(selected, p)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Name < selected[j].Name })
+
+	m.Lock()
+	changed := !samePolicies(m.selected, selected)
+	m.selected = selected
+	m.Unlock()
+	if changed {
+		log.Infof("policies selecting node %v change", m.nodeName)
+		triggerReload(m.reload)
+	}
+}
+
+// samePolicies returns true if the policies have the same names and generations in order
+func samePolicies(a, b []*kubernetes.RubikPolicy) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Generation != b[i].Generation {
+			return false
+		}
+	}
+	return true
+}
+
+// apply merges the selected policies into the configuration in order of name,
+// so the later policy replaces the configuration of the same service. The merged policies are returned.
+func (m *policyManager) apply(c *config.Config) ([]*kubernetes.RubikPolicy, error) {
+	m.Lock()
+	policies := m.selected
+	m.Unlock()
+	for _, p := range policies {
+		if len(p.Spec.Services) == 0 {
+			continue
+		}
+		fields, err := c.ParseConfig(p.Spec.Services)
+		if err != nil {
+			return policies, fmt.Errorf("invalid policy %v: %v", p.Name, err)
+		}
+		if err := c.MergeServiceConfig(fields); err != nil {
+			return policies, fmt.Errorf("invalid policy %v: %v", p.Name, err)
+		}
+	}
+	// the services of the policies may carry the overrides as well
+	if err := c.ApplyOverrides(m.nodeName, m.nodeLabels); err != nil {
+		return policies, fmt.Errorf("failed to apply overrides of policies: %v", err)
+	}
+	return policies, nil
+}
+
+// report writes the result of applying the policies to their status,
+// and removes the node from the status of the policies no longer selecting it
+func (m *policyManager) report(policies []*kubernetes.RubikPolicy, applyErr error) {
+	cond := metav1.Condition{
+		Type:    kubernetes.PolicyConditionApplied,
+		Status:  metav1.ConditionTrue,
+		Reason:  reasonApplied,
+		Message: "the policy is applied",
+	}
+	if applyErr != nil {
+		cond.Status, cond.Reason, cond.Message = metav1.ConditionFalse, reasonFailed, applyErr.Error()
+	}
+	reported := make(map[string]struct{}, len(policies))
+	for _, p := range policies {
+		cond.ObservedGeneration = p.Generation
+		if err := m.client.UpdateNodeStatus(p.Name, m.nodeName, p.Generation, &cond); err != nil {
+			log.Errorf("failed to update status of policy %v: %v", p.Name, err)
+		}
+		reported[p.Name] = struct{}{}
+	}
+	for name := range m.reported {
+		if _, ok := reported[name]; ok {
+			continue
+		}
+		if err := m.client.UpdateNodeStatus(name, m.nodeName, 0, nil); err != nil {
+			log.Errorf("failed to remove node %v from status of policy %v: %v", m.nodeName, name, err)
+			reported[name] = struct{}{}
+		}
+	}
+	m.reported = reported
+}
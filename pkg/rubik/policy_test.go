@@ -0,0 +1,172 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file tests applying the RubikPolicy
+
+package rubik
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"isula.org/rubik/pkg/config"
+	"isula.org/rubik/pkg/lib/kubernetes"
+)
+
+// fakePolicies stores the policies in memory, the status applied by the only node replaces the status
+type fakePolicies struct {
+	dynamic.ResourceInterface
+	objects map[string]*unstructured.Unstructured
+}
+
+func (f *fakePolicies) Get(ctx context.Context, name string, options metav1.GetOptions,
+	subresources ...string) (*unstructured.Unstructured, error) {
+	obj, ok := f.objects[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(kubernetes.PolicyGVR.GroupResource(), name)
+	}
+	return obj.DeepCopy(), nil
+}
+
+func (f *fakePolicies) Patch(ctx context.Context, name string, pt types.PatchType, data []byte,
+	options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	obj, ok := f.objects[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(kubernetes.PolicyGVR.GroupResource(), name)
+	}
+	var applied unstructured.Unstructured
+	if err := applied.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	obj.Object["status"] = applied.Object["status"]
+	return obj.DeepCopy(), nil
+}
+
+// status returns the status of the node in the policy
+func (f *fakePolicies) status(t *testing.T, name, nodeName string) *kubernetes.NodePolicyStatus {
+	p, err := kubernetes.PolicyFromUnstructured(f.objects[name])
+	assert.NoError(t, err)
+	for _, n := range p.Status.Nodes {
+		if n.NodeName == nodeName {
+			return &n
+		}
+	}
+	return nil
+}
+
+func newPolicy(name string, generation int64, nodeSelector, services map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": kubernetes.PolicyGVR.GroupVersion().String(),
+		"kind":       "RubikPolicy",
+		"spec":       map[string]interface{}{"nodeSelector": nodeSelector, "services": services},
+	}}
+	obj.SetName(name)
+	obj.SetGeneration(generation)
+	return obj
+}
+
+// TestPolicyManager tests selecting, applying the policies and reporting the results
+func TestPolicyManager(t *testing.T) {
+	const nodeName = "node-1"
+	var (
+		fake   = &fakePolicies{objects: make(map[string]*unstructured.Unstructured)}
+		reload = make(chan struct{}, 1)
+		labels = map[string]string{"pool": "batch"}
+		m      = newPolicyManager(kubernetes.NewPolicyClient(fake), nodeName, func() (map[string]string, error) {
+			return labels, nil
+		}, reload)
+		reloaded = func() bool {
+			select {
+			case <-reload:
+				return true
+			default:
+				return false
+			}
+		}
+		add = func(obj *unstructured.Unstructured) {
+			fake.objects[obj.GetName()] = obj
+			assert.NoError(t, m.store.Update(obj))
+		}
+		loadConfig = func() *config.Config {
+			c := config.NewConfig(config.JSON)
+			assert.NoError(t, c.LoadConfigData([]byte(`{"preemption": {"resource": ["cpu"]}, "psi": {"interval": 10}}`)))
+			return c
+		}
+	)
+
+	// TC1: the policies selecting the node are applied in order of name
+	add(newPolicy("b-batch", 1, map[string]interface{}{"pool": "batch"}, map[string]interface{}{
+		"preemption": map[string]interface{}{"resource": []interface{}{"cpu", "memory"}},
+		"psi": map[string]interface{}{
+			"interval":  int64(20),
+			"overrides": []interface{}{map[string]interface{}{"nodeName": nodeName, "config": map[string]interface{}{"interval": int64(30)}}},
+		},
+	}))
+	add(newPolicy("a-all", 1, nil, map[string]interface{}{
+		"preemption": map[string]interface{}{"resource": []interface{}{"net"}},
+	}))
+	add(newPolicy("c-online", 1, map[string]interface{}{"pool": "online"}, map[string]interface{}{
+		"psi": map[string]interface{}{"interval": int64(40)},
+	}))
+	m.refresh()
+	assert.True(t, reloaded())
+	c := loadConfig()
+	policies, err := m.apply(c)
+	assert.NoError(t, err)
+	assert.Len(t, policies, 2)
+	services := c.UnwrapServiceConfig()
+	assert.Equal(t, []interface{}{"cpu", "memory"}, services["preemption"].(map[string]interface{})["resource"])
+	assert.Equal(t, "30", fmt.Sprint(services["psi"].(map[string]interface{})["interval"]))
+	m.report(policies, nil)
+	assert.Equal(t, metav1.ConditionTrue, fake.status(t, "a-all", nodeName).Conditions[0].Status)
+	assert.Equal(t, int64(1), fake.status(t, "b-batch", nodeName).ObservedGeneration)
+	assert.Nil(t, fake.status(t, "c-online", nodeName))
+
+	// TC2: nothing changes
+	m.refresh()
+	assert.False(t, reloaded())
+
+	// TC3: the invalid policy fails the configuration
+	add(newPolicy("a-all", 2, nil, map[string]interface{}{"agent": map[string]interface{}{}}))
+	m.refresh()
+	assert.True(t, reloaded())
+	policies, err = m.apply(loadConfig())
+	assert.Error(t, err)
+	m.report(policies, err)
+	status := fake.status(t, "a-all", nodeName)
+	assert.Equal(t, int64(2), status.ObservedGeneration)
+	assert.Equal(t, metav1.ConditionFalse, status.Conditions[0].Status)
+	assert.Contains(t, status.Conditions[0].Message, "a-all")
+
+	// TC4: the node is removed from the status of the policy no longer selecting it
+	labels = map[string]string{"pool": "online"}
+	m.refresh()
+	assert.True(t, reloaded())
+	policies, err = m.apply(loadConfig())
+	assert.Error(t, err)
+	assert.NoError(t, m.store.Delete(fake.objects["a-all"]))
+	m.refresh()
+	c = loadConfig()
+	policies, err = m.apply(c)
+	assert.NoError(t, err)
+	m.report(policies, err)
+	assert.Nil(t, fake.status(t, "b-batch", nodeName))
+	assert.NotNil(t, fake.status(t, "c-online", nodeName))
+	assert.Equal(t, "40", fmt.Sprint(c.UnwrapServiceConfig()["psi"].(map[string]interface{})["interval"]))
+}
@@ -0,0 +1,117 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements the periodic reconciliation of the settings of the pods
+
+package rubik
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"isula.org/rubik/pkg/common/audit"
+	"isula.org/rubik/pkg/common/metrics"
+	"isula.org/rubik/pkg/core/publisher"
+	"isula.org/rubik/pkg/core/typedef"
+	"isula.org/rubik/pkg/lib/kubernetes"
+	"isula.org/rubik/pkg/services"
+	"isula.org/rubik/pkg/services/helper"
+)
+
+const (
+	// the settings of the pods are reconciled every reconcileInterval seconds, 0 disables the reconciliation
+	minReconcileInterval = 10
+	maxReconcileInterval = 86400
+	// reasonCgroupDrift is the reason of the event on the pod whose settings are changed by others
+	reasonCgroupDrift = "CgroupDrift"
+)
+
+// checkReconcileInterval checks the interval in seconds of reconciling the settings of the pods
+func checkReconcileInterval(seconds int) error {
+	if seconds != 0 && (seconds < minReconcileInterval || seconds > maxReconcileInterval) {
+		return fmt.Errorf("invalid reconcile interval: %d (valid values are 0 or %d-%d)",
+			seconds, minReconcileInterval, maxReconcileInterval)
+	}
+	return nil
+}
+
+// SetReconcileInterval sets the interval in seconds of reconciling the settings of the pods,
+// it takes effect when the manager starts
+func (manager *ServiceManager) SetReconcileInterval(seconds int) error {
+	if err := checkReconcileInterval(seconds); err != nil {
+		return err
+	}
+	manager.Lock()
+	manager.reconcileInterval = time.Duration(seconds) * time.Second
+	manager.Unlock()
+	return nil
+}
+
+// reconcile queues the reconciliation to the services implementing Reconciler, the services restore
+// the settings of the pods changed by others in order with the pod events they handle
+func (manager *ServiceManager) reconcile() {
+	// the settings are never written in dry-run mode, so every setting of the services would be taken as drift
+	if audit.DryRun() {
+		return
+	}
+	manager.RLock()
+	if manager.Viewer == nil {
+		manager.RUnlock()
+		return
+	}
+	var queues = make([]*publisher.Queue, 0, len(manager.RunningServices))
+	for name, s := range manager.RunningServices {
+		// the paused or stopped services do not handle the pods
+		if _, ok := s.(services.Reconciler); !ok || manager.states[name] != "" {
+			continue
+		}
+		queues = append(queues, manager.queue(name, s))
+	}
+	manager.RUnlock()
+	for _, q := range queues {
+		q.Push(typedef.ReconcileEvent{})
+	}
+}
+
+// reconcileService restores the settings of the pods changed by others for the service,
+// it is called by the queue of the service without holding the manager lock
+func (manager *ServiceManager) reconcileService(name string, s services.Service, r services.Reconciler) {
+	if audit.DryRun() || manager.Viewer == nil {
+		return
+	}
+	for _, pod := range ownedViewer(manager.Viewer, name).ListPodsWithOptions() {
+		// the service paused, stopped or replaced while reconciling does not wait for the remaining pods
+		if state, current := manager.serviceState(name, s); !current || state != "" {
+			return
+		}
+		drifts, err := r.Reconcile(pod)
+		for _, drift := range drifts {
+			reportDrift(name, pod, drift)
+		}
+		if err != nil {
+			managerLog.WithPod(pod.UID).WithService(name).Warnf("service %v failed to reconcile pod %v: %v",
+				name, pod.Name, err)
+			metrics.ServiceErrors.Inc(name, "reconcile")
+		}
+	}
+}
+
+// reportDrift reports the setting of the pod changed by others and restored by the service
+func reportDrift(service string, pod *typedef.PodInfo, drift *helper.Drift) {
+	managerLog.WithPod(pod.UID).WithService(service).Warnf("service %v restores the drift of pod %v: %v",
+		service, pod.Name, drift)
+	metrics.CgroupDrifts.Inc(service, drift.File)
+	kubernetes.RecordEvent(kubernetes.PodReference(pod.Namespace, pod.Name, pod.UID), corev1.EventTypeWarning,
+		reasonCgroupDrift, "%v is changed to %q by others and restored to %q by %v",
+		drift.File, drift.Actual, drift.Expected, service)
+}
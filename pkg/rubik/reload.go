@@ -0,0 +1,158 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements the hot reload of the configuration
+
+package rubik
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"isula.org/rubik/pkg/common/log"
+	"isula.org/rubik/pkg/common/util"
+	"isula.org/rubik/pkg/config"
+)
+
+// configWatchInterval is the interval to check whether the configuration file changes
+const configWatchInterval = 10 * time.Second
+
+// triggerReload requests to reload the configuration without blocking
+func triggerReload(reload chan<- struct{}) {
+	select {
+	case reload <- struct{}{}:
+	default:
+		// a reload is already pending
+	}
+}
+
+// readConfigFiles reads the configuration file and the fragments in the drop-in directory
+func readConfigFiles(path string) ([]byte, error) {
+	files, err := config.ConfigFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	for _, file := range files {
+		data, err := util.ReadSmallFile(file)
+		if err != nil {
+			return nil, err
+		}
+		// the file name is included so that renaming a fragment is also a change
+		buf.WriteString(file)
+		buf.WriteByte(0)
+		buf.Write(data)
+		buf.WriteByte(0)
+	}
+	return buf.Bytes(), nil
+}
+
+// watchConfig triggers reload when the content of the configuration file or the drop-in fragments changes
+func watchConfig(ctx context.Context, path string, reload chan<- struct{}) {
+	last, err := readConfigFiles(path)
+	if err != nil {
+		log.Warnf("failed to read config file %v: %v", path, err)
+	}
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		data, err := readConfigFiles(path)
+		if err != nil {
+			log.Warnf("failed to read config file %v: %v", path, err)
+			return
+		}
+		if bytes.Equal(last, data) {
+			return
+		}
+		last = data
+		log.Infof("config file %v changes", path)
+		triggerReload(reload)
+	}, configWatchInterval)
+}
+
+// reloadConfig loads the configuration file again and applies it to the services
+func (a *Agent) reloadConfig() error {
+	c, err := a.options.loadConfig()
+	if err != nil {
+		return err
+	}
+	if a.policies == nil {
+		return a.applyConfig(c)
+	}
+	policies, err := a.policies.apply(c)
+	if err == nil {
+		err = a.applyConfig(c)
+	}
+	a.policies.report(policies, err)
+	return err
+}
+
+// applyConfig applies the reloaded configuration to the services
+func (a *Agent) applyConfig(c *config.Config) error {
+	// only the enabled and optional features of agent configuration can be changed at runtime
+	agentConf := *a.Config().Agent
+	agentConf.EnabledFeatures = c.Agent.EnabledFeatures
+	agentConf.OptionalFeatures = c.Agent.OptionalFeatures
+	if !reflect.DeepEqual(&agentConf, c.Agent) {
+		log.Warnf("agent configuration except enabledFeatures and optionalFeatures takes effect after rubik restarts")
+		c.Agent = &agentConf
+	}
+	rules, err := parsePodRules(c)
+	if err != nil {
+		return err
+	}
+	a.servicesManager.SetOptionalFeatures(c.Agent.OptionalFeatures)
+	err = a.servicesManager.Reload(c.Agent.EnabledFeatures, c.UnwrapServiceConfig(), c)
+	var partial *ReloadError
+	if err != nil && !errors.As(err, &partial) {
+		a.servicesManager.SetOptionalFeatures(a.Config().Agent.OptionalFeatures)
+		return err
+	}
+	if partial != nil {
+		// the configuration in use is kept, so that it matches the running services
+		// and the services failed are changed again by the next reload
+		c = partialConfig(c, partial)
+	}
+	a.podManager.SetRules(rules)
+	a.configLock.Lock()
+	a.config = c
+	a.configLock.Unlock()
+	if partial != nil {
+		log.Warnf("agent reloaded partially with config:\n%s", c.String())
+		return err
+	}
+	log.Infof("agent reloaded with config:\n%s", c.String())
+	return nil
+}
+
+// partialConfig returns the copy of the configuration whose enabled features and service configurations
+// are replaced by the ones in use after the reload failed partially
+func partialConfig(c *config.Config, partial *ReloadError) *config.Config {
+	var (
+		applied   = *c
+		agentConf = *c.Agent
+	)
+	agentConf.EnabledFeatures = partial.Features
+	applied.Agent = &agentConf
+	applied.Fields = make(map[string]interface{}, len(c.Fields))
+	for key, value := range c.Fields {
+		applied.Fields[key] = value
+	}
+	for name := range c.UnwrapServiceConfig() {
+		if conf, ok := partial.ServiceConfig[name]; ok {
+			applied.Fields[name] = conf
+		}
+	}
+	return &applied
+}
@@ -0,0 +1,78 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file tests reloading the configuration
+
+package rubik
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"isula.org/rubik/pkg/config"
+	"isula.org/rubik/pkg/core/publisher"
+	"isula.org/rubik/pkg/podmanager"
+)
+
+func loadTestConfig(t *testing.T, data string) *config.Config {
+	c := config.NewConfig(config.JSON)
+	assert.NoError(t, c.LoadConfigData([]byte(data)))
+	return c
+}
+
+// TestAgent_ApplyConfig tests the configuration in use matches the services after the reload fails partially
+func TestAgent_ApplyConfig(t *testing.T) {
+	c := loadTestConfig(t, `{"agent": {"enabledFeatures": ["fakeServiceA"]}, "fakeServiceA": {"value": 1}}`)
+	manager := NewServiceManager()
+	assert.NoError(t, manager.InitServices(c.Agent.EnabledFeatures, c.UnwrapServiceConfig(), c))
+	a := &Agent{
+		config:          c,
+		servicesManager: manager,
+		podManager:      podmanager.NewPodManager(publisher.GetPublisherFactory().GetPublisher(publisher.GENERIC)),
+	}
+	s, ok := manager.RunningServices[fakeServiceA].(*fakeService)
+	assert.True(t, ok)
+
+	// nothing is changed if the configuration is invalid
+	assert.Error(t, a.applyConfig(loadTestConfig(t,
+		`{"agent": {"enabledFeatures": ["fakeServiceA"]}, "fakeServiceA": {"value": -1}}`)))
+	assert.Equal(t, c, a.Config())
+
+	// fakeServiceA fails to be reconfigured, while fakeServiceB is started and the pod rules are applied
+	s.setConfigErr = fmt.Errorf("failed to set config")
+	err := a.applyConfig(loadTestConfig(t, `{"agent": {"enabledFeatures": ["fakeServiceA", "fakeServiceB"]},
+		"fakeServiceA": {"value": 2}, "fakeServiceB": {"value": 1},
+		"podRules": [{"name": "system", "match": {"namespaces": ["kube-system"]}, "set": {"priority": "batch"}}]}`))
+	var partial *ReloadError
+	assert.ErrorAs(t, err, &partial)
+	got := a.Config()
+	assert.Equal(t, []string{fakeServiceA, fakeServiceB}, got.Agent.EnabledFeatures)
+	assert.Equal(t, manager.serviceConfig[fakeServiceA], got.UnwrapServiceConfig()[fakeServiceA])
+	assert.Equal(t, c.UnwrapServiceConfig()[fakeServiceA], got.UnwrapServiceConfig()[fakeServiceA])
+	assert.Contains(t, got.Fields, "podRules")
+	assert.Len(t, manager.RunningServices, 2)
+
+	// the service failed is reconfigured by the next reload
+	s.setConfigErr = nil
+	assert.NoError(t, a.applyConfig(loadTestConfig(t, `{"agent": {"enabledFeatures": ["fakeServiceA", "fakeServiceB"]},
+		"fakeServiceA": {"value": 2}, "fakeServiceB": {"value": 1}}`)))
+	assert.Equal(t, 2, s.conf.Value)
+
+	// the required service failed to start is not taken as enabled
+	assert.NoError(t, a.applyConfig(loadTestConfig(t,
+		`{"agent": {"enabledFeatures": ["fakeServiceB"]}, "fakeServiceB": {"value": 1}}`)))
+	err = a.applyConfig(loadTestConfig(t, `{"agent": {"enabledFeatures": ["fakeServiceA", "fakeServiceB"]},
+		"fakeServiceA": {"value": 2, "failPreStart": true}, "fakeServiceB": {"value": 1}}`))
+	assert.ErrorAs(t, err, &partial)
+	assert.Equal(t, []string{fakeServiceB}, a.Config().Agent.EnabledFeatures)
+}
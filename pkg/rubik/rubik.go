@@ -16,31 +16,48 @@ package rubik
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 
 	"golang.org/x/sys/unix"
 
+	"isula.org/rubik/pkg/admin"
 	"isula.org/rubik/pkg/api"
+	"isula.org/rubik/pkg/common/audit"
+	"isula.org/rubik/pkg/common/checkpoint"
 	"isula.org/rubik/pkg/common/constant"
 	"isula.org/rubik/pkg/common/log"
+	"isula.org/rubik/pkg/common/metrics"
 	"isula.org/rubik/pkg/common/util"
 	"isula.org/rubik/pkg/config"
 	"isula.org/rubik/pkg/core/publisher"
+	"isula.org/rubik/pkg/core/typedef"
 	"isula.org/rubik/pkg/core/typedef/cgroup"
 	"isula.org/rubik/pkg/informer"
+	"isula.org/rubik/pkg/lib/kubernetes"
 	"isula.org/rubik/pkg/podmanager"
 	"isula.org/rubik/pkg/services"
+	"isula.org/rubik/pkg/version"
 )
 
 // Agent runs a series of rubik services and manages data
 type Agent struct {
+	// configLock protects the config which is replaced by reloading
+	configLock      sync.RWMutex
 	config          *config.Config
 	podManager      *podmanager.PodManager
 	informer        api.Informer
 	servicesManager *ServiceManager
+	// reload receives the requests to reload the configuration, such as SIGHUP
+	reload chan struct{}
+	// options is the command-line options to load the configuration
+	options *options
+	// policies merges the RubikPolicy selecting the node into the configuration if it is enabled
+	policies *policyManager
 }
 
 // NewAgent returns an agent for given configuration
@@ -51,18 +68,44 @@ func NewAgent(cfg *config.Config) (*Agent, error) {
 		cfg.UnwrapServiceConfig(), cfg); err != nil {
 		return nil, err
 	}
+	serviceManager.SetOptionalFeatures(cfg.Agent.OptionalFeatures)
+	serviceManager.SetRevertOnExit(cfg.Agent.RevertOnExit)
+	if err := serviceManager.SetReconcileInterval(cfg.Agent.ReconcileInterval); err != nil {
+		return nil, err
+	}
+	translator, err := podmanager.NewTranslator(cfg.Agent.Annotations)
+	if err != nil {
+		return nil, fmt.Errorf("invalid annotations: %v", err)
+	}
+	rules, err := parsePodRules(cfg)
+	if err != nil {
+		return nil, err
+	}
 	a := &Agent{
 		config:          cfg,
 		podManager:      podmanager.NewPodManager(publisher),
 		servicesManager: serviceManager,
+		options:         defaultOptions(),
 	}
+	a.podManager.SetTranslator(translator)
+	a.podManager.SetRules(rules)
 	return a, nil
 }
 
+// parsePodRules parses and checks the pod rules of the configuration
+func parsePodRules(c *config.Config) (*podmanager.Rules, error) {
+	var rules []podmanager.Rule
+	if err := c.UnmarshalPodRules(&rules); err != nil {
+		return nil, fmt.Errorf("failed to parse pod rules: %v", err)
+	}
+	return podmanager.NewRules(rules)
+}
+
 // Run starts and runs the agent until receiving stop signal
 func (a *Agent) Run(ctx context.Context) error {
-	log.Infof("agent run with config:\n%s", a.config.String())
-	if err := a.startInformer(ctx, a.config.Agent.InformerType); err != nil {
+	log.Infof("agent run with config:\n%s", a.Config().String())
+	a.startEventRecorder(ctx)
+	if err := a.startInformer(ctx, a.Config().Agent.InformerType); err != nil {
 		return err
 	}
 	defer a.stopInformer()
@@ -70,8 +113,130 @@ func (a *Agent) Run(ctx context.Context) error {
 		return err
 	}
 	defer a.stopServiceHandler()
-	<-ctx.Done()
-	return nil
+	a.startAdminServer(ctx)
+	a.startPolicyManager(ctx)
+	go watchConfig(ctx, a.options.configFile, a.reload)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-a.reload:
+			if err := a.reloadConfig(); err != nil {
+				log.Errorf("failed to reload config: %v", err)
+			}
+		}
+	}
+}
+
+// ListPods returns the pods cached by the agent
+func (a *Agent) ListPods() map[string]*typedef.PodInfo {
+	return a.podManager.ListPodsWithOptions()
+}
+
+// ListServices returns the status of the running services
+func (a *Agent) ListServices() []admin.ServiceStatus {
+	return a.servicesManager.ServiceStatuses()
+}
+
+// ControlService pauses, resumes, stops or starts the enabled service
+func (a *Agent) ControlService(name string, action admin.ServiceAction) error {
+	return a.servicesManager.ControlService(name, action)
+}
+
+// Config returns the configuration in use
+func (a *Agent) Config() *config.Config {
+	a.configLock.RLock()
+	defer a.configLock.RUnlock()
+	return a.config
+}
+
+// ValidateConfig checks the configuration data without applying it
+func (a *Agent) ValidateConfig(data []byte) error {
+	// the data in JSON is also accepted by the YAML parser
+	c := config.NewConfig(config.YAML)
+	if err := c.LoadConfigData(data); err != nil {
+		return err
+	}
+	if err := applyNodeOverrides(c); err != nil {
+		return err
+	}
+	if _, err := podmanager.NewTranslator(c.Agent.Annotations); err != nil {
+		return fmt.Errorf("invalid annotations: %v", err)
+	}
+	if _, err := parsePodRules(c); err != nil {
+		return err
+	}
+	return a.servicesManager.ValidateConfig(c.Agent.EnabledFeatures, c.UnwrapServiceConfig(), c)
+}
+
+// startAdminServer starts the admin API server and the optional metrics endpoint,
+// rubik keeps running without them if they fail to start
+func (a *Agent) startAdminServer(ctx context.Context) {
+	agentConf := a.Config().Agent
+	server := admin.NewServer(constant.AdminSocket, a)
+	if agentConf.EnableMetrics {
+		server.Handle(metrics.Path, metrics.Handler())
+		if agentConf.MetricsAddress != "" {
+			if err := metrics.Serve(ctx, agentConf.MetricsAddress); err != nil {
+				log.Errorf("failed to start metrics server: %v", err)
+			}
+		}
+	}
+	if err := server.Start(ctx); err != nil {
+		log.Errorf("failed to start admin server: %v", err)
+	}
+}
+
+// startHealthServer starts serving the liveness and readiness endpoints on TCP if the address is set,
+// rubik keeps running without them if they fail to start
+func (a *Agent) startHealthServer(ctx context.Context) {
+	address := a.Config().Agent.HealthAddress
+	if address == "" {
+		return
+	}
+	if err := admin.NewServer(constant.AdminSocket, a).ServeHealth(ctx, address); err != nil {
+		log.Errorf("failed to start health server: %v", err)
+	}
+}
+
+// startPolicyManager starts watching RubikPolicy if it is enabled,
+// rubik keeps running with the configuration file if it fails to start
+func (a *Agent) startPolicyManager(ctx context.Context) {
+	if !a.Config().Agent.EnablePolicy {
+		return
+	}
+	nodeName := os.Getenv(constant.NodeNameEnvKey)
+	if nodeName == "" {
+		log.Errorf("failed to start policy manager: environment variable %s is not set", constant.NodeNameEnvKey)
+		return
+	}
+	client, err := kubernetes.GetPolicyClient()
+	if err != nil {
+		log.Errorf("failed to start policy manager: %v", err)
+		return
+	}
+	a.policies = newPolicyManager(client, nodeName, func() (map[string]string, error) {
+		return kubernetes.NodeLabels(nodeName)
+	}, a.reload)
+	go a.policies.run(ctx)
+}
+
+// startEventRecorder starts emitting the Kubernetes Events of the actions of the services on pods
+func (a *Agent) startEventRecorder(ctx context.Context) {
+	if !a.Config().Agent.EnableEvents {
+		return
+	}
+	client, err := kubernetes.GetClient()
+	if err != nil {
+		log.Errorf("failed to start event recorder: %v", err)
+		return
+	}
+	recorder := kubernetes.NewEventRecorder(client.CoreV1(), os.Getenv(constant.NodeNameEnvKey))
+	kubernetes.SetEventRecorder(recorder)
+	go func() {
+		recorder.Run(ctx)
+		kubernetes.SetEventRecorder(nil)
+	}()
 }
 
 // startInformer starts informer to obtain external data
@@ -85,6 +250,8 @@ func (a *Agent) startInformer(ctx context.Context, informerName string) error {
 		return fmt.Errorf("failed to subscribe informer: %v", err)
 	}
 	a.informer = i
+	// the probes are served while the informer synchronizes the pods
+	a.startHealthServer(ctx)
 	return i.Start(ctx)
 }
 
@@ -109,24 +276,58 @@ func (a *Agent) stopServiceHandler() {
 	a.servicesManager.Stop()
 }
 
+// openCheckpoint loads the states of the services saved before rubik restarts,
+// the services start from the initial states if the checkpoint is disabled or corrupted
+func openCheckpoint(dir string) *checkpoint.Store {
+	if dir == "" {
+		return nil
+	}
+	store, err := checkpoint.Open(dir)
+	if err != nil {
+		log.Warnf("failed to load checkpoint, the services start from the initial states: %v", err)
+	}
+	return store
+}
+
 // runAgent creates and runs rubik's agent
-func runAgent(ctx context.Context) error {
+func runAgent(ctx context.Context, opts *options, reload chan struct{}) error {
 	// 1. read configuration
-	c := config.NewConfig(config.JSON)
-	if err := c.LoadConfig(constant.ConfigFile); err != nil {
-		return fmt.Errorf("failed to load agent config: %v", err)
+	c, err := opts.loadConfig()
+	if err != nil {
+		return err
 	}
 
 	// 2. enable log system
-	if err := log.InitConfig(c.Agent.LogDriver, c.Agent.LogDir, c.Agent.LogLevel, c.Agent.LogSize); err != nil {
+	if err := log.InitConfig(c.Agent.LogDriver, c.Agent.LogDir, c.Agent.LogLevel, c.Agent.LogSize,
+		log.WithFormat(c.Agent.LogFormat), log.WithModuleLevels(c.Agent.LogModuleLevels)); err != nil {
 		return fmt.Errorf("failed to initialize log: %v", err)
 	}
+	// the writes to the kernel interface files are recorded in the log directory
+	if c.Agent.EnableAudit {
+		if err := audit.Init(c.Agent.LogDir, c.Agent.AuditSize, log.Warnf); err != nil {
+			return fmt.Errorf("failed to initialize audit: %v", err)
+		}
+		defer func() {
+			log.DropError(audit.Close())
+		}()
+	}
+	if c.Agent.DryRun {
+		log.Warnf("rubik runs in dry-run mode, the changes of the services are logged but not applied")
+		audit.SetDryRun(log.Infof)
+		defer audit.SetDryRun(nil)
+	}
 
 	// 3. enable cgroup system
-	if err := cgroup.Init(cgroup.WithRoot(c.Agent.CgroupRoot), cgroup.WithDriver(c.Agent.CgroupDriver)); err != nil {
+	if err := cgroup.Init(cgroup.WithRoot(c.Agent.CgroupRoot), cgroup.WithDriver(c.Agent.CgroupDriver),
+		cgroup.WithVersion(c.Agent.CgroupVersion)); err != nil {
 		return err
 	}
 
+	// the events are queued after the queue is initialized
+	if err := publisher.InitQueue(c.Agent.EventQueueSize, c.Agent.EventOverflowPolicy); err != nil {
+		return fmt.Errorf("failed to initialize event queue: %v", err)
+	}
+
 	// 4. init service components
 	services.InitServiceComponents(defaultRubikFeature)
 
@@ -135,6 +336,9 @@ func runAgent(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to create agent: %v", err)
 	}
+	agent.reload = reload
+	agent.options = opts
+	agent.servicesManager.checkpoints = openCheckpoint(c.Agent.CheckpointDir)
 	if err := agent.Run(ctx); err != nil {
 		return fmt.Errorf("failed to start agent: %v", err)
 	}
@@ -145,10 +349,20 @@ func runAgent(ctx context.Context) error {
 func Run() int {
 	// 0. file mask permission setting and parameter checking
 	unix.Umask(constant.DefaultUmask)
-	if len(os.Args) > 1 {
-		fmt.Println("invalid parameter, the length must be greater than 1")
+	opts, err := parseOptions(os.Args[1:], os.Stderr)
+	if err == flag.ErrHelp {
+		return constant.NormalExitCode
+	}
+	if err != nil {
 		return constant.ArgumentErrorExitCode
 	}
+	if opts.version {
+		version.Print(os.Stdout)
+		return constant.NormalExitCode
+	}
+	if opts.validate {
+		return validateConfig(opts, os.Stdout)
+	}
 	// 1. apply file locks, only one rubik process can run at the same time
 	lock, err := util.LockFile(constant.LockFile)
 	defer func() {
@@ -163,23 +377,28 @@ func Run() int {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	// 2. handle external signals
-	go handleSignals(cancel)
+	reload := make(chan struct{}, 1)
+	go handleSignals(cancel, reload)
 
 	// 3. run rubik-agent
-	if err := runAgent(ctx); err != nil {
+	if err := runAgent(ctx, opts, reload); err != nil {
 		log.Errorf("failed to run rubik agent: %v", err)
 		return constant.ErrorExitCode
 	}
 	return constant.NormalExitCode
 }
 
-func handleSignals(cancel context.CancelFunc) {
+func handleSignals(cancel context.CancelFunc, reload chan<- struct{}) {
 	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, syscall.SIGTERM, syscall.SIGINT)
+	signal.Notify(signalChan, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
 	for sig := range signalChan {
-		if sig == syscall.SIGTERM || sig == syscall.SIGINT {
+		switch sig {
+		case syscall.SIGTERM, syscall.SIGINT:
 			log.Infof("signal %v received and starting exit...", sig)
 			cancel()
+		case syscall.SIGHUP:
+			log.Infof("signal %v received and starting reload...", sig)
+			triggerReload(reload)
 		}
 	}
 }
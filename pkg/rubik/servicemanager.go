@@ -17,21 +17,52 @@ package rubik
 import (
 	"context"
 	"fmt"
+	"math"
+	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/wait"
 
+	"isula.org/rubik/pkg/admin"
 	"isula.org/rubik/pkg/api"
+	"isula.org/rubik/pkg/common/audit"
+	"isula.org/rubik/pkg/common/checkpoint"
 	"isula.org/rubik/pkg/common/log"
+	"isula.org/rubik/pkg/common/metrics"
+	"isula.org/rubik/pkg/common/util"
 	"isula.org/rubik/pkg/config"
+	"isula.org/rubik/pkg/core/publisher"
 	"isula.org/rubik/pkg/core/subscriber"
 	"isula.org/rubik/pkg/core/typedef"
+	"isula.org/rubik/pkg/core/typedef/cgroup"
 	"isula.org/rubik/pkg/services"
+	"isula.org/rubik/pkg/services/helper"
 )
 
-// serviceManagerName is the unique ID of the service manager
-const serviceManagerName = "serviceManager"
+const (
+	// serviceManagerName is the unique ID of the service manager
+	serviceManagerName = "serviceManager"
+	// runnerStopTimeout is the maximum time to wait for a runner to exit
+	runnerStopTimeout = 10 * time.Second
+	// serviceQueuePrefix is the prefix of the name of the queues delivering the pod events to the services
+	serviceQueuePrefix = "service/"
+	// checkpointInterval is the interval of saving the states of the services
+	checkpointInterval = 30 * time.Second
+	// the states saved more than checkpointMaxAge ago are not restored, since the pods and their usage
+	// may have changed a lot while rubik is not running
+	checkpointMaxAge = 10 * time.Minute
+	// rubik is unhealthy if a persistent service is restarted more than restartBudget times in restartBudgetWindow
+	restartBudget       = 5
+	restartBudgetWindow = 10 * time.Minute
+	// the optional service failed to pre-start is retried with the backoff from retryInterval to maxRetryInterval
+	retryInterval    = 10 * time.Second
+	maxRetryInterval = 5 * time.Minute
+)
+
+// managerLog is the logger of the service manager
+var managerLog = log.WithModule(serviceManagerName)
 
 // ServiceManager is used to manage the lifecycle of services
 type ServiceManager struct {
@@ -39,57 +70,410 @@ type ServiceManager struct {
 	api.Viewer
 	sync.RWMutex
 	RunningServices map[string]services.Service
+	// serviceConfig is the configuration of the running services
+	serviceConfig map[string]interface{}
+	// ctx is the parent context of all runners, it is set when the services start
+	ctx     context.Context
+	runners map[string]*runner
+	// health records the running states of the persistent services, it is protected by healthLock
+	// instead of the manager lock so that the liveness check never waits for reloading or controlling
+	health     map[string]*serviceHealth
+	healthLock sync.RWMutex
+	// checkpoints saves the states of the services across restarts, it is nil if checkpoint is disabled
+	checkpoints *checkpoint.Store
+	// revertOnExit terminates the services and restores the files they changed when rubik exits,
+	// otherwise the settings are kept for the restarted rubik which restores the states of the services
+	revertOnExit bool
+	// preStarted is set to 1 after all services are pre-started
+	preStarted int32
+	// optional are the features which are degraded instead of stopping rubik if they fail to pre-start
+	optional map[string]struct{}
+	// degraded are the optional services failed to pre-start, which are not running until the retry succeeds
+	degraded map[string]*degradedService
+	// states are the services paused or stopped at runtime, which do not run or handle the pods
+	states map[string]string
+	// queues deliver the pod events to the running services
+	queues    map[string]*serviceQueue
+	queueLock sync.Mutex
+	// reconcileInterval is the interval of restoring the settings of the pods changed by others, 0 disables it
+	reconcileInterval time.Duration
+	// deleting are the deleted pods whose deletion events are not handled by all services yet
+	deleting     map[string]*deletingPod
+	deletingLock sync.Mutex
+	// handlers dispatch the pod information events
+	handlers *subscriber.Handlers
+	// controlLock serializes reloading, controlling and stopping the services
+	controlLock sync.Mutex
+	// serviceLocks serialize the calls to each service, so that the service is not reconfigured or terminated
+	// while it handles a pod event. The manager lock must not be held while waiting for them.
+	serviceLocks     map[string]*sync.Mutex
+	serviceLocksLock sync.Mutex
+}
+
+// deletingPod is a deleted pod waiting for the services to handle its deletion
+type deletingPod struct {
+	path     string
+	services map[string]struct{}
+}
+
+// serviceQueue is the queue delivering the pod events to the service
+type serviceQueue struct {
+	*publisher.Queue
+	service services.Service
+}
+
+// degradedService records an optional service which failed to pre-start
+type degradedService struct {
+	service   services.Service
+	lastError string
+	retries   int64
+	// cancel stops retrying, it is nil before the manager starts
+	cancel context.CancelFunc
+}
+
+// runner records a running persistent service
+type runner struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// serviceHealth records the running state of a persistent service
+type serviceHealth struct {
+	sync.RWMutex
+	running       bool
+	restarts      int64
+	panics        int64
+	lastPanic     string
+	lastPanicTime time.Time
+	// restartTimes is the time of the restarts in restartBudgetWindow
+	restartTimes []time.Time
 }
 
 // NewServiceManager creates a servicemanager object
 func NewServiceManager() *ServiceManager {
 	manager := &ServiceManager{
 		RunningServices: make(map[string]services.Service),
+		runners:         make(map[string]*runner),
+		health:          make(map[string]*serviceHealth),
+		optional:        make(map[string]struct{}),
+		degraded:        make(map[string]*degradedService),
+		states:          make(map[string]string),
+		queues:          make(map[string]*serviceQueue),
+		deleting:        make(map[string]*deletingPod),
+		serviceLocks:    make(map[string]*sync.Mutex),
+		revertOnExit:    true,
+	}
+	manager.handlers = &subscriber.Handlers{
+		OnPodAdd:    func(e typedef.PodAddEvent) { manager.addFunc(e.Pod) },
+		OnPodUpdate: func(e typedef.PodUpdateEvent) { manager.updateFunc(e.Old, e.New) },
+		OnPodDelete: func(e typedef.PodDeleteEvent) { manager.deleteFunc(e.Pod) },
 	}
 	manager.Subscriber = subscriber.NewGenericSubscriber(manager, serviceManagerName)
 	return manager
 }
 
+// newConfigHandler returns the ConfigHandler obtaining the service configuration from serviceConfig
+func newConfigHandler(serviceConfig map[string]interface{}, parser config.ConfigParser) helper.ConfigHandler {
+	return func(configName string, v interface{}) error {
+		config := serviceConfig[configName]
+		if config == nil {
+			return fmt.Errorf("this configuration is not available,configName:%v", configName)
+		}
+		if err := parser.UnmarshalSubConfig(config, v); err != nil {
+			return fmt.Errorf("this configuration failed to be serialized,configName:%v,error:%v", configName, err)
+		}
+		return nil
+	}
+}
+
+// newService creates the service of the feature and sets its configuration
+func newService(feature string, handler helper.ConfigHandler, parser config.ConfigParser) (services.Service, error) {
+	s, err := services.GetServiceComponent(feature)
+	if err != nil {
+		return nil, fmt.Errorf("get component failed %s: %v", feature, err)
+	}
+	if err := s.SetConfig(handler); err != nil {
+		return nil, fmt.Errorf("set configuration failed, err:%v", err)
+	}
+	conf, err := parser.MarshalIndent(s.GetConfig(), "", "\t")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service %v configuration: %v", s.ID(), err)
+	}
+	if len(conf) != 0 {
+		log.Infof("service %v will run with configuration:\n%v", s.ID(), conf)
+	} else {
+		log.Infof("service %v will run", s.ID())
+	}
+	return s, nil
+}
+
 // InitServices parses the to-be-run services config and loads them to the ServiceManager
 func (manager *ServiceManager) InitServices(features []string,
 	serviceConfig map[string]interface{}, parser config.ConfigParser) error {
+	handler := newConfigHandler(serviceConfig, parser)
 	for _, feature := range features {
-		s, err := services.GetServiceComponent(feature)
+		s, err := newService(feature, handler, parser)
 		if err != nil {
-			return fmt.Errorf("get component failed %s: %v", feature, err)
+			return err
+		}
+		if err := manager.AddRunningService(feature, s); err != nil {
+			return err
 		}
+	}
+	manager.serviceConfig = serviceConfig
+	return nil
+}
 
-		if err1 := s.SetConfig(func(configName string, v interface{}) error {
-			config := serviceConfig[configName]
-			if config == nil {
-				return fmt.Errorf("this configuration is not available,configName:%v", configName)
-			}
-			if err2 := parser.UnmarshalSubConfig(config, v); err2 != nil {
-				return fmt.Errorf("this configuration failed to be serialized,configName:%v,error:%v", configName, err2)
-			}
-			return nil
-		}); err1 != nil {
-			return fmt.Errorf("set configuration failed, err:%v", err1)
+// ReloadError is returned by Reload if some services fail to start or to be reconfigured,
+// while the other changes are applied
+type ReloadError struct {
+	error
+	// Features are the enabled features in use, the required features failed to start are excluded
+	Features []string
+	// ServiceConfig is the configuration in use, the services failed to be reconfigured keep the old ones
+	ServiceConfig map[string]interface{}
+}
+
+// Reload applies the new configuration to the running services.
+// Services whose configuration changes are reconfigured, newly enabled services are started
+// and disabled services are terminated. Nothing is changed if any configuration is invalid.
+// ReloadError is returned if any required service fails to start or to be reconfigured,
+// while the other services are still changed.
+// The manager lock is only held to change the running services, so that the pod events and the health checks
+// are not blocked while the services are stopped, reconfigured and pre-started.
+func (manager *ServiceManager) Reload(features []string,
+	serviceConfig map[string]interface{}, parser config.ConfigParser) error {
+	manager.controlLock.Lock()
+	defer manager.controlLock.Unlock()
+	handler := newConfigHandler(serviceConfig, parser)
+	// 1. check all configurations before changing any service
+	plan, err := manager.planReload(features, serviceConfig, handler, parser)
+	if err != nil {
+		return err
+	}
+
+	// 2. apply the changes
+	var runners = make(map[string]*runner, len(plan.disabled)+len(plan.changed))
+	manager.Lock()
+	for name, d := range manager.degraded {
+		// the degraded service still enabled is created and pre-started again as an added service
+		manager.recoverDegraded(name, d)
+		if _, existed := plan.enabled[name]; !existed {
+			plan.removed = append(plan.removed, name)
+		}
+	}
+	for name := range plan.disabled {
+		manager.closeQueue(name)
+		runners[name] = manager.detachRunner(name)
+		delete(manager.RunningServices, name)
+		manager.healthLock.Lock()
+		delete(manager.health, name)
+		manager.healthLock.Unlock()
+		plan.removed = append(plan.removed, name)
+		// the stopped service has been terminated
+		if manager.states[name] == admin.ServiceStopped {
+			delete(plan.disabled, name)
 		}
+		delete(manager.states, name)
+	}
+	for name := range plan.changed {
+		runners[name] = manager.detachRunner(name)
+	}
+	manager.Unlock()
+	for name, r := range runners {
+		r.stop(name)
+	}
+	for _, name := range plan.removed {
+		manager.dropCheckpoint(name)
+	}
+	for name, s := range plan.disabled {
+		manager.terminateService(name, s)
+	}
+	var (
+		errs error
+		// applied is the configuration in use, the configuration of the service failed to be reconfigured
+		// is not changed so that it is reconfigured again by the next reload
+		applied = make(map[string]interface{}, len(serviceConfig))
+	)
+	for name, conf := range serviceConfig {
+		applied[name] = conf
+	}
+	for name, s := range plan.changed {
+		if err := manager.reconfigureService(name, s, handler); err != nil {
+			errs = util.AppendErr(errs, err)
+			applied[name] = plan.oldConfig[name]
+		}
+	}
+	for name, s := range plan.added {
+		if err := manager.startService(name, s); err != nil {
+			errs = util.AppendErr(errs, err)
+		}
+	}
 
-		conf, err := parser.MarshalIndent(s.GetConfig(), "", "\t")
-		if err != nil {
-			return fmt.Errorf("failed to get service %v configuration: %v", s.ID(), err)
+	manager.Lock()
+	defer manager.Unlock()
+	for name, s := range plan.changed {
+		manager.startRunner(name, s)
+	}
+	manager.serviceConfig = applied
+	if errs == nil {
+		return nil
+	}
+	var inUse = make([]string, 0, len(features))
+	for _, feature := range features {
+		_, running := manager.RunningServices[feature]
+		_, degraded := manager.degraded[feature]
+		if running || degraded {
+			inUse = append(inUse, feature)
 		}
+	}
+	return &ReloadError{error: errs, Features: inUse, ServiceConfig: applied}
+}
 
-		if err := manager.AddRunningService(feature, s); err != nil {
-			return err
+// reloadPlan is the changes of the services applied by reloading
+type reloadPlan struct {
+	enabled  map[string]struct{}
+	changed  map[string]services.Service
+	added    map[string]services.Service
+	disabled map[string]services.Service
+	// removed are the services whose checkpoints are dropped
+	removed []string
+	// oldConfig is the configuration in use before reloading
+	oldConfig map[string]interface{}
+}
+
+// planReload checks the new configuration and returns the changes of the services
+func (manager *ServiceManager) planReload(features []string, serviceConfig map[string]interface{},
+	handler helper.ConfigHandler, parser config.ConfigParser) (*reloadPlan, error) {
+	manager.RLock()
+	defer manager.RUnlock()
+	var plan = &reloadPlan{
+		enabled:   make(map[string]struct{}, len(features)),
+		changed:   make(map[string]services.Service),
+		added:     make(map[string]services.Service),
+		disabled:  make(map[string]services.Service),
+		oldConfig: manager.serviceConfig,
+	}
+	for _, feature := range features {
+		if _, existed := plan.enabled[feature]; existed {
+			return nil, fmt.Errorf("service name conflict: %s", feature)
+		}
+		plan.enabled[feature] = struct{}{}
+		s, running := manager.RunningServices[feature]
+		if !running {
+			created, err := newService(feature, handler, parser)
+			if err != nil {
+				return nil, err
+			}
+			plan.added[feature] = created
+			continue
+		}
+		if reflect.DeepEqual(manager.serviceConfig[feature], serviceConfig[feature]) {
+			continue
+		}
+		if err := validateServiceConfig(feature, s, handler); err != nil {
+			return nil, fmt.Errorf("invalid configuration of service %v: %v", feature, err)
 		}
+		plan.changed[feature] = s
+	}
+	for name, s := range manager.RunningServices {
+		if _, existed := plan.enabled[name]; !existed {
+			plan.disabled[name] = s
+		}
+	}
+	return plan, nil
+}
 
-		if len(conf) != 0 {
-			log.Infof("service %v will run with configuration:\n%v", s.ID(), conf)
-		} else {
-			log.Infof("service %v will run", s.ID())
+// reconfigureService sets the new configuration of the service after it finishes handling the pod event,
+// and replays the current pods to the service so that the new configuration is applied to them
+func (manager *ServiceManager) reconfigureService(name string, s services.Service, handler helper.ConfigHandler) error {
+	defer manager.lockService(name)()
+	if err := s.SetConfig(handler); err != nil {
+		log.Errorf("failed to reconfigure service %v: %v", name, err)
+		return fmt.Errorf("failed to reconfigure service %v: %v", name, err)
+	}
+	log.Infof("service %v is reconfigured", name)
+	// the paused service replays the pods when it resumes and the stopped one when it starts
+	if state, _ := manager.serviceState(name, s); state == "" {
+		manager.replayPods(name, s)
+	}
+	return nil
+}
+
+// startService pre-starts and runs the newly enabled service. The service is added to the running services
+// before it is pre-started, so that the pod events queued meanwhile are handled after the pre-start.
+func (manager *ServiceManager) startService(name string, s services.Service) error {
+	defer manager.lockService(name)()
+	manager.restoreService(name, s)
+	manager.Lock()
+	manager.RunningServices[name] = s
+	manager.Unlock()
+	if err := s.PreStart(ownedViewer(manager.Viewer, name)); err != nil {
+		manager.Lock()
+		delete(manager.RunningServices, name)
+		manager.closeQueue(name)
+		optional := manager.isOptional(name)
+		manager.Unlock()
+		if optional {
+			revertService(name)
+			manager.Lock()
+			manager.degrade(name, s, err)
+			manager.Unlock()
+			return nil
 		}
+		log.Errorf("failed to preStart service %v: %v", name, err)
+		terminatingServices(map[string]services.Service{name: s}, manager.Viewer)
+		manager.dropCheckpoint(name)
+		return fmt.Errorf("failed to preStart service %v: %v", name, err)
 	}
+	manager.Lock()
+	manager.startRunner(name, s)
+	manager.Unlock()
+	log.Infof("service %v is started", name)
 	return nil
 }
 
+// ValidateConfig checks the configuration of the features without changing any service
+func (manager *ServiceManager) ValidateConfig(features []string,
+	serviceConfig map[string]interface{}, parser config.ConfigParser) error {
+	manager.RLock()
+	defer manager.RUnlock()
+	var (
+		handler = newConfigHandler(serviceConfig, parser)
+		enabled = make(map[string]struct{}, len(features))
+	)
+	for _, feature := range features {
+		if _, existed := enabled[feature]; existed {
+			return fmt.Errorf("service name conflict: %s", feature)
+		}
+		enabled[feature] = struct{}{}
+		s, running := manager.RunningServices[feature]
+		if !running {
+			created, err := services.GetServiceComponent(feature)
+			if err != nil {
+				return fmt.Errorf("get component failed %s: %v", feature, err)
+			}
+			s = created
+		}
+		if err := validateServiceConfig(feature, s, handler); err != nil {
+			return fmt.Errorf("invalid configuration of service %v: %v", feature, err)
+		}
+	}
+	return nil
+}
+
+// validateServiceConfig checks the new configuration of the running service
+func validateServiceConfig(feature string, s services.Service, handler helper.ConfigHandler) error {
+	if v, ok := s.(services.ConfigValidator); ok {
+		return v.ValidateConfig(handler)
+	}
+	tmp, err := services.GetServiceComponent(feature)
+	if err != nil {
+		return err
+	}
+	return tmp.SetConfig(handler)
+}
+
 // AddRunningService adds a to-be-run service
 func (manager *ServiceManager) AddRunningService(name string, s services.Service) error {
 	manager.Lock()
@@ -102,27 +486,21 @@ func (manager *ServiceManager) AddRunningService(name string, s services.Service
 }
 
 // HandleEvent is used to handle PodInfo events pushed by the publisher
-func (manager *ServiceManager) HandleEvent(eventType typedef.EventType, event typedef.Event) {
+func (manager *ServiceManager) HandleEvent(event typedef.Event) {
 	defer func() {
 		if err := recover(); err != nil {
 			log.Errorf("panic occur: %v", err)
 		}
 	}()
-	switch eventType {
-	case typedef.INFOADD:
-		manager.addFunc(event)
-	case typedef.INFOUPDATE:
-		manager.updateFunc(event)
-	case typedef.INFODELETE:
-		manager.deleteFunc(event)
-	default:
-		log.Infof("service manager fail to process %s type", eventType.String())
+	if !manager.handlers.Dispatch(event) {
+		eventType := event.Type().String()
+		managerLog.WithEvent(eventType).Infof("service manager fail to process %s type", eventType)
 	}
 }
 
 // EventTypes returns the type of event the serviceManager is interested in
 func (manager *ServiceManager) EventTypes() []typedef.EventType {
-	return []typedef.EventType{typedef.INFOADD, typedef.INFOUPDATE, typedef.INFODELETE}
+	return manager.handlers.EventTypes()
 }
 
 // terminatingRunningServices handles services exits during the setup and exit phases
@@ -135,11 +513,19 @@ func terminatingServices(serviceMap map[string]services.Service, viewer api.View
 				log.Infof("service %v stop successfully", name)
 			}
 		}
-		if err := s.Terminate(viewer); err != nil {
+		if err := s.Terminate(ownedViewer(viewer, name)); err != nil {
 			log.Errorf("failed to terminate service %v: %v", name, err)
 		} else {
 			log.Infof("service %v terminate successfully", name)
 		}
+		revertService(name)
+	}
+}
+
+// revertService restores the files changed by the service which are not changed by other running services
+func revertService(name string) {
+	if err := audit.Revert(name); err != nil {
+		log.Errorf("failed to restore the files changed by service %v: %v", name, err)
 	}
 }
 
@@ -152,140 +538,815 @@ func (manager *ServiceManager) Setup(v api.Viewer) error {
 	manager.Viewer = v
 
 	var preStarted = make(map[string]services.Service, 0)
-	manager.RLock()
-	defer manager.RUnlock()
+	manager.Lock()
+	defer manager.Unlock()
 	for name, s := range manager.RunningServices {
 		/*
-			Try to prestart the service. If any service fails, rubik exits
+			Try to prestart the service. If any required service fails, rubik exits
 			and invokes the terminate function to terminate the prestarted service.
+			The optional service failed is degraded and retried after the manager starts.
 		*/
-		if err := s.PreStart(manager.Viewer); err != nil {
+		manager.restoreService(name, s)
+		if err := s.PreStart(ownedViewer(manager.Viewer, name)); err != nil {
+			revertService(name)
+			if manager.isOptional(name) {
+				manager.degrade(name, s, err)
+				continue
+			}
 			terminatingServices(preStarted, manager.Viewer)
 			return fmt.Errorf("failed to preStart service %v: %v", name, err)
 		}
 		preStarted[name] = s
 		log.Infof("service %v pre-start successfully", name)
 	}
+	atomic.StoreInt32(&manager.preStarted, 1)
+	return nil
+}
+
+// PreStarted returns true if all services are pre-started
+func (manager *ServiceManager) PreStarted() bool {
+	return atomic.LoadInt32(&manager.preStarted) == 1
+}
+
+// CheckRestartBudget returns an error if any persistent service is restarted too many times recently,
+// which indicates that the service keeps panicking
+func (manager *ServiceManager) CheckRestartBudget() error {
+	manager.healthLock.RLock()
+	defer manager.healthLock.RUnlock()
+	now := time.Now()
+	for name, h := range manager.health {
+		if restarts := h.recentRestarts(now); restarts > restartBudget {
+			return fmt.Errorf("service %v restarted %d times in %v, last panic: %v",
+				name, restarts, restartBudgetWindow, h.lastPanicMessage())
+		}
+	}
 	return nil
 }
 
 // Start starts and runs the persistent service
 func (manager *ServiceManager) Start(ctx context.Context) {
+	manager.Lock()
+	defer manager.Unlock()
+	manager.ctx = ctx
+	for id, s := range manager.RunningServices {
+		manager.startRunner(id, s)
+	}
+	for name, d := range manager.degraded {
+		manager.startRetry(name, d)
+	}
+	if manager.checkpoints != nil {
+		go wait.Until(manager.saveCheckpoints, checkpointInterval, ctx.Done())
+	}
+	if manager.reconcileInterval > 0 {
+		go wait.Until(manager.reconcile, manager.reconcileInterval, ctx.Done())
+	}
+}
+
+// ControlService pauses, resumes, stops or starts the enabled service at runtime.
+// The state is kept until rubik restarts or the service is disabled.
+func (manager *ServiceManager) ControlService(name string, action admin.ServiceAction) error {
+	manager.controlLock.Lock()
+	defer manager.controlLock.Unlock()
+	s, err := manager.controlledService(name, action)
+	if err != nil {
+		return err
+	}
+	switch action {
+	case admin.PauseService:
+		manager.setState(name, admin.ServicePaused).stop(name)
+	case admin.ResumeService:
+		// the pods added or updated while the service is paused are replayed to the service,
+		// the events queued once it resumes are handled after the replay
+		unlock := manager.lockService(name)
+		manager.setState(name, "")
+		manager.replayPods(name, s)
+		unlock()
+		manager.Lock()
+		manager.startRunner(name, s)
+		manager.Unlock()
+	case admin.StopService:
+		manager.setState(name, admin.ServiceStopped).stop(name)
+		manager.terminateService(name, s)
+		manager.dropCheckpoint(name)
+	case admin.StartService:
+		// the current pods are replayed to the service by pre-starting it,
+		// the events queued once it starts are handled after the pre-start
+		unlock := manager.lockService(name)
+		manager.setState(name, "")
+		if err := s.PreStart(ownedViewer(manager.Viewer, name)); err != nil {
+			revertService(name)
+			manager.setState(name, admin.ServiceStopped)
+			unlock()
+			return fmt.Errorf("failed to preStart service %v: %v", name, err)
+		}
+		unlock()
+		manager.Lock()
+		manager.startRunner(name, s)
+		manager.Unlock()
+	}
+	log.Infof("%v service %v at runtime", action, name)
+	return nil
+}
+
+// controlledService returns the service if the action is allowed in its current state
+func (manager *ServiceManager) controlledService(name string, action admin.ServiceAction) (services.Service, error) {
+	manager.RLock()
+	defer manager.RUnlock()
+	s, existed := manager.RunningServices[name]
+	if !existed {
+		if _, degraded := manager.degraded[name]; degraded {
+			return nil, fmt.Errorf("service %v is degraded", name)
+		}
+		return nil, admin.ServiceNotFoundError(name)
+	}
+	state := manager.states[name]
+	switch action {
+	case admin.PauseService:
+		if state != "" {
+			return nil, fmt.Errorf("service %v is %v", name, state)
+		}
+	case admin.ResumeService:
+		if state != admin.ServicePaused {
+			return nil, fmt.Errorf("service %v is not paused", name)
+		}
+	case admin.StopService:
+		if state == admin.ServiceStopped {
+			return nil, fmt.Errorf("service %v is %v", name, state)
+		}
+	case admin.StartService:
+		if state != admin.ServiceStopped {
+			return nil, fmt.Errorf("service %v is not stopped", name)
+		}
+	default:
+		return nil, fmt.Errorf("unknown action %v", action)
+	}
+	return s, nil
+}
+
+// setState changes the state of the service at runtime, the runner of the service paused or stopped
+// is returned to be stopped without holding the lock
+func (manager *ServiceManager) setState(name, state string) *runner {
+	manager.Lock()
+	defer manager.Unlock()
+	if state == "" {
+		delete(manager.states, name)
+		return nil
+	}
+	manager.states[name] = state
+	if state == admin.ServiceStopped {
+		manager.closeQueue(name)
+	}
+	return manager.detachRunner(name)
+}
+
+// serviceState returns the state of the service at runtime,
+// current is false if the service is no longer enabled or is replaced
+func (manager *ServiceManager) serviceState(name string, s services.Service) (state string, current bool) {
+	manager.RLock()
+	defer manager.RUnlock()
+	return manager.states[name], manager.RunningServices[name] == s
+}
+
+// lockService serializes the calls to the service, it returns the function releasing the service
+func (manager *ServiceManager) lockService(name string) func() {
+	manager.serviceLocksLock.Lock()
+	l, existed := manager.serviceLocks[name]
+	if !existed {
+		l = &sync.Mutex{}
+		manager.serviceLocks[name] = l
+	}
+	manager.serviceLocksLock.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+// terminateService terminates the service after it finishes handling the pod event
+func (manager *ServiceManager) terminateService(name string, s services.Service) {
+	defer manager.lockService(name)()
+	terminatingServices(map[string]services.Service{name: s}, manager.Viewer)
+}
+
+// leaveService stops the persistent service when rubik exits, the settings of the service are neither
+// cleaned up by terminating it nor restored, so that the restarted rubik takes them over
+func (manager *ServiceManager) leaveService(name string, s services.Service) {
+	defer manager.lockService(name)()
+	if !s.IsRunner() {
+		return
+	}
+	if err := s.Stop(); err != nil {
+		log.Errorf("failed to stop service %v: %v", name, err)
+	} else {
+		log.Infof("service %v stop successfully", name)
+	}
+}
+
+// replayPods adds the current pods to the service, the service must be locked so that
+// the replay is not interleaved with the queued pod events of the service
+func (manager *ServiceManager) replayPods(name string, s services.Service) {
+	if manager.Viewer == nil {
+		return
+	}
+	for _, pod := range ownedViewer(manager.Viewer, name).ListPodsWithOptions() {
+		if err := s.AddPod(pod); err != nil {
+			podLogger(typedef.INFOADD, pod, name).Errorf("service %s failed to replay the pod: %v", name, err)
+			metrics.ServiceErrors.Inc(name, "add")
+		}
+	}
+}
+
+// SetOptionalFeatures sets the features which are degraded instead of stopping rubik if they fail to pre-start
+func (manager *ServiceManager) SetOptionalFeatures(features []string) {
+	manager.Lock()
+	defer manager.Unlock()
+	manager.optional = make(map[string]struct{}, len(features))
+	for _, feature := range features {
+		manager.optional[feature] = struct{}{}
+	}
+}
+
+// SetRevertOnExit sets whether the services are terminated and the files they changed are restored
+// when rubik exits
+func (manager *ServiceManager) SetRevertOnExit(revert bool) {
+	manager.Lock()
+	manager.revertOnExit = revert
+	manager.Unlock()
+}
+
+func (manager *ServiceManager) isOptional(name string) bool {
+	_, existed := manager.optional[name]
+	return existed
+}
+
+// degrade removes the optional service failed to pre-start from the running services and retries it,
+// the lock must be held
+func (manager *ServiceManager) degrade(name string, s services.Service, err error) {
+	log.Warnf("optional service %v is degraded and retried in the background: failed to preStart: %v", name, err)
+	delete(manager.RunningServices, name)
+	d := &degradedService{service: s, lastError: err.Error()}
+	manager.degraded[name] = d
+	metrics.ServiceDegraded.Set(1, name)
+	manager.startRetry(name, d)
+}
+
+// recoverDegraded stops retrying the degraded service, the lock must be held
+func (manager *ServiceManager) recoverDegraded(name string, d *degradedService) {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	delete(manager.degraded, name)
+	metrics.ServiceDegraded.Set(0, name)
+}
+
+// startRetry retries pre-starting the degraded service with backoff until it succeeds,
+// the service is retried after the manager starts
+func (manager *ServiceManager) startRetry(name string, d *degradedService) {
+	if manager.ctx == nil || d.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(manager.ctx)
+	d.cancel = cancel
+	go func() {
+		backoff := wait.Backoff{
+			Duration: retryInterval,
+			Factor:   2,
+			Jitter:   0.1,
+			Steps:    math.MaxInt32,
+			Cap:      maxRetryInterval,
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff.Step()):
+			}
+			if manager.retryPreStart(name, d) {
+				return
+			}
+		}
+	}()
+}
+
+// retryPreStart pre-starts the degraded service and runs it if it succeeds,
+// true is returned if the service no longer needs to be retried
+func (manager *ServiceManager) retryPreStart(name string, d *degradedService) bool {
+	manager.controlLock.Lock()
+	defer manager.controlLock.Unlock()
+	manager.Lock()
+	defer manager.Unlock()
+	// the service is disabled or replaced by reloading
+	if manager.degraded[name] != d {
+		return true
+	}
+	d.retries++
+	if err := d.service.PreStart(ownedViewer(manager.Viewer, name)); err != nil {
+		revertService(name)
+		d.lastError = err.Error()
+		log.Warnf("optional service %v failed to preStart after %d retries: %v", name, d.retries, err)
+		return false
+	}
+	manager.recoverDegraded(name, d)
+	manager.RunningServices[name] = d.service
+	manager.startRunner(name, d.service)
+	log.Infof("optional service %v is started after %d retries", name, d.retries)
+	return true
+}
+
+// restoreService restores the state of the service saved in the checkpoint,
+// the service starts from the initial state if the state fails to be restored
+func (manager *ServiceManager) restoreService(name string, s services.Service) {
+	c, ok := s.(services.Checkpointer)
+	if !ok || manager.checkpoints == nil {
+		return
+	}
+	state := manager.checkpoints.Get(name)
+	if state == nil {
+		return
+	}
+	if age := time.Since(state.Time); age > checkpointMaxAge {
+		log.Warnf("the state of service %v saved %v ago is stale, start from the initial state",
+			name, age.Truncate(time.Second))
+		return
+	}
+	if err := c.Restore(state); err != nil {
+		log.Warnf("failed to restore the state of service %v, start from the initial state: %v", name, err)
+		return
+	}
+	log.Infof("service %v is restored from the state saved at %v", name, state.Time.Format(time.RFC3339))
+}
+
+// saveCheckpoints saves the states of the running services to the checkpoint file,
+// nothing is written if no running service keeps its state
+func (manager *ServiceManager) saveCheckpoints() {
+	if manager.checkpoints.Disabled() {
+		return
+	}
+	manager.RLock()
+	defer manager.RUnlock()
+	var checkpointed bool
+	for name, s := range manager.RunningServices {
+		c, ok := s.(services.Checkpointer)
+		if !ok || manager.states[name] == admin.ServiceStopped {
+			continue
+		}
+		checkpointed = true
+		state, err := c.Checkpoint()
+		if err != nil {
+			log.Warnf("failed to checkpoint service %v: %v", name, err)
+			continue
+		}
+		manager.checkpoints.Set(name, state)
+	}
+	if !checkpointed {
+		return
+	}
+	manager.saveCheckpointFile()
+}
+
+// saveCheckpointFile writes the checkpoint file, checkpointing is disabled if it fails
+func (manager *ServiceManager) saveCheckpointFile() {
+	if err := manager.checkpoints.Save(); err != nil {
+		log.Warnf("failed to save checkpoint, the states of the services are no longer saved: %v", err)
+	}
+}
+
+// dropCheckpoint drops the state of the service which is no longer running
+func (manager *ServiceManager) dropCheckpoint(name string) {
+	if manager.checkpoints == nil || manager.checkpoints.Get(name) == nil {
+		return
+	}
+	manager.checkpoints.Delete(name)
+	manager.saveCheckpointFile()
+}
+
+// startRunner runs the persistent service in the background until it is stopped
+func (manager *ServiceManager) startRunner(id string, s services.Service) {
+	// services are started after the manager starts, and the paused or stopped services are not started
+	if !s.IsRunner() || manager.ctx == nil || manager.states[id] != "" {
+		return
+	}
+	manager.healthLock.Lock()
+	h, existed := manager.health[id]
+	if !existed {
+		h = &serviceHealth{}
+		manager.health[id] = h
+	}
+	manager.healthLock.Unlock()
+	ctx, cancel := context.WithCancel(manager.ctx)
+	r := &runner{cancel: cancel, done: make(chan struct{})}
+	manager.runners[id] = r
+	go func() {
+		defer close(r.done)
+		h.setRunning(true)
+		defer h.setRunning(false)
+		runService(ctx, id, s.Run, h)
+	}()
+}
+
+// detachRunner removes the runner of the service and returns it, the lock must be held
+func (manager *ServiceManager) detachRunner(id string) *runner {
+	r, existed := manager.runners[id]
+	if !existed {
+		return nil
+	}
+	delete(manager.runners, id)
+	return r
+}
+
+// stop cancels the runner of the service and waits for it to exit, nothing is done if r is nil
+func (r *runner) stop(id string) {
+	if r == nil {
+		return
+	}
+	r.cancel()
+	select {
+	case <-r.done:
+	case <-time.After(runnerStopTimeout):
+		log.Warnf("service %s does not exit in %v", id, runnerStopTimeout)
+	}
+}
+
+// runService runs the service until the context is canceled
+func runService(ctx context.Context, id string, runFunc func(ctx context.Context), h *serviceHealth) {
 	/*
 		The Run function of the service will be called continuously until the context is canceled.
 		When a service panics while running, recover will catch the violation
 		and briefly restart for a short period of time.
 	*/
 	const restartDuration = 2 * time.Second
-	runner := func(ctx context.Context, id string, runFunc func(ctx context.Context)) {
-		var restartCount int64
-		wait.UntilWithContext(ctx, func(ctx context.Context) {
-			defer func() {
-				if err := recover(); err != nil {
-					log.Errorf("service %s catch a panic: %v", id, err)
-				}
-			}()
-			if restartCount > 0 {
-				log.Warnf("service %s has restart %v times", id, restartCount)
+	var restartCount int64
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		defer func() {
+			if err := recover(); err != nil {
+				log.Errorf("service %s catch a panic: %v", id, err)
+				h.recordPanic(err)
+				metrics.ServicePanics.Inc(id)
 			}
-			restartCount++
-			runFunc(ctx)
-		}, restartDuration)
+		}()
+		if restartCount > 0 {
+			log.Warnf("service %s has restart %v times", id, restartCount)
+			h.recordRestart()
+			metrics.ServiceRestarts.Inc(id)
+		}
+		restartCount++
+		runFunc(ctx)
+	}, restartDuration)
+}
+
+// serviceHealth returns the running state of the persistent service, nil is returned if it never runs
+func (manager *ServiceManager) serviceHealth(name string) *serviceHealth {
+	manager.healthLock.RLock()
+	defer manager.healthLock.RUnlock()
+	return manager.health[name]
+}
+
+func (h *serviceHealth) setRunning(running bool) {
+	h.Lock()
+	h.running = running
+	h.Unlock()
+}
+
+func (h *serviceHealth) recordRestart() {
+	h.Lock()
+	h.restarts++
+	now := time.Now()
+	h.restartTimes = append(h.restartTimes[len(h.restartTimes)-h.countRestarts(now):], now)
+	h.Unlock()
+}
+
+// countRestarts returns the number of the restarts in restartBudgetWindow, the lock must be held
+func (h *serviceHealth) countRestarts(now time.Time) int {
+	var i int
+	for i < len(h.restartTimes) && now.Sub(h.restartTimes[i]) > restartBudgetWindow {
+		i++
 	}
+	return len(h.restartTimes) - i
+}
 
-	for id, s := range manager.RunningServices {
-		if s.IsRunner() {
-			go runner(ctx, id, s.Run)
+func (h *serviceHealth) recentRestarts(now time.Time) int {
+	h.RLock()
+	defer h.RUnlock()
+	return h.countRestarts(now)
+}
+
+func (h *serviceHealth) lastPanicMessage() string {
+	h.RLock()
+	defer h.RUnlock()
+	return h.lastPanic
+}
+
+func (h *serviceHealth) recordPanic(err interface{}) {
+	h.Lock()
+	h.panics++
+	h.lastPanic = fmt.Sprint(err)
+	h.lastPanicTime = time.Now()
+	h.Unlock()
+}
+
+// fill fills the running state to the status
+func (h *serviceHealth) fill(status *admin.ServiceStatus) {
+	h.RLock()
+	defer h.RUnlock()
+	status.Running = h.running
+	status.Restarts = h.restarts
+	status.Panics = h.panics
+	if h.panics > 0 {
+		status.LastPanic = h.lastPanic
+		lastPanicTime := h.lastPanicTime
+		status.LastPanicTime = &lastPanicTime
+	}
+}
+
+// ServiceStatuses returns the configuration and running state of the running services
+func (manager *ServiceManager) ServiceStatuses() []admin.ServiceStatus {
+	manager.RLock()
+	defer manager.RUnlock()
+	var statuses = make([]admin.ServiceStatus, 0, len(manager.RunningServices))
+	for name, s := range manager.RunningServices {
+		status := admin.ServiceStatus{
+			Name:   name,
+			Runner: s.IsRunner(),
+			State:  manager.states[name],
+			Config: s.GetConfig(),
+		}
+		if h := manager.serviceHealth(name); h != nil {
+			h.fill(&status)
 		}
+		statuses = append(statuses, status)
+	}
+	for name, d := range manager.degraded {
+		statuses = append(statuses, admin.ServiceStatus{
+			Name:      name,
+			Runner:    d.service.IsRunner(),
+			Degraded:  true,
+			LastError: d.lastError,
+			Retries:   d.retries,
+			Config:    d.service.GetConfig(),
+		})
 	}
+	return statuses
 }
 
 // Stop terminates the running service
 func (manager *ServiceManager) Stop() error {
+	manager.controlLock.Lock()
+	defer manager.controlLock.Unlock()
+	// the states are saved before the services are terminated
+	if manager.checkpoints != nil {
+		manager.saveCheckpoints()
+	}
+	// the services are terminated after they finish handling the pod events
+	manager.closeQueues()
 	manager.RLock()
-	terminatingServices(manager.RunningServices, manager.Viewer)
+	var (
+		revert  = manager.revertOnExit
+		running = make(map[string]services.Service, len(manager.RunningServices))
+	)
+	for name, s := range manager.RunningServices {
+		// the stopped services have been terminated
+		if manager.states[name] != admin.ServiceStopped {
+			running[name] = s
+		}
+	}
 	manager.RUnlock()
+	for name, s := range running {
+		if revert {
+			manager.terminateService(name, s)
+		} else {
+			manager.leaveService(name, s)
+		}
+	}
 	return nil
 }
 
-// addFunc handles pod addition events
-func (manager *ServiceManager) addFunc(event typedef.Event) {
-	podInfo, ok := event.(*typedef.PodInfo)
-	if !ok {
-		log.Warnf("receive invalid event: %T", event)
-		return
-	}
+// podLogger returns the logger of the event of the pod handled by the service
+func podLogger(eventType typedef.EventType, pod *typedef.PodInfo, service string) *log.Entry {
+	return managerLog.WithEvent(eventType.String()).WithPod(pod.UID).WithService(service)
+}
 
-	const retryCount = 5
-	addOnce := func(s services.Service, podInfo *typedef.PodInfo, wg *sync.WaitGroup) {
-		for i := 0; i < retryCount; i++ {
-			if err := s.AddPod(podInfo); err != nil {
-				log.Errorf("service %s add func failed: %v", s.ID(), err)
-			} else {
-				break
-			}
+// addFunc queues pod addition events to the services
+func (manager *ServiceManager) addFunc(podInfo *typedef.PodInfo) {
+	manager.RLock()
+	for name, s := range manager.RunningServices {
+		if manager.states[name] != "" {
+			continue
 		}
-		wg.Done()
+		manager.queue(name, s).Push(typedef.PodAddEvent{Pod: ownedCopy(podInfo, s.ID())})
 	}
+	manager.RUnlock()
+}
+
+// updateFunc queues pod update events to the services
+func (manager *ServiceManager) updateFunc(oldPod, newPod *typedef.PodInfo) {
 	manager.RLock()
-	var wg sync.WaitGroup
-	for _, s := range manager.RunningServices {
-		wg.Add(1)
-		go addOnce(s, podInfo.DeepCopy(), &wg)
+	for name, s := range manager.RunningServices {
+		if manager.states[name] != "" {
+			continue
+		}
+		manager.queue(name, s).Push(typedef.PodUpdateEvent{
+			Old: ownedCopy(oldPod, s.ID()),
+			New: ownedCopy(newPod, s.ID()),
+		})
 	}
-	wg.Wait()
 	manager.RUnlock()
 }
 
-// updateFunc handles pod update events
-func (manager *ServiceManager) updateFunc(event typedef.Event) {
-	podInfos, ok := event.([]*typedef.PodInfo)
-	if !ok {
-		log.Warnf("receive invalid event: %T", event)
-		return
+// deleteFunc queues pod deletion events to the services
+func (manager *ServiceManager) deleteFunc(podInfo *typedef.PodInfo) {
+	manager.RLock()
+	defer manager.RUnlock()
+	var queues = make(map[string]*publisher.Queue, len(manager.RunningServices))
+	for name, s := range manager.RunningServices {
+		// the paused services still forget the deleted pods so that they are not leaked
+		if manager.states[name] == admin.ServiceStopped {
+			continue
+		}
+		queues[name] = manager.queue(name, s)
+	}
+	// the pod is forgotten after the services handle the events queued before the deletion,
+	// otherwise the writes of these events are kept for the pod which no longer exists
+	manager.waitDeletion(podInfo, queues)
+	for name, q := range queues {
+		if !q.Push(typedef.PodDeleteEvent{Pod: ownedCopy(podInfo, manager.RunningServices[name].ID())}) {
+			// the deletion dropped is never handled by the service
+			manager.deletionHandled(name, podInfo.UID)
+		}
 	}
-	const podInfosLen = 2
-	if len(podInfos) != podInfosLen {
-		log.Warnf("pod infos contains more than two pods")
+}
+
+// waitDeletion records the services which are going to handle the deletion of the pod,
+// the pod is forgotten at once if there is no such service
+func (manager *ServiceManager) waitDeletion(podInfo *typedef.PodInfo, queues map[string]*publisher.Queue) {
+	if len(queues) == 0 {
+		forgetPod(podInfo.UID, podInfo.Path)
 		return
 	}
-	runOnce := func(s services.Service, old, new *typedef.PodInfo, wg *sync.WaitGroup) {
-		log.Debugf("update Func with service: %s", s.ID())
-		if err := s.UpdatePod(old, new); err != nil {
-			log.Errorf("service %s update func failed: %v", s.ID(), err)
+	manager.deletingLock.Lock()
+	defer manager.deletingLock.Unlock()
+	pod, ok := manager.deleting[podInfo.UID]
+	if !ok {
+		pod = &deletingPod{path: podInfo.Path, services: make(map[string]struct{}, len(queues))}
+		manager.deleting[podInfo.UID] = pod
+	}
+	for name := range queues {
+		pod.services[name] = struct{}{}
+	}
+}
+
+// deletionHandled forgets the pods after the last service handles their deletions,
+// the deletions of all pods are taken as handled by the service if uid is empty
+func (manager *ServiceManager) deletionHandled(service, uid string) {
+	manager.deletingLock.Lock()
+	defer manager.deletingLock.Unlock()
+	for podUID, pod := range manager.deleting {
+		if uid != "" && podUID != uid {
+			continue
+		}
+		delete(pod.services, service)
+		if len(pod.services) == 0 {
+			delete(manager.deleting, podUID)
+			forgetPod(podUID, pod.path)
 		}
-		wg.Done()
 	}
-	manager.RLock()
-	var wg sync.WaitGroup
-	for _, s := range manager.RunningServices {
-		wg.Add(1)
-		go runOnce(s, podInfos[0], podInfos[1], &wg)
+}
+
+// forgetPod drops the writes and the original states of the files of the deleted pod
+func forgetPod(uid, path string) {
+	cgroup.ForgetWrites(path)
+	audit.ForgetPod(uid)
+}
+
+// queue returns the queue delivering the pod events to the service in order,
+// so that a slow service does not delay the events of the other services
+func (manager *ServiceManager) queue(name string, s services.Service) *publisher.Queue {
+	manager.queueLock.Lock()
+	defer manager.queueLock.Unlock()
+	if q, existed := manager.queues[name]; existed && q.service == s {
+		return q.Queue
 	}
-	wg.Wait()
-	manager.RUnlock()
+	manager.closeQueueLocked(name)
+	q := &serviceQueue{
+		Queue: publisher.NewQueue(serviceQueuePrefix+name, func(event typedef.Event) {
+			manager.handleServiceEvent(name, s, event)
+		}),
+		service: s,
+	}
+	manager.queues[name] = q
+	return q.Queue
 }
 
-// deleteFunc handles pod deletion events
-func (manager *ServiceManager) deleteFunc(event typedef.Event) {
-	podInfo, ok := event.(*typedef.PodInfo)
-	if !ok {
-		log.Warnf("receive invalid event: %T", event)
+// closeQueue discards the pending events of the service which no longer handles the pods.
+// The event being handled is not waited for, the caller locks the service to wait for it if necessary.
+func (manager *ServiceManager) closeQueue(name string) {
+	manager.queueLock.Lock()
+	manager.closeQueueLocked(name)
+	manager.queueLock.Unlock()
+}
+
+func (manager *ServiceManager) closeQueueLocked(name string) {
+	if q, existed := manager.queues[name]; existed {
+		q.Stop()
+		delete(manager.queues, name)
+		// the pending deletions are discarded with the queue
+		manager.deletionHandled(name, "")
+	}
+}
+
+// closeQueues closes the queues of all services and waits for the events being handled,
+// the manager lock must not be held since the handlers take it to check the services
+func (manager *ServiceManager) closeQueues() {
+	manager.queueLock.Lock()
+	defer manager.queueLock.Unlock()
+	for name, q := range manager.queues {
+		q.Close()
+		delete(manager.queues, name)
+		manager.deletionHandled(name, "")
+	}
+}
+
+// handleServiceEvent handles the pod event by the service
+func (manager *ServiceManager) handleServiceEvent(name string, s services.Service, event typedef.Event) {
+	deletion, deleted := event.(typedef.PodDeleteEvent)
+	if deleted {
+		defer manager.deletionHandled(name, deletion.Pod.UID)
+	}
+	// the service is not reconfigured or terminated while it handles the event,
+	// and the manager lock is only held to check the service so that a slow service blocks nobody else
+	defer manager.lockService(name)()
+	// the service is stopped, paused or replaced after the event is queued
+	state, current := manager.serviceState(name, s)
+	if !current || state == admin.ServiceStopped || (state == admin.ServicePaused && !deleted) {
 		return
 	}
+	switch e := event.(type) {
+	case typedef.PodAddEvent:
+		const retryCount = 5
+		for i := 0; i < retryCount; i++ {
+			if err := s.AddPod(e.Pod); err != nil {
+				podLogger(typedef.INFOADD, e.Pod, name).Errorf("service %s add func failed: %v", name, err)
+				metrics.ServiceErrors.Inc(name, "add")
+			} else {
+				break
+			}
+		}
+	case typedef.PodUpdateEvent:
+		logger := podLogger(typedef.INFOUPDATE, e.New, name)
+		logger.Debugf("update Func with service: %s", name)
+		if err := s.UpdatePod(e.Old, e.New); err != nil {
+			logger.Errorf("service %s update func failed: %v", name, err)
+			metrics.ServiceErrors.Inc(name, "update")
+		}
+	case typedef.PodDeleteEvent:
+		if err := s.DeletePod(e.Pod); err != nil {
+			podLogger(typedef.INFODELETE, e.Pod, name).Errorf("service %s delete func failed: %v", name, err)
+			metrics.ServiceErrors.Inc(name, "delete")
+		}
+	case typedef.ReconcileEvent:
+		if r, ok := s.(services.Reconciler); ok {
+			manager.reconcileService(name, s, r)
+		}
+	}
+}
+
+// serviceViewer lists the pods whose cgroup files are written by the service
+type serviceViewer struct {
+	api.Viewer
+	service string
+}
+
+// ownedViewer returns the viewer used by the service, so that the writes of the service to the pods
+// listed by the viewer are attributed to the service
+func ownedViewer(v api.Viewer, service string) api.Viewer {
+	if v == nil {
+		return nil
+	}
+	return &serviceViewer{Viewer: v, service: service}
+}
 
-	deleteOnce := func(s services.Service, podInfo *typedef.PodInfo, wg *sync.WaitGroup) {
-		if err := s.DeletePod(podInfo); err != nil {
-			log.Errorf("service %s delete func failed: %v", s.ID(), err)
+// ListPodsWithOptions returns the pods owned by the service, the pods listed by the viewer are already copied
+func (v *serviceViewer) ListPodsWithOptions(options ...api.ListOption) map[string]*typedef.PodInfo {
+	pods := v.Viewer.ListPodsWithOptions(options...)
+	for _, pod := range pods {
+		pod.SetOwner(v.service)
+	}
+	return pods
+}
+
+// ListContainersWithOptions returns the containers owned by the service
+func (v *serviceViewer) ListContainersWithOptions(options ...api.ListOption) map[string]*typedef.ContainerInfo {
+	conts := make(map[string]*typedef.ContainerInfo)
+	for _, pod := range v.ListPodsWithOptions(options...) {
+		for _, ci := range pod.IDContainersMap {
+			conts[ci.ID] = ci
 		}
-		wg.Done()
 	}
-	manager.RLock()
-	var wg sync.WaitGroup
-	for _, s := range manager.RunningServices {
-		wg.Add(1)
-		go deleteOnce(s, podInfo.DeepCopy(), &wg)
+	return conts
+}
+
+// ownedCopy returns the copy of the pod whose cgroup files are written by the service
+func ownedCopy(pod *typedef.PodInfo, service string) *typedef.PodInfo {
+	copied := pod.DeepCopy()
+	if copied != nil {
+		copied.SetOwner(service)
 	}
-	wg.Wait()
-	manager.RUnlock()
+	return copied
 }
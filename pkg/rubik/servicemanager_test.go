@@ -0,0 +1,639 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file tests the service manager
+
+package rubik
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"isula.org/rubik/pkg/admin"
+	"isula.org/rubik/pkg/api"
+	"isula.org/rubik/pkg/common/audit"
+	"isula.org/rubik/pkg/common/checkpoint"
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/config"
+	"isula.org/rubik/pkg/core/publisher"
+	"isula.org/rubik/pkg/core/typedef"
+	"isula.org/rubik/pkg/core/typedef/cgroup"
+	"isula.org/rubik/pkg/podmanager"
+	"isula.org/rubik/pkg/services/helper"
+)
+
+const (
+	fakeServiceA = "fakeServiceA"
+	fakeServiceB = "fakeServiceB"
+)
+
+type fakeConfig struct {
+	Value int `json:"value"`
+	// FailPreStart makes PreStart fail
+	FailPreStart bool `json:"failPreStart,omitempty"`
+}
+
+type fakeService struct {
+	helper.ServiceBase
+	conf       fakeConfig
+	setCount   int
+	terminated bool
+	running    int32
+	// preStartErr is returned by PreStart
+	preStartErr error
+	// setConfigErr is returned by SetConfig, the configuration is still checked by a new service
+	setConfigErr error
+	preStarts    int
+	added        int32
+	// reconciled counts the pods reconciled
+	reconciled int32
+	// block blocks AddPod until it is closed if it is not nil
+	block chan struct{}
+	// onReconcile is called when a pod is reconciled if it is not nil
+	onReconcile func()
+}
+
+type fakeFactory struct {
+	ObjName string
+}
+
+func (f fakeFactory) Name() string {
+	return "fakeFactory"
+}
+
+func (f fakeFactory) NewObj() (interface{}, error) {
+	return &fakeService{ServiceBase: helper.ServiceBase{Name: f.ObjName}}, nil
+}
+
+func (s *fakeService) SetConfig(f helper.ConfigHandler) error {
+	if s.setConfigErr != nil {
+		return s.setConfigErr
+	}
+	var conf fakeConfig
+	if err := f(s.Name, &conf); err != nil {
+		return err
+	}
+	if conf.Value < 0 {
+		return fmt.Errorf("negative value")
+	}
+	s.conf = conf
+	s.setCount++
+	return nil
+}
+
+func (s *fakeService) GetConfig() interface{} {
+	return s.conf
+}
+
+func (s *fakeService) IsRunner() bool {
+	return true
+}
+
+func (s *fakeService) Run(ctx context.Context) {
+	atomic.AddInt32(&s.running, 1)
+	<-ctx.Done()
+	atomic.AddInt32(&s.running, -1)
+}
+
+func (s *fakeService) PreStart(api.Viewer) error {
+	s.preStarts++
+	if s.conf.FailPreStart {
+		return fmt.Errorf("failed to pre-start")
+	}
+	return s.preStartErr
+}
+
+func (s *fakeService) AddPod(*typedef.PodInfo) error {
+	if s.block != nil {
+		<-s.block
+	}
+	atomic.AddInt32(&s.added, 1)
+	return nil
+}
+
+func (s *fakeService) Terminate(api.Viewer) error {
+	s.terminated = true
+	return nil
+}
+
+func (s *fakeService) Reconcile(pod *typedef.PodInfo) ([]*helper.Drift, error) {
+	onReconcile := s.onReconcile
+	atomic.AddInt32(&s.reconciled, 1)
+	if onReconcile != nil {
+		onReconcile()
+	}
+	return []*helper.Drift{{Path: pod.Path, File: "cpu.qos_level", Expected: "-1", Actual: "0"}}, nil
+}
+
+func (s *fakeService) isRunning() bool {
+	return atomic.LoadInt32(&s.running) == 1
+}
+
+// fakeViewer lists the fixed pods
+type fakeViewer map[string]*typedef.PodInfo
+
+func (v fakeViewer) ListPodsWithOptions(...api.ListOption) map[string]*typedef.PodInfo {
+	pods := make(map[string]*typedef.PodInfo, len(v))
+	for uid, pod := range v {
+		pods[uid] = pod.DeepCopy()
+	}
+	return pods
+}
+
+func (v fakeViewer) ListContainersWithOptions(...api.ListOption) map[string]*typedef.ContainerInfo {
+	return nil
+}
+
+func init() {
+	helper.AddFactory(fakeServiceA, fakeFactory{ObjName: fakeServiceA})
+	helper.AddFactory(fakeServiceB, fakeFactory{ObjName: fakeServiceB})
+}
+
+func parseServiceConfig(t *testing.T, data string) map[string]interface{} {
+	c := config.NewConfig(config.JSON)
+	fields, err := c.ParseConfig([]byte(data))
+	assert.NoError(t, err)
+	return fields
+}
+
+// TestServiceManager_Reload tests Reload
+func TestServiceManager_Reload(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	parser := config.NewConfig(config.JSON)
+	manager := NewServiceManager()
+	assert.NoError(t, manager.InitServices([]string{fakeServiceA},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 1}}`), parser))
+	manager.Start(ctx)
+	a, ok := manager.RunningServices[fakeServiceA].(*fakeService)
+	assert.True(t, ok)
+	assert.Eventually(t, a.isRunning, time.Second, time.Millisecond)
+
+	// unchanged configuration is not set again
+	assert.NoError(t, manager.Reload([]string{fakeServiceA},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 1}}`), parser))
+	assert.Equal(t, 1, a.setCount)
+
+	// changed service is reconfigured and restarted, new service is started
+	assert.NoError(t, manager.Reload([]string{fakeServiceA, fakeServiceB},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 2}, "fakeServiceB": {"value": 1}}`), parser))
+	assert.Equal(t, 2, a.setCount)
+	assert.Equal(t, 2, a.conf.Value)
+	assert.Eventually(t, a.isRunning, time.Second, time.Millisecond)
+	b, ok := manager.RunningServices[fakeServiceB].(*fakeService)
+	assert.True(t, ok)
+	assert.Eventually(t, b.isRunning, time.Second, time.Millisecond)
+
+	// nothing changes if any configuration is invalid
+	assert.Error(t, manager.Reload([]string{fakeServiceB},
+		parseServiceConfig(t, `{"fakeServiceB": {"value": -1}}`), parser))
+	assert.Error(t, manager.Reload([]string{fakeServiceA, fakeServiceA},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 3}}`), parser))
+	assert.Error(t, manager.Reload([]string{fakeServiceA, "notExistedService"},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 3}}`), parser))
+	assert.Len(t, manager.RunningServices, 2)
+	assert.Equal(t, 2, a.conf.Value)
+	assert.Equal(t, 1, b.conf.Value)
+	assert.False(t, a.terminated)
+
+	// disabled service is terminated
+	assert.NoError(t, manager.Reload([]string{fakeServiceB},
+		parseServiceConfig(t, `{"fakeServiceB": {"value": 1}}`), parser))
+	assert.Len(t, manager.RunningServices, 1)
+	assert.True(t, a.terminated)
+	assert.False(t, a.isRunning())
+	assert.True(t, b.isRunning())
+	statuses := manager.ServiceStatuses()
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, fakeServiceB, statuses[0].Name)
+	assert.True(t, statuses[0].Runner)
+	assert.Equal(t, fakeConfig{Value: 1}, statuses[0].Config)
+
+	// the required service failed to start is reported, while the other services are still changed
+	assert.Error(t, manager.Reload([]string{fakeServiceA, fakeServiceB},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 1, "failPreStart": true}, "fakeServiceB": {"value": 2}}`),
+		parser))
+	assert.Len(t, manager.RunningServices, 1)
+	assert.Equal(t, 2, b.conf.Value)
+	// the service failed is started by the next reload
+	assert.NoError(t, manager.Reload([]string{fakeServiceA, fakeServiceB},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 1}, "fakeServiceB": {"value": 2}}`), parser))
+	assert.Len(t, manager.RunningServices, 2)
+}
+
+// TestServiceManager_ReloadPods tests the current pods are replayed to the reconfigured services
+// unless they are paused
+func TestServiceManager_ReloadPods(t *testing.T) {
+	parser := config.NewConfig(config.JSON)
+	manager := NewServiceManager()
+	assert.NoError(t, manager.InitServices([]string{fakeServiceA},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 1}}`), parser))
+	assert.NoError(t, manager.Setup(fakeViewer{"uid-a": {UID: "uid-a", Name: "pod-a"}}))
+	a, ok := manager.RunningServices[fakeServiceA].(*fakeService)
+	assert.True(t, ok)
+
+	assert.NoError(t, manager.Reload([]string{fakeServiceA, fakeServiceB},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 2}, "fakeServiceB": {"value": 1}}`), parser))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&a.added))
+	b, ok := manager.RunningServices[fakeServiceB].(*fakeService)
+	assert.True(t, ok)
+	assert.Equal(t, 1, b.preStarts)
+	// the pods are not replayed to the paused service until it resumes
+	assert.NoError(t, manager.ControlService(fakeServiceA, admin.PauseService))
+	assert.NoError(t, manager.Reload([]string{fakeServiceA, fakeServiceB},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 3}, "fakeServiceB": {"value": 1}}`), parser))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&a.added))
+	assert.NoError(t, manager.ControlService(fakeServiceA, admin.ResumeService))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&a.added))
+}
+
+// TestServiceManager_ValidateConfig tests ValidateConfig
+func TestServiceManager_ValidateConfig(t *testing.T) {
+	parser := config.NewConfig(config.JSON)
+	manager := NewServiceManager()
+	assert.NoError(t, manager.InitServices([]string{fakeServiceA},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 1}}`), parser))
+	a, ok := manager.RunningServices[fakeServiceA].(*fakeService)
+	assert.True(t, ok)
+
+	assert.NoError(t, manager.ValidateConfig([]string{fakeServiceA, fakeServiceB},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 2}, "fakeServiceB": {"value": 1}}`), parser))
+	assert.Error(t, manager.ValidateConfig([]string{fakeServiceB},
+		parseServiceConfig(t, `{"fakeServiceB": {"value": -1}}`), parser))
+	assert.Error(t, manager.ValidateConfig([]string{fakeServiceA, fakeServiceA},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 1}}`), parser))
+	assert.Error(t, manager.ValidateConfig([]string{"notExistedService"}, nil, parser))
+	// the running services are not changed
+	assert.Len(t, manager.RunningServices, 1)
+	assert.Equal(t, 1, a.conf.Value)
+	assert.Equal(t, 1, a.setCount)
+}
+
+// TestServiceManager_CheckRestartBudget tests CheckRestartBudget
+func TestServiceManager_CheckRestartBudget(t *testing.T) {
+	manager := NewServiceManager()
+	h := &serviceHealth{}
+	manager.health[fakeServiceA] = h
+	for i := 0; i < restartBudget; i++ {
+		h.recordRestart()
+	}
+	assert.NoError(t, manager.CheckRestartBudget())
+	h.recordRestart()
+	assert.Error(t, manager.CheckRestartBudget())
+	// the restarts out of the window are not counted
+	for i := range h.restartTimes {
+		h.restartTimes[i] = h.restartTimes[i].Add(-restartBudgetWindow - time.Second)
+	}
+	h.recordRestart()
+	assert.NoError(t, manager.CheckRestartBudget())
+	assert.Len(t, h.restartTimes, 1)
+	assert.False(t, manager.PreStarted())
+
+	// the liveness check does not wait for the manager lock held by reloading or controlling the services
+	checked := make(chan error, 1)
+	manager.Lock()
+	go func() {
+		checked <- manager.CheckRestartBudget()
+	}()
+	select {
+	case err := <-checked:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Error("CheckRestartBudget waits for the manager lock")
+	}
+	manager.Unlock()
+}
+
+// TestServiceManager_Setup tests the required and optional services failed to pre-start
+func TestServiceManager_Setup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	parser := config.NewConfig(config.JSON)
+	viewer := podmanager.NewPodManager(publisher.GetPublisherFactory().GetPublisher(publisher.GENERIC))
+	newManager := func(optional ...string) (*ServiceManager, *fakeService) {
+		manager := NewServiceManager()
+		assert.NoError(t, manager.InitServices([]string{fakeServiceA, fakeServiceB},
+			parseServiceConfig(t, `{"fakeServiceA": {"value": 1}, "fakeServiceB": {"value": 1}}`), parser))
+		manager.SetOptionalFeatures(optional)
+		a, ok := manager.RunningServices[fakeServiceA].(*fakeService)
+		assert.True(t, ok)
+		a.preStartErr = fmt.Errorf("resctrl is not mounted")
+		return manager, a
+	}
+
+	// the required service failed stops rubik
+	manager, _ := newManager()
+	assert.Error(t, manager.Setup(viewer))
+	assert.False(t, manager.PreStarted())
+
+	// the optional service failed is degraded and the others keep running
+	manager, a := newManager(fakeServiceA)
+	assert.NoError(t, manager.Setup(viewer))
+	assert.True(t, manager.PreStarted())
+	manager.Start(ctx)
+	assert.Len(t, manager.RunningServices, 1)
+	statuses := manager.ServiceStatuses()
+	assert.Len(t, statuses, 2)
+	for _, status := range statuses {
+		assert.Equal(t, status.Name == fakeServiceA, status.Degraded)
+	}
+	d := manager.degraded[fakeServiceA]
+	assert.NotNil(t, d)
+	assert.False(t, manager.retryPreStart(fakeServiceA, d))
+	assert.Equal(t, int64(1), d.retries)
+
+	// the degraded service runs once it is pre-started
+	a.preStartErr = nil
+	assert.True(t, manager.retryPreStart(fakeServiceA, d))
+	assert.Len(t, manager.degraded, 0)
+	assert.Len(t, manager.RunningServices, 2)
+	assert.Eventually(t, a.isRunning, time.Second, time.Millisecond)
+
+	// the degraded service is dropped once it is disabled
+	manager, _ = newManager(fakeServiceA)
+	assert.NoError(t, manager.Setup(viewer))
+	assert.NoError(t, manager.Reload([]string{fakeServiceB},
+		parseServiceConfig(t, `{"fakeServiceB": {"value": 1}}`), parser))
+	assert.Len(t, manager.degraded, 0)
+	assert.Len(t, manager.ServiceStatuses(), 1)
+}
+
+// TestServiceManager_ControlService tests pausing, resuming, stopping and starting a service at runtime
+func TestServiceManager_ControlService(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	parser := config.NewConfig(config.JSON)
+	viewer := podmanager.NewPodManager(publisher.GetPublisherFactory().GetPublisher(publisher.GENERIC))
+	manager := NewServiceManager()
+	assert.NoError(t, manager.InitServices([]string{fakeServiceA},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 1}}`), parser))
+	assert.NoError(t, manager.Setup(viewer))
+	manager.Start(ctx)
+	a, ok := manager.RunningServices[fakeServiceA].(*fakeService)
+	assert.True(t, ok)
+	assert.Eventually(t, a.isRunning, time.Second, time.Millisecond)
+	pod := &typedef.PodInfo{UID: "uid-a"}
+
+	_, notFound := manager.ControlService(fakeServiceB, admin.PauseService).(admin.ServiceNotFoundError)
+	assert.True(t, notFound)
+	assert.Error(t, manager.ControlService(fakeServiceA, admin.ResumeService))
+	assert.Error(t, manager.ControlService(fakeServiceA, admin.StartService))
+
+	// the paused service does not run or handle the pods
+	assert.NoError(t, manager.ControlService(fakeServiceA, admin.PauseService))
+	assert.False(t, a.isRunning())
+	manager.addFunc(pod)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&a.added))
+	assert.Equal(t, admin.ServicePaused, manager.ServiceStatuses()[0].State)
+	assert.NoError(t, manager.ControlService(fakeServiceA, admin.ResumeService))
+	assert.Eventually(t, a.isRunning, time.Second, time.Millisecond)
+	manager.addFunc(pod)
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&a.added) == 1 }, time.Second, time.Millisecond)
+
+	// the stopped service is terminated and pre-started again when it starts
+	assert.NoError(t, manager.ControlService(fakeServiceA, admin.StopService))
+	assert.True(t, a.terminated)
+	assert.False(t, a.isRunning())
+	assert.Error(t, manager.ControlService(fakeServiceA, admin.StopService))
+	a.preStartErr = fmt.Errorf("failed")
+	assert.Error(t, manager.ControlService(fakeServiceA, admin.StartService))
+	assert.Equal(t, admin.ServiceStopped, manager.ServiceStatuses()[0].State)
+	a.preStartErr = nil
+	assert.NoError(t, manager.ControlService(fakeServiceA, admin.StartService))
+	assert.Equal(t, 3, a.preStarts)
+	assert.Eventually(t, a.isRunning, time.Second, time.Millisecond)
+	assert.Empty(t, manager.ServiceStatuses()[0].State)
+}
+
+// TestServiceManager_HandleEvent tests the service handling a pod event blocks neither the manager
+// nor the other services, and it is terminated after it finishes handling the event
+func TestServiceManager_HandleEvent(t *testing.T) {
+	parser := config.NewConfig(config.JSON)
+	manager := NewServiceManager()
+	assert.NoError(t, manager.InitServices([]string{fakeServiceA, fakeServiceB},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 1}, "fakeServiceB": {"value": 1}}`), parser))
+	assert.NoError(t, manager.Setup(fakeViewer{}))
+	a, ok := manager.RunningServices[fakeServiceA].(*fakeService)
+	assert.True(t, ok)
+	b, ok := manager.RunningServices[fakeServiceB].(*fakeService)
+	assert.True(t, ok)
+	a.block = make(chan struct{})
+
+	manager.addFunc(&typedef.PodInfo{UID: "uid-a"})
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&b.added) == 1 }, time.Second, time.Millisecond)
+	assert.Len(t, manager.ServiceStatuses(), 2)
+	stopped := make(chan error)
+	go func() {
+		stopped <- manager.ControlService(fakeServiceA, admin.StopService)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("service is stopped while it is adding the pod")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(a.block)
+	assert.NoError(t, <-stopped)
+	assert.True(t, a.terminated)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&a.added))
+}
+
+// TestServiceManager_Stop tests the services are terminated when rubik exits only if revertOnExit is set
+func TestServiceManager_Stop(t *testing.T) {
+	parser := config.NewConfig(config.JSON)
+	for _, revert := range []bool{true, false} {
+		manager := NewServiceManager()
+		assert.NoError(t, manager.InitServices([]string{fakeServiceA},
+			parseServiceConfig(t, `{"fakeServiceA": {"value": 1}}`), parser))
+		assert.NoError(t, manager.Setup(fakeViewer{}))
+		manager.SetRevertOnExit(revert)
+		a, ok := manager.RunningServices[fakeServiceA].(*fakeService)
+		assert.True(t, ok)
+		assert.NoError(t, manager.Stop())
+		assert.Equal(t, revert, a.terminated)
+	}
+}
+
+// TestServiceManager_ResumeService tests the pods added while the service is paused are handled after it resumes
+func TestServiceManager_ResumeService(t *testing.T) {
+	parser := config.NewConfig(config.JSON)
+	viewer := fakeViewer{}
+	manager := NewServiceManager()
+	assert.NoError(t, manager.InitServices([]string{fakeServiceA},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 1}}`), parser))
+	assert.NoError(t, manager.Setup(viewer))
+	a, ok := manager.RunningServices[fakeServiceA].(*fakeService)
+	assert.True(t, ok)
+
+	assert.NoError(t, manager.ControlService(fakeServiceA, admin.PauseService))
+	pod := &typedef.PodInfo{UID: "uid-a", Name: "pod-a"}
+	viewer[pod.UID] = pod
+	manager.addFunc(pod)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&a.added))
+	assert.NoError(t, manager.ControlService(fakeServiceA, admin.ResumeService))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&a.added))
+}
+
+// TestServiceManager_Reconcile tests reconciling the pods by the services implementing Reconciler
+func TestServiceManager_Reconcile(t *testing.T) {
+	assert.NoError(t, checkReconcileInterval(0))
+	assert.NoError(t, checkReconcileInterval(minReconcileInterval))
+	assert.Error(t, checkReconcileInterval(minReconcileInterval-1))
+	assert.Error(t, checkReconcileInterval(maxReconcileInterval+1))
+
+	parser := config.NewConfig(config.JSON)
+	manager := NewServiceManager()
+	assert.Error(t, manager.SetReconcileInterval(-1))
+	assert.NoError(t, manager.SetReconcileInterval(minReconcileInterval))
+	defer manager.closeQueues()
+	assert.NoError(t, manager.InitServices([]string{fakeServiceA},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 1}}`), parser))
+	// the manager without the viewer reconciles nothing
+	manager.reconcile()
+	assert.NoError(t, manager.Setup(fakeViewer{
+		"uid-a": {UID: "uid-a", Name: "pod-a"},
+		"uid-b": {UID: "uid-b", Name: "pod-b"},
+	}))
+	a, ok := manager.RunningServices[fakeServiceA].(*fakeService)
+	assert.True(t, ok)
+	reconciled := func(n int32) func() bool {
+		return func() bool { return atomic.LoadInt32(&a.reconciled) == n }
+	}
+	manager.reconcile()
+	assert.Eventually(t, reconciled(2), time.Second, time.Millisecond)
+
+	// the pods are reconciled after the service handles the pod events queued before
+	a.block = make(chan struct{})
+	manager.addFunc(&typedef.PodInfo{UID: "uid-c"})
+	manager.reconcile()
+	time.Sleep(10 * time.Millisecond)
+	assert.True(t, reconciled(2)())
+	close(a.block)
+	assert.Eventually(t, reconciled(4), time.Second, time.Millisecond)
+
+	// nothing is reconciled in dry-run mode
+	audit.SetDryRun(t.Logf)
+	manager.reconcile()
+	audit.SetDryRun(nil)
+	time.Sleep(10 * time.Millisecond)
+	assert.True(t, reconciled(4)())
+
+	// the service paused while reconciling stops before the remaining pods
+	a.onReconcile = func() {
+		a.onReconcile = nil
+		assert.NoError(t, manager.ControlService(fakeServiceA, admin.PauseService))
+	}
+	manager.reconcile()
+	assert.Eventually(t, reconciled(5), time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	assert.True(t, reconciled(5)())
+
+	// the paused service does not reconcile the pods
+	manager.reconcile()
+	time.Sleep(10 * time.Millisecond)
+	assert.True(t, reconciled(5)())
+}
+
+// TestServiceManager_DeletePod tests the deleted pod is forgotten after the services handle the pending events
+func TestServiceManager_DeletePod(t *testing.T) {
+	parser := config.NewConfig(config.JSON)
+	manager := NewServiceManager()
+	assert.NoError(t, manager.InitServices([]string{fakeServiceA, fakeServiceB},
+		parseServiceConfig(t, `{"fakeServiceA": {"value": 1}, "fakeServiceB": {"value": 1}}`), parser))
+	assert.NoError(t, manager.Setup(fakeViewer{}))
+	a, ok := manager.RunningServices[fakeServiceA].(*fakeService)
+	assert.True(t, ok)
+	a.block = make(chan struct{})
+	deleting := func() int {
+		manager.deletingLock.Lock()
+		defer manager.deletingLock.Unlock()
+		return len(manager.deleting)
+	}
+
+	pod := &typedef.PodInfo{UID: "uid-a", Hierarchy: cgroup.Hierarchy{Path: "kubepods/poduid-a"}}
+	manager.addFunc(pod)
+	manager.deleteFunc(pod)
+	// fakeServiceA is still adding the pod
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 1, deleting())
+	close(a.block)
+	assert.Eventually(t, func() bool { return deleting() == 0 }, time.Second, time.Millisecond)
+
+	// the pending deletions are discarded with the queue of the service
+	a.block = make(chan struct{})
+	manager.addFunc(pod)
+	manager.deleteFunc(pod)
+	manager.closeQueue(fakeServiceA)
+	assert.Eventually(t, func() bool { return deleting() == 0 }, time.Second, time.Millisecond)
+	close(a.block)
+}
+
+// checkpointService is the fake service keeping its state across restarts
+type checkpointService struct {
+	*fakeService
+}
+
+func (s checkpointService) Checkpoint() (*checkpoint.State, error) {
+	return checkpoint.NewState(1, s.conf)
+}
+
+func (s checkpointService) Restore(state *checkpoint.State) error {
+	return state.Decode(1, &s.conf)
+}
+
+// TestServiceManager_SaveCheckpoints tests the checkpoint file is written only for the services keeping states
+func TestServiceManager_SaveCheckpoints(t *testing.T) {
+	defer os.RemoveAll(constant.TmpTestDir)
+	store, err := checkpoint.Open(constant.TmpTestDir)
+	assert.NoError(t, err)
+	manager := NewServiceManager()
+	manager.checkpoints = store
+	a := &fakeService{ServiceBase: helper.ServiceBase{Name: fakeServiceA}}
+	manager.RunningServices[fakeServiceA] = a
+	file := filepath.Join(constant.TmpTestDir, "checkpoint.json")
+
+	manager.saveCheckpoints()
+	manager.dropCheckpoint(fakeServiceA)
+	_, err = os.Stat(file)
+	assert.True(t, os.IsNotExist(err))
+
+	manager.RunningServices[fakeServiceA] = checkpointService{fakeService: a}
+	manager.saveCheckpoints()
+	assert.FileExists(t, file)
+	assert.NotNil(t, store.Get(fakeServiceA))
+}
+
+// TestServiceManager_RestoreService tests the stale states are not restored
+func TestServiceManager_RestoreService(t *testing.T) {
+	defer os.RemoveAll(constant.TmpTestDir)
+	store, err := checkpoint.Open(constant.TmpTestDir)
+	assert.NoError(t, err)
+	manager := NewServiceManager()
+	manager.checkpoints = store
+	a := checkpointService{fakeService: &fakeService{ServiceBase: helper.ServiceBase{Name: fakeServiceA}}}
+	state, err := checkpoint.NewState(1, fakeConfig{Value: 2})
+	assert.NoError(t, err)
+	store.Set(fakeServiceA, state)
+
+	manager.restoreService(fakeServiceA, a)
+	assert.Equal(t, 2, a.conf.Value)
+	a.conf.Value = 1
+	state.Time = state.Time.Add(-checkpointMaxAge - time.Minute)
+	manager.restoreService(fakeServiceA, a)
+	assert.Equal(t, 1, a.conf.Value)
+}
@@ -0,0 +1,153 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements the validation mode which checks the configuration without running the agent
+
+package rubik
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"isula.org/rubik/pkg/common/audit"
+	"isula.org/rubik/pkg/common/checkpoint"
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/common/log"
+	"isula.org/rubik/pkg/config"
+	"isula.org/rubik/pkg/core/publisher"
+	"isula.org/rubik/pkg/core/typedef/cgroup"
+	"isula.org/rubik/pkg/podmanager"
+	"isula.org/rubik/pkg/services"
+	"isula.org/rubik/pkg/services/helper"
+)
+
+const (
+	// agentCheckName is the name of the check of the agent configuration in the report
+	agentCheckName = "agent"
+	// podRulesCheckName is the name of the check of the pod rules in the report
+	podRulesCheckName = "podRules"
+)
+
+// checkResult is the result of checking the agent or an enabled feature
+type checkResult struct {
+	name string
+	err  error
+}
+
+// validateConfig checks the configuration file and whether the node supports the enabled features,
+// prints the report to w and returns the exit code
+func validateConfig(opts *options, w io.Writer) int {
+	// only errors are logged to keep the report readable
+	log.DropError(log.InitConfig(constant.LogDriverStdio, "", constant.LogLevelError, constant.DefaultLogSize))
+	c, err := opts.loadConfig()
+	if err != nil {
+		fmt.Fprintf(w, "configuration %v is invalid: %v\n", opts.configFile, err)
+		return constant.ErrorExitCode
+	}
+	_, rulesErr := parsePodRules(c)
+	results := append([]checkResult{
+		{name: agentCheckName, err: checkAgentConfig(c.Agent)},
+		{name: podRulesCheckName, err: rulesErr},
+	}, checkFeatures(c)...)
+
+	var failed int
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tRESULT\tMESSAGE")
+	for _, r := range results {
+		var result, message = "OK", "-"
+		if r.err != nil {
+			result, message = "FAIL", r.err.Error()
+			failed++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.name, result, message)
+	}
+	log.DropError(tw.Flush())
+	if failed != 0 {
+		fmt.Fprintf(w, "configuration %v is invalid: %d of %d checks failed\n", opts.configFile, failed, len(results))
+		return constant.ErrorExitCode
+	}
+	fmt.Fprintf(w, "configuration %v is valid\n", opts.configFile)
+	return constant.NormalExitCode
+}
+
+// checkAgentConfig checks the agent configuration and initializes the cgroup for checking the features
+func checkAgentConfig(c *config.AgentConfig) error {
+	if err := cgroup.Init(cgroup.WithRoot(c.CgroupRoot), cgroup.WithDriver(c.CgroupDriver),
+		cgroup.WithVersion(c.CgroupVersion)); err != nil {
+		return err
+	}
+	if err := log.CheckConfig(c.LogDriver, c.LogDir, c.LogLevel, c.LogSize,
+		log.WithFormat(c.LogFormat), log.WithModuleLevels(c.LogModuleLevels)); err != nil {
+		return err
+	}
+	if c.EnableAudit {
+		if err := audit.CheckConfig(c.LogDir, c.AuditSize); err != nil {
+			return err
+		}
+	}
+	if err := publisher.CheckQueueConfig(c.EventQueueSize, c.EventOverflowPolicy); err != nil {
+		return err
+	}
+	if err := checkReconcileInterval(c.ReconcileInterval); err != nil {
+		return err
+	}
+	if _, err := podmanager.NewTranslator(c.Annotations); err != nil {
+		return fmt.Errorf("invalid annotations: %v", err)
+	}
+	if c.CheckpointDir != "" {
+		return checkpoint.CheckConfig(c.CheckpointDir)
+	}
+	return nil
+}
+
+// checkFeatures checks each enabled feature in order
+func checkFeatures(c *config.Config) []checkResult {
+	services.InitServiceComponents(defaultRubikFeature)
+	var (
+		handler = newConfigHandler(c.UnwrapServiceConfig(), c)
+		checked = make(map[string]struct{}, len(c.Agent.EnabledFeatures))
+		results = make([]checkResult, 0, len(c.Agent.EnabledFeatures))
+	)
+	for _, feature := range c.Agent.EnabledFeatures {
+		if _, existed := checked[feature]; existed {
+			results = append(results, checkResult{name: feature, err: fmt.Errorf("service name conflict: %s", feature)})
+			continue
+		}
+		checked[feature] = struct{}{}
+		results = append(results, checkResult{name: feature, err: checkFeature(feature, handler)})
+	}
+	return results
+}
+
+// checkFeature checks the configuration of the feature and whether the kernel supports it
+func checkFeature(feature string, handler helper.ConfigHandler) error {
+	s, err := services.GetServiceComponent(feature)
+	if err != nil {
+		return err
+	}
+	// applying the configuration may change the node, such as the iocost settings,
+	// so it is only applied to the service which can not check it without being applied
+	if v, ok := s.(services.ConfigValidator); ok {
+		err = v.ValidateConfig(handler)
+	} else {
+		err = s.SetConfig(handler)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %v", err)
+	}
+	if c, ok := s.(services.CapabilityChecker); ok {
+		if err := c.CheckCapability(); err != nil {
+			return fmt.Errorf("not supported by the node: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file tests the command-line options and the validation mode
+
+package rubik
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/common/util"
+	"isula.org/rubik/pkg/core/typedef/cgroup"
+)
+
+// TestParseOptions tests parsing the command-line options
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    *options
+		wantErr error
+	}{
+		{name: "TC1-no argument", args: []string{}, want: &options{configFile: constant.ConfigFile}},
+		{name: "TC2-all options", args: []string{"--config", "/tmp/rubik.json", "--log-level", "debug", "--validate"},
+			want: &options{configFile: "/tmp/rubik.json", logLevel: "debug", validate: true}},
+		{name: "TC3-short version", args: []string{"-v"},
+			want: &options{configFile: constant.ConfigFile, version: true}},
+		{name: "TC4-long version", args: []string{"--version"},
+			want: &options{configFile: constant.ConfigFile, version: true}},
+		{name: "TC5-help", args: []string{"-h"}, wantErr: flag.ErrHelp},
+		{name: "TC6-unknown option", args: []string{"--unknown"}},
+		{name: "TC7-positional argument", args: []string{"config.json"}},
+		{name: "TC8-empty config path", args: []string{"--config", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			got, err := parseOptions(tt.args, &output)
+			if tt.want == nil {
+				assert.Error(t, err)
+				if tt.wantErr != nil {
+					assert.Equal(t, tt.wantErr, err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestValidateConfig tests checking the configuration and printing the report
+func TestValidateConfig(t *testing.T) {
+	defer os.RemoveAll(constant.TmpTestDir)
+	defer func() {
+		assert.NoError(t, cgroup.Init(cgroup.WithRoot(constant.DefaultCgroupRoot),
+			cgroup.WithDriver(constant.CgroupDriverCgroupfs), cgroup.WithVersion(constant.CgroupVersionV1)))
+	}()
+	cgroupRoot := filepath.Join(constant.TmpTestDir, "cgroup")
+	assert.NoError(t, util.WriteFile(filepath.Join(cgroupRoot, "cpu", constant.KubepodsCgroup, "cpu.cfs_burst_us"), "0"))
+
+	writeConfig := func(name, agent string) string {
+		path := filepath.Join(constant.TmpTestDir, name)
+		assert.NoError(t, util.WriteFile(path, fmt.Sprintf(`{"agent": %s}`, agent)))
+		return path
+	}
+	tests := []struct {
+		name     string
+		opts     *options
+		code     int
+		contains []string
+	}{
+		{
+			name: "TC1-valid configuration",
+			opts: &options{configFile: writeConfig("valid.json",
+				`{"cgroupRoot": "`+cgroupRoot+`", "cgroupVersion": "v1", "enabledFeatures": ["quotaBurst"]}`)},
+			code:     constant.NormalExitCode,
+			contains: []string{"agent", "quotaBurst", "OK", "is valid"},
+		},
+		{
+			name: "TC2-unsupported feature and invalid log level override",
+			opts: &options{logLevel: "verbose", configFile: writeConfig("unsupported.json",
+				`{"cgroupVersion": "v1", "cgroupRoot": "`+constant.TmpTestDir+`", "enabledFeatures": ["quotaBurst"]}`)},
+			code: constant.ErrorExitCode,
+			contains: []string{"invalid log level: verbose", "not supported by the node",
+				"2 of 3 checks failed"},
+		},
+		{
+			name: "TC3-unknown and duplicated features",
+			opts: &options{configFile: writeConfig("unknown.json",
+				`{"cgroupRoot": "`+cgroupRoot+`", "enabledFeatures": ["unknown", "quotaBurst", "quotaBurst"]}`)},
+			code:     constant.ErrorExitCode,
+			contains: []string{"unknown", "service name conflict: quotaBurst", "2 of 5 checks failed"},
+		},
+		{
+			name: "TC3.1-invalid pod rules",
+			opts: &options{configFile: writeConfig("rules.json",
+				`{"cgroupRoot": "`+cgroupRoot+`"}, "podRules": [{"name": "batch", "set": {"priority": "low"}}]`)},
+			code:     constant.ErrorExitCode,
+			contains: []string{"podRules", "invalid pod rule batch", "1 of 2 checks failed"},
+		},
+		{
+			name:     "TC4-invalid configuration file",
+			opts:     &options{configFile: writeConfig("invalid.json", `[]`)},
+			code:     constant.ErrorExitCode,
+			contains: []string{"failed to parse agent config"},
+		},
+		{
+			name:     "TC5-missing configuration file",
+			opts:     &options{configFile: filepath.Join(constant.TmpTestDir, "missing.json")},
+			code:     constant.ErrorExitCode,
+			contains: []string{"failed to load config file"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			assert.Equal(t, tt.code, validateConfig(tt.opts, &output), output.String())
+			for _, s := range tt.contains {
+				assert.Contains(t, output.String(), s)
+			}
+		})
+	}
+}
+
+// TestValidateConfig_NoWrite tests the validation mode never changes the cgroup files
+func TestValidateConfig_NoWrite(t *testing.T) {
+	defer os.RemoveAll(constant.TmpTestDir)
+	defer func() {
+		assert.NoError(t, cgroup.Init(cgroup.WithRoot(constant.DefaultCgroupRoot),
+			cgroup.WithDriver(constant.CgroupDriverCgroupfs), cgroup.WithVersion(constant.CgroupVersionV1)))
+	}()
+	cgroupRoot := filepath.Join(constant.TmpTestDir, "cgroup")
+	assert.NoError(t, util.WriteFile(filepath.Join(cgroupRoot, "io.cost.qos"), "8:0 enable=1 ctrl=user"))
+	assert.NoError(t, util.WriteFile(filepath.Join(cgroupRoot, "io.cost.model"), "8:0 ctrl=user model=linear"))
+	snapshot := func() map[string]string {
+		files := make(map[string]string)
+		assert.NoError(t, filepath.Walk(cgroupRoot, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			files[path] = string(data)
+			return err
+		}))
+		return files
+	}
+	before := snapshot()
+
+	path := filepath.Join(constant.TmpTestDir, "iocost.json")
+	assert.NoError(t, util.WriteFile(path, `{"agent": {"cgroupRoot": "`+cgroupRoot+`", "cgroupVersion": "v2",
+		"enabledFeatures": ["ioCost"]}, "ioCost": [{"nodeName": "global", "config": []}]}`))
+	var output bytes.Buffer
+	assert.Equal(t, constant.NormalExitCode, validateConfig(&options{configFile: path}, &output), output.String())
+	assert.Equal(t, before, snapshot())
+}
+
+// TestAgent_ValidateConfig tests checking the configuration data sent by the admin API
+func TestAgent_ValidateConfig(t *testing.T) {
+	a := &Agent{servicesManager: NewServiceManager()}
+	assert.NoError(t, a.ValidateConfig([]byte(`{"agent": {"annotations": {"priority": [{"key": "example.com/tier"}]}}}`)))
+	assert.Error(t, a.ValidateConfig([]byte(`{"agent": {"annotations": {"cpuShares": [{"key": "example.com/shares"}]}}}`)))
+	assert.Error(t, a.ValidateConfig([]byte(`{"podRules": [{"name": "batch", "set": {"priority": "low"}}]}`)))
+}
@@ -0,0 +1,166 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file prints the responses of the admin API
+
+package rubikctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"isula.org/rubik/pkg/admin"
+	"isula.org/rubik/pkg/common/audit"
+	"isula.org/rubik/pkg/core/typedef"
+)
+
+// print prints v in JSON or prints it as the table by printTable
+func (c *ctl) print(v interface{}, printTable func(w io.Writer)) error {
+	if c.output == outputJSON {
+		return c.printJSON(v)
+	}
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	printTable(w)
+	return w.Flush()
+}
+
+func (c *ctl) printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(c.stdout, string(data))
+	return err
+}
+
+func printPods(w io.Writer, pods []*typedef.PodInfo) {
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tUID\tPRIORITY\tCONTAINERS")
+	for _, pod := range pods {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", pod.Namespace, pod.Name, pod.UID, pod.Priority(), len(pod.IDContainersMap))
+	}
+}
+
+func printServices(w io.Writer, services []admin.ServiceStatus) {
+	fmt.Fprintln(w, "NAME\tRUNNER\tRUNNING\tRESTARTS\tPANICS\tLAST PANIC\tLAST ERROR")
+	for _, s := range services {
+		var running, restarts, panics = "-", "-", "-"
+		if s.Runner {
+			running = fmt.Sprint(s.Running)
+			restarts = fmt.Sprint(s.Restarts)
+			panics = fmt.Sprint(s.Panics)
+		}
+		lastPanic, lastError := "-", "-"
+		if s.LastPanicTime != nil {
+			lastPanic = fmt.Sprintf("%v (%v)", s.LastPanic, s.LastPanicTime.Format(time.RFC3339))
+		}
+		if s.State != "" {
+			running = s.State
+		}
+		if s.Degraded {
+			running = "degraded"
+			lastError = fmt.Sprintf("%v (%d retries)", s.LastError, s.Retries)
+		}
+		fmt.Fprintf(w, "%s\t%v\t%s\t%s\t%s\t%s\t%s\n",
+			s.Name, s.Runner, running, restarts, panics, lastPanic, lastError)
+	}
+}
+
+// sortedKeys returns the keys of the map in order
+func sortedKeys(m map[string]string) []string {
+	var keys = make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// singleLine joins the lines of the cgroup file content to keep the table aligned
+func singleLine(s string) string {
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", ", ")
+}
+
+func printCgroupDetail(w io.Writer, indent string, detail admin.CgroupDetail) {
+	fmt.Fprintf(w, "%sCgroup Paths:\n", indent)
+	for _, subsys := range sortedKeys(detail.Paths) {
+		fmt.Fprintf(w, "%s  %s:\t%s\n", indent, subsys, detail.Paths[subsys])
+	}
+	fmt.Fprintf(w, "%sQoS Values:\n", indent)
+	if len(detail.Values) == 0 {
+		fmt.Fprintf(w, "%s  <none>\n", indent)
+	}
+	for _, file := range sortedKeys(detail.Values) {
+		fmt.Fprintf(w, "%s  %s:\t%s\n", indent, file, singleLine(detail.Values[file]))
+	}
+}
+
+func printPodDetail(w io.Writer, pod *admin.PodDetail) {
+	fmt.Fprintf(w, "Name:\t%s\n", pod.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", pod.Namespace)
+	fmt.Fprintf(w, "UID:\t%s\n", pod.UID)
+	fmt.Fprintf(w, "Priority:\t%s\n", pod.Priority())
+	if pod.Rule != "" {
+		fmt.Fprintf(w, "Rule:\t%s\n", pod.Rule)
+		for _, key := range sortedKeys(pod.RuleValues) {
+			fmt.Fprintf(w, "  %s:\t%s\n", key, pod.RuleValues[key])
+		}
+	}
+	printCgroupDetail(w, "", pod.Cgroup)
+	var ids = make([]string, 0, len(pod.IDContainersMap))
+	for id := range pod.IDContainersMap {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return pod.IDContainersMap[ids[i]].Name < pod.IDContainersMap[ids[j]].Name
+	})
+	fmt.Fprintln(w, "Containers:")
+	for _, id := range ids {
+		fmt.Fprintf(w, "  %s (%s):\n", pod.IDContainersMap[id].Name, id)
+		printCgroupDetail(w, "    ", pod.ContainerCgroups[id])
+	}
+}
+
+func printExplanation(w io.Writer, e *admin.PodExplanation) {
+	fmt.Fprintf(w, "Pod:\t%s/%s (%s)\n", e.Namespace, e.Name, e.UID)
+	fmt.Fprintf(w, "Running Services:\t%s\n", strings.Join(e.Services, ", "))
+	if len(e.Writes) == 0 {
+		fmt.Fprintln(w, "No values written to the cgroups of the pod are recorded.")
+		return
+	}
+	fmt.Fprintln(w, "\nTIME\tSERVICE\tCGROUP\tFILE\tVALUE")
+	for _, r := range e.Writes {
+		owner := r.Owner
+		if owner == "" {
+			owner = "<unknown>"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Time.Format(time.RFC3339), owner, r.Path, r.File, singleLine(r.Value))
+	}
+}
+
+func printAudit(w io.Writer, records []audit.Record) {
+	fmt.Fprintln(w, "TIME\tSERVICE\tPOD\tFILE\tOLD\tNEW")
+	for _, r := range records {
+		var service, pod = r.Service, r.PodUID
+		if service == "" {
+			service = "<unknown>"
+		}
+		if pod == "" {
+			pod = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Time.Format(time.RFC3339), service, pod, r.File,
+			singleLine(r.Old), singleLine(r.New))
+	}
+}
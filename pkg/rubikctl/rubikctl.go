@@ -0,0 +1,290 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file implements the commands of rubikctl
+
+// Package rubikctl implements the command-line client talking to the running rubik
+package rubikctl
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"isula.org/rubik/pkg/admin"
+	"isula.org/rubik/pkg/common/audit"
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/config"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+const usage = `Usage: rubikctl [options] <command> [arguments]
+
+Commands:
+  pods list [-n namespace]   list the pods cached by rubik
+  pod show <uid>             show the cgroup paths and the current qos values of the pod
+  services list              list the running services
+  services pause|resume|stop|start <name>
+                             pause, resume, stop or start the enabled service at runtime
+  config show                show the configuration in use
+  config validate [file]     check the configuration file and its drop-in fragments without applying it
+                             (default: %v)
+  explain <uid>              show which services acted on the pod and what values they wrote
+  audit [-pod uid] [-service name] [-file path] [-since time] [-limit n]
+                             show the latest writes in the audit journal, -since is a duration
+                             such as 1h or a time in RFC3339 format
+
+Options:
+`
+
+// ctl runs a command of rubikctl
+type ctl struct {
+	client *admin.Client
+	output string
+	stdout io.Writer
+}
+
+// command handles the arguments after the command name
+type command func(c *ctl, args []string) error
+
+var commands = map[string]command{
+	"pods":     (*ctl).pods,
+	"pod":      (*ctl).pod,
+	"services": (*ctl).services,
+	"config":   (*ctl).config,
+	"explain":  (*ctl).explain,
+	"audit":    (*ctl).audit,
+}
+
+// Run runs rubikctl with the arguments excluding the program name and returns the exit code
+func Run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("rubikctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	socket := flags.String("socket", constant.AdminSocket, "the admin socket of rubik")
+	output := flags.String("o", outputTable, "the output format, "+outputTable+" or "+outputJSON)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, usage, constant.ConfigFile)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return constant.NormalExitCode
+		}
+		return constant.ArgumentErrorExitCode
+	}
+	if *output != outputTable && *output != outputJSON {
+		fmt.Fprintf(stderr, "invalid output format: %v\n", *output)
+		return constant.ArgumentErrorExitCode
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return constant.ArgumentErrorExitCode
+	}
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command: %v\n", flags.Arg(0))
+		flags.Usage()
+		return constant.ArgumentErrorExitCode
+	}
+	c := &ctl{
+		client: admin.NewClient(*socket),
+		output: *output,
+		stdout: stdout,
+	}
+	if err := cmd(c, flags.Args()[1:]); err != nil {
+		fmt.Fprintf(stderr, "rubikctl %v: %v\n", flags.Arg(0), err)
+		if _, ok := err.(argumentError); ok {
+			return constant.ArgumentErrorExitCode
+		}
+		return constant.ErrorExitCode
+	}
+	return constant.NormalExitCode
+}
+
+// argumentError indicates the arguments of the command are invalid
+type argumentError string
+
+func (e argumentError) Error() string {
+	return string(e)
+}
+
+// subcommand returns the subcommand which is the first argument
+func subcommand(args []string, subcommands ...string) (string, error) {
+	if len(args) != 0 {
+		for _, s := range subcommands {
+			if args[0] == s {
+				return s, nil
+			}
+		}
+	}
+	return "", argumentError(fmt.Sprintf("expect subcommand %v", strings.Join(subcommands, " or ")))
+}
+
+// uidArgument returns the pod UID which is the only argument
+func uidArgument(args []string) (string, error) {
+	if len(args) != 1 || args[0] == "" {
+		return "", argumentError("expect the UID of the pod")
+	}
+	return args[0], nil
+}
+
+func (c *ctl) pods(args []string) error {
+	if _, err := subcommand(args, "list"); err != nil {
+		return err
+	}
+	flags := flag.NewFlagSet("pods list", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	namespace := flags.String("n", "", "the namespace of pods")
+	if err := flags.Parse(args[1:]); err != nil || flags.NArg() != 0 {
+		return argumentError("usage: pods list [-n namespace]")
+	}
+	pods, err := c.client.ListPods(*namespace)
+	if err != nil {
+		return err
+	}
+	return c.print(pods, func(w io.Writer) { printPods(w, pods) })
+}
+
+func (c *ctl) pod(args []string) error {
+	if _, err := subcommand(args, "show"); err != nil {
+		return err
+	}
+	uid, err := uidArgument(args[1:])
+	if err != nil {
+		return err
+	}
+	pod, err := c.client.GetPod(uid)
+	if err != nil {
+		return err
+	}
+	return c.print(pod, func(w io.Writer) { printPodDetail(w, pod) })
+}
+
+func (c *ctl) services(args []string) error {
+	const usage = "usage: services list | services pause|resume|stop|start <name>"
+	sub, err := subcommand(args, "list", string(admin.PauseService), string(admin.ResumeService),
+		string(admin.StopService), string(admin.StartService))
+	if err != nil {
+		return argumentError(usage)
+	}
+	if sub != "list" {
+		const argsLen = 2
+		if len(args) != argsLen || args[1] == "" {
+			return argumentError(usage)
+		}
+		if err := c.client.ControlService(args[1], admin.ServiceAction(sub)); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stdout, "service %v: %v succeeded\n", args[1], sub)
+		return nil
+	}
+	if len(args) != 1 {
+		return argumentError(usage)
+	}
+	services, err := c.client.ListServices()
+	if err != nil {
+		return err
+	}
+	return c.print(services, func(w io.Writer) { printServices(w, services) })
+}
+
+func (c *ctl) config(args []string) error {
+	sub, err := subcommand(args, "show", "validate")
+	if err != nil {
+		return err
+	}
+	if sub == "show" {
+		if len(args) != 1 {
+			return argumentError("usage: config show")
+		}
+		fields, err := c.client.GetConfig()
+		if err != nil {
+			return err
+		}
+		// the configuration is always printed in the format of the configuration file
+		return c.printJSON(fields)
+	}
+	const maxArgs = 2
+	if len(args) > maxArgs {
+		return argumentError("usage: config validate [file]")
+	}
+	var path = constant.ConfigFile
+	if len(args) == maxArgs {
+		path = args[1]
+	}
+	// the configuration is merged with the fragments of the drop-in directory in the same way as the agent
+	conf := config.NewConfig(config.JSON)
+	if err := conf.LoadConfig(path); err != nil {
+		return fmt.Errorf("invalid configuration %v: %v", path, err)
+	}
+	if err := c.client.ValidateConfig([]byte(conf.String())); err != nil {
+		return fmt.Errorf("invalid configuration %v: %v", path, err)
+	}
+	fmt.Fprintf(c.stdout, "configuration %v is valid\n", path)
+	return nil
+}
+
+func (c *ctl) explain(args []string) error {
+	uid, err := uidArgument(args)
+	if err != nil {
+		return err
+	}
+	explanation, err := c.client.ExplainPod(uid)
+	if err != nil {
+		return err
+	}
+	return c.print(explanation, func(w io.Writer) { printExplanation(w, explanation) })
+}
+
+func (c *ctl) audit(args []string) error {
+	const usage = "usage: audit [-pod uid] [-service name] [-file path] [-since time] [-limit n]"
+	flags := flag.NewFlagSet("audit", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	var (
+		q     audit.Query
+		since string
+	)
+	flags.StringVar(&q.PodUID, "pod", "", "the UID of the pod")
+	flags.StringVar(&q.Service, "service", "", "the service writing the files")
+	flags.StringVar(&q.File, "file", "", "the part of the path of the written files")
+	flags.StringVar(&since, "since", "", "the duration or the time in RFC3339 format")
+	flags.IntVar(&q.Limit, "limit", audit.DefaultLimit, "the maximum number of the latest writes")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 || q.Limit <= 0 {
+		return argumentError(usage)
+	}
+	if since != "" {
+		t, err := parseSince(since)
+		if err != nil {
+			return argumentError(fmt.Sprintf("invalid time %v, %v", since, usage))
+		}
+		q.Since = t
+	}
+	records, err := c.client.QueryAudit(q)
+	if err != nil {
+		return err
+	}
+	return c.print(records, func(w io.Writer) { printAudit(w, records) })
+}
+
+// parseSince parses the duration before now or the time in RFC3339 format
+func parseSince(since string) (time.Time, error) {
+	if d, err := time.ParseDuration(since); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, since)
+}
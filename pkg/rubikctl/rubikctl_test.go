@@ -0,0 +1,158 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file tests the commands of rubikctl
+
+package rubikctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"isula.org/rubik/pkg/admin"
+	"isula.org/rubik/pkg/common/audit"
+	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/common/util"
+	"isula.org/rubik/pkg/config"
+	"isula.org/rubik/pkg/core/typedef"
+	"isula.org/rubik/pkg/core/typedef/cgroup"
+)
+
+type fakeSource struct {
+	pods map[string]*typedef.PodInfo
+}
+
+func (s *fakeSource) ListPods() map[string]*typedef.PodInfo {
+	return s.pods
+}
+
+func (s *fakeSource) ListServices() []admin.ServiceStatus {
+	return []admin.ServiceStatus{{Name: "preemption"}, {Name: "quotaTurbo", Runner: true, Running: true},
+		{Name: "dynCache", Degraded: true, LastError: "resctrl is not mounted", Retries: 2}}
+}
+
+func (s *fakeSource) Config() *config.Config {
+	return config.NewConfig(config.JSON)
+}
+
+func (s *fakeSource) ValidateConfig(data []byte) error {
+	return config.NewConfig(config.JSON).LoadConfigData(data)
+}
+
+func (s *fakeSource) ControlService(name string, action admin.ServiceAction) error {
+	if name != "preemption" {
+		return admin.ServiceNotFoundError(name)
+	}
+	return nil
+}
+
+func (s *fakeSource) LivenessChecks() []admin.HealthCheck {
+	return nil
+}
+
+func (s *fakeSource) ReadinessChecks() []admin.HealthCheck {
+	return nil
+}
+
+// TestRun tests the commands of rubikctl
+func TestRun(t *testing.T) {
+	defer os.RemoveAll(constant.TmpTestDir)
+	socket := filepath.Join(constant.TmpTestDir, "rubik.sock")
+	pod := &typedef.PodInfo{
+		Name:      "a",
+		UID:       "uid-a",
+		Namespace: "default",
+		Hierarchy: cgroup.Hierarchy{MountPoint: constant.TmpTestDir, Path: "kubepods/poda", Owner: "preemption"},
+		IDContainersMap: map[string]*typedef.ContainerInfo{
+			"id-1": {Name: "c1", ID: "id-1", Hierarchy: cgroup.Hierarchy{MountPoint: constant.TmpTestDir,
+				Path: "kubepods/poda/id-1"}},
+		},
+	}
+	key := &cgroup.Key{SubSys: "cpu", FileName: constant.CPUCgroupFileName}
+	assert.NoError(t, util.WriteFile(filepath.Join(pod.AbsolutePath("cpu"), key.FileName), "0"))
+	assert.NoError(t, audit.Init(constant.TmpTestDir, 1, t.Errorf))
+	defer audit.Close()
+	assert.NoError(t, pod.SetCgroupAttr(key, "-1"))
+	defer cgroup.ForgetWrites(pod.Path)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, admin.NewServer(socket, &fakeSource{pods: map[string]*typedef.PodInfo{pod.UID: pod}}).Start(ctx))
+
+	validConfig := filepath.Join(constant.TmpTestDir, "valid.json")
+	assert.NoError(t, util.WriteFile(validConfig, `{"agent": {"logLevel": "debug"}}`))
+	invalidConfig := filepath.Join(constant.TmpTestDir, "invalid.json")
+	assert.NoError(t, util.WriteFile(invalidConfig, `{"agent": []}`))
+
+	tests := []struct {
+		name     string
+		args     []string
+		code     int
+		contains []string
+	}{
+		{name: "TC1-list pods", args: []string{"pods", "list"}, code: constant.NormalExitCode,
+			contains: []string{"NAMESPACE", "uid-a", "online"}},
+		{name: "TC2-list pods of namespace", args: []string{"pods", "list", "-n", "kube-system"},
+			code: constant.NormalExitCode, contains: []string{"NAMESPACE"}},
+		{name: "TC3-show pod", args: []string{"pod", "show", "uid-a"}, code: constant.NormalExitCode,
+			contains: []string{"Priority:", filepath.Join(constant.TmpTestDir, "cpu/kubepods/poda"),
+				constant.CPUCgroupFileName + ":", "c1 (id-1)", "<none>"}},
+		{name: "TC4-show non-existed pod", args: []string{"pod", "show", "uid-x"}, code: constant.ErrorExitCode},
+		{name: "TC5-list services", args: []string{"services", "list"}, code: constant.NormalExitCode,
+			contains: []string{"quotaTurbo", "true", "degraded", "resctrl is not mounted (2 retries)"}},
+		{name: "TC6-show config", args: []string{"config", "show"}, code: constant.NormalExitCode,
+			contains: []string{`"agent"`}},
+		{name: "TC7-validate valid config", args: []string{"config", "validate", validConfig},
+			code: constant.NormalExitCode, contains: []string{"is valid"}},
+		{name: "TC8-validate invalid config", args: []string{"config", "validate", invalidConfig},
+			code: constant.ErrorExitCode},
+		{name: "TC9-explain pod", args: []string{"explain", "uid-a"}, code: constant.NormalExitCode,
+			contains: []string{"preemption, quotaTurbo", "kubepods/poda", constant.CPUCgroupFileName, "-1"}},
+		{name: "TC10-unknown command", args: []string{"unknown"}, code: constant.ArgumentErrorExitCode},
+		{name: "TC11-missing uid", args: []string{"explain"}, code: constant.ArgumentErrorExitCode},
+		{name: "TC12-unknown subcommand", args: []string{"pod", "list"}, code: constant.ArgumentErrorExitCode},
+		{name: "TC13-invalid output", args: []string{"-o", "yaml", "pods", "list"},
+			code: constant.ArgumentErrorExitCode},
+		{name: "TC14-no command", args: []string{}, code: constant.ArgumentErrorExitCode},
+		{name: "TC15-audit of service", args: []string{"audit", "-service", "preemption", "-since", "1h"},
+			code: constant.NormalExitCode, contains: []string{"OLD", "preemption", constant.CPUCgroupFileName, "0", "-1"}},
+		{name: "TC16-audit with invalid time", args: []string{"audit", "-since", "yesterday"},
+			code: constant.ArgumentErrorExitCode},
+		{name: "TC17-stop service", args: []string{"services", "stop", "preemption"},
+			code: constant.NormalExitCode, contains: []string{"succeeded"}},
+		{name: "TC18-pause non-existed service", args: []string{"services", "pause", "cpuevict"},
+			code: constant.ErrorExitCode},
+		{name: "TC19-resume without name", args: []string{"services", "resume"},
+			code: constant.ArgumentErrorExitCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, tt.code, Run(append([]string{"-socket", socket}, tt.args...), &stdout, &stderr),
+				stderr.String())
+			for _, s := range tt.contains {
+				assert.Contains(t, stdout.String(), s)
+			}
+		})
+	}
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, constant.NormalExitCode, Run([]string{"-socket", socket, "-o", "json", "pod", "show", "uid-a"},
+		&stdout, &stderr))
+	var detail admin.PodDetail
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &detail))
+	assert.Equal(t, "-1", detail.Cgroup.Values[constant.CPUCgroupFileName])
+}
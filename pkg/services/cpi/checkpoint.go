@@ -0,0 +1,151 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file keeps the learned CPI statistic and the limited pods across restarts
+
+// Package cpi is for CPU Interference Detection Service
+package cpi
+
+import (
+	"time"
+
+	"isula.org/rubik/pkg/common/checkpoint"
+	"isula.org/rubik/pkg/common/log"
+)
+
+// checkpointVersion is the version of the state of the CPI service
+const checkpointVersion = 1
+
+// cpiCheckpoint is the state of the CPI service saved in the checkpoint
+type cpiCheckpoint struct {
+	Pods map[string]*podCheckpoint `json:"pods"`
+}
+
+// podCheckpoint is the state of a pod saved in the checkpoint
+type podCheckpoint struct {
+	// Count, CPIMean and StdDev are the CPI statistic learned from the online pod
+	Count   int64   `json:"count,omitempty"`
+	CPIMean float64 `json:"cpiMean,omitempty"`
+	StdDev  float64 `json:"stdDev,omitempty"`
+	// Limited indicates the CPU quota of the offline pod is limited,
+	// PreCPUQuota and ContainerQuotas are the CPU quotas of the pod and its containers before limiting
+	Limited         bool              `json:"limited,omitempty"`
+	PreCPUQuota     string            `json:"preCpuQuota,omitempty"`
+	ContainerQuotas map[string]string `json:"containerQuotas,omitempty"`
+}
+
+// checkpoint returns the state of the pod, nil is returned if there is nothing to be kept
+func (podStatus *podStatus) checkpoint() *podCheckpoint {
+	if podStatus.isOnline {
+		count, cpiMean, stdDev := podStatus.getCPIStatistic()
+		if count == 0 {
+			return nil
+		}
+		return &podCheckpoint{Count: count, CPIMean: cpiMean, StdDev: stdDev}
+	}
+	podStatus.podMutex.RLock()
+	defer podStatus.podMutex.RUnlock()
+	if !podStatus.isLimited {
+		return nil
+	}
+	cp := &podCheckpoint{
+		Limited:         true,
+		PreCPUQuota:     podStatus.preCpuQuota,
+		ContainerQuotas: make(map[string]string, len(podStatus.containers)),
+	}
+	for id, containerStatus := range podStatus.containers {
+		cp.ContainerQuotas[id] = containerStatus.preCpuQuota
+	}
+	return cp
+}
+
+// restore applies the state saved before rubik restarts to the pod
+func (podStatus *podStatus) restore(cp *podCheckpoint) {
+	if podStatus.isOnline {
+		podStatus.setCPIStatistic(cp.Count, cp.CPIMean, cp.StdDev)
+		return
+	}
+	if !cp.Limited || cp.PreCPUQuota == "" {
+		return
+	}
+	podStatus.podMutex.Lock()
+	// the quotas may have been recovered when rubik stopped, the state is dropped unless the cgroups
+	// are still limited, otherwise the quotas read from the cgroups would be replaced by the saved ones
+	if !podStatus.limitedBy(defaultLimitQuota) {
+		podStatus.podMutex.Unlock()
+		log.Infof("offlinePod %v is no longer limited after restart, drop its saved state", podStatus.UID)
+		return
+	}
+	// the quotas read from the cgroup are the limited quotas, so the quotas before limiting are used
+	// and recovered after the limit duration
+	podStatus.preCpuQuota = cp.PreCPUQuota
+	for id, containerStatus := range podStatus.containers {
+		if quota, ok := cp.ContainerQuotas[id]; ok {
+			containerStatus.preCpuQuota = quota
+		}
+	}
+	podStatus.isLimited = true
+	podStatus.delayer = time.AfterFunc(defaultLimitDur, func() {
+		podStatus.recoverQuota(defaultLimitDur)
+	})
+	podStatus.podMutex.Unlock()
+	log.Infof("offlinePod %v is still limited after restart", podStatus.UID)
+}
+
+// limitedBy returns true if the quotas of the pod and its containers read from the cgroups are the limited quota,
+// the lock must be held
+func (podStatus *podStatus) limitedBy(quota string) bool {
+	if podStatus.preCpuQuota != quota {
+		return false
+	}
+	for _, containerStatus := range podStatus.containers {
+		if containerStatus.preCpuQuota != quota {
+			return false
+		}
+	}
+	return true
+}
+
+// Checkpoint returns the CPI statistic of the online pods and the limited offline pods
+func (service *CpiService) Checkpoint() (*checkpoint.State, error) {
+	cp := &cpiCheckpoint{Pods: make(map[string]*podCheckpoint)}
+	for _, tasks := range []map[string]*podStatus{service.getOnlinePods(), service.getOfflinePods()} {
+		for uid, podStatus := range tasks {
+			if podCheckpoint := podStatus.checkpoint(); podCheckpoint != nil {
+				cp.Pods[uid] = podCheckpoint
+			}
+		}
+	}
+	return checkpoint.NewState(checkpointVersion, cp)
+}
+
+// Restore keeps the states of the pods saved before rubik restarts, which are applied when the pods are added
+func (service *CpiService) Restore(state *checkpoint.State) error {
+	cp := &cpiCheckpoint{}
+	if err := state.Decode(checkpointVersion, cp); err != nil {
+		return err
+	}
+	service.restoredMutex.Lock()
+	service.restored = cp.Pods
+	service.restoredMutex.Unlock()
+	return nil
+}
+
+// restorePod applies the state of the pod saved before rubik restarts, the state is applied only once
+func (service *CpiService) restorePod(podStatus *podStatus) {
+	service.restoredMutex.Lock()
+	cp, ok := service.restored[podStatus.UID]
+	delete(service.restored, podStatus.UID)
+	service.restoredMutex.Unlock()
+	if ok && cp != nil {
+		podStatus.restore(cp)
+	}
+}
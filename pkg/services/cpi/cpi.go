@@ -25,6 +25,7 @@ import (
 	"isula.org/rubik/pkg/api"
 	"isula.org/rubik/pkg/common/constant"
 	"isula.org/rubik/pkg/common/log"
+	"isula.org/rubik/pkg/common/metrics"
 	"isula.org/rubik/pkg/common/perf"
 	"isula.org/rubik/pkg/core/typedef"
 	"isula.org/rubik/pkg/services/helper"
@@ -53,13 +54,18 @@ type CpiService struct {
 	Viewer       api.Viewer
 	onlineMutex  sync.RWMutex
 	offlineMutex sync.RWMutex
+	// restored is the states of the pods saved before rubik restarts
+	restored      map[string]*podCheckpoint
+	restoredMutex sync.Mutex
 }
 
 func (service *CpiService) addPod(pod *typedef.PodInfo) {
 	switch pod.Annotations[constant.CpiAnnotationKey] {
 	case "online":
+		onlinePod, _ := newPodStatus(pod, true, pod.UID)
+		service.restorePod(onlinePod)
 		service.onlineMutex.Lock()
-		service.onlineTasks[pod.UID], _ = newPodStatus(pod, true, pod.UID)
+		service.onlineTasks[pod.UID] = onlinePod
 		service.onlineMutex.Unlock()
 		log.Debugf("added online pod %v", pod.UID)
 	case "offline":
@@ -69,6 +75,7 @@ func (service *CpiService) addPod(pod *typedef.PodInfo) {
 			service.offlineMutex.Unlock()
 			return
 		}
+		service.restorePod(offlinePod)
 		service.offlineTasks[pod.UID] = offlinePod
 		service.offlineMutex.Unlock()
 		log.Debugf("added offline pod %v", pod.UID)
@@ -76,6 +83,8 @@ func (service *CpiService) addPod(pod *typedef.PodInfo) {
 }
 
 func (service *CpiService) deletePod(pod *typedef.PodInfo) {
+	metrics.CPIMean.Delete(pod.UID)
+	metrics.CPIStdDev.Delete(pod.UID)
 	switch pod.Annotations[constant.CpiAnnotationKey] {
 	case "online":
 		service.onlineMutex.Lock()
@@ -156,11 +165,19 @@ func (service *CpiService) Run(ctx context.Context) {
 	}, defaultExpireDur, ctx.Done())
 }
 
-// PreStart initializes the CPI service by adding the current online and offline tasks into management.
-func (service *CpiService) PreStart(viewer api.Viewer) error {
+// CheckCapability checks whether the kernel supports the perf events to collect CPI
+func (service *CpiService) CheckCapability() error {
 	if !perf.Support() {
 		return fmt.Errorf("this machine does not support the Perf tool, so the CPI service cannot be executed. Please verify the system settings.")
 	}
+	return nil
+}
+
+// PreStart initializes the CPI service by adding the current online and offline tasks into management.
+func (service *CpiService) PreStart(viewer api.Viewer) error {
+	if err := service.CheckCapability(); err != nil {
+		return err
+	}
 	if viewer == nil {
 		return fmt.Errorf("invalid pods viewer")
 	}
@@ -21,6 +21,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+
 	"isula.org/rubik/pkg/common/constant"
 	"isula.org/rubik/pkg/common/perf"
 	"isula.org/rubik/pkg/core/typedef"
@@ -495,3 +497,71 @@ func TestCpiServiceIdentifyAntagonists(t *testing.T) {
 		})
 	}
 }
+
+// TestCpiServiceCheckpoint tests restoring the CPI statistic and the limited pods after restart
+func TestCpiServiceCheckpoint(t *testing.T) {
+	const name = "cpi"
+	service := newCpiService(name)
+	online := &podStatus{UID: fooOnlinePod.UID, isOnline: true}
+	online.setCPIStatistic(100, 1.5, 0.2)
+	offline := &podStatus{
+		UID:         fooOfflinePod.UID,
+		isLimited:   true,
+		preCpuQuota: "-1",
+		containers: map[string]*containerStatus{
+			fooOfflineCon.ID: {UID: fooOfflineCon.ID, preCpuQuota: "20000"},
+		},
+	}
+	service.onlineTasks[online.UID] = online
+	service.offlineTasks[offline.UID] = offline
+	// the offline pod which is not limited is not saved
+	service.offlineTasks["pod"] = &podStatus{UID: "pod"}
+
+	state, err := service.Checkpoint()
+	assert.NoError(t, err)
+	restarted := newCpiService(name)
+	assert.NoError(t, restarted.Restore(state))
+	assert.Len(t, restarted.restored, 2)
+
+	newOnline := &podStatus{UID: fooOnlinePod.UID, isOnline: true}
+	restarted.restorePod(newOnline)
+	count, cpiMean, stdDev := newOnline.getCPIStatistic()
+	assert.Equal(t, int64(100), count)
+	assert.Equal(t, 1.5, cpiMean)
+	assert.Equal(t, 0.2, stdDev)
+
+	// the quotas read after restart are the limited quotas
+	newOffline := &podStatus{
+		UID:         fooOfflinePod.UID,
+		preCpuQuota: defaultLimitQuota,
+		containers: map[string]*containerStatus{
+			fooOfflineCon.ID: {UID: fooOfflineCon.ID, preCpuQuota: defaultLimitQuota},
+		},
+	}
+	restarted.restorePod(newOffline)
+	assert.True(t, newOffline.isLimited)
+	assert.Equal(t, "-1", newOffline.preCpuQuota)
+	assert.Equal(t, "20000", newOffline.containers[fooOfflineCon.ID].preCpuQuota)
+	assert.NotNil(t, newOffline.delayer)
+	newOffline.delayer.Stop()
+	// the state is applied only once
+	assert.Len(t, restarted.restored, 0)
+
+	// the state is dropped if the quotas are recovered before restart
+	recovered := newCpiService(name)
+	assert.NoError(t, recovered.Restore(state))
+	newOffline = &podStatus{
+		UID:         fooOfflinePod.UID,
+		preCpuQuota: "-1",
+		containers: map[string]*containerStatus{
+			fooOfflineCon.ID: {UID: fooOfflineCon.ID, preCpuQuota: "20000"},
+		},
+	}
+	recovered.restorePod(newOffline)
+	assert.False(t, newOffline.isLimited)
+	assert.Nil(t, newOffline.delayer)
+	assert.Len(t, recovered.restored, 1)
+
+	state.Version = checkpointVersion + 1
+	assert.Error(t, newCpiService(name).Restore(state))
+}
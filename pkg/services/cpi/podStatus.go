@@ -20,13 +20,20 @@ import (
 	"sync"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+
 	"isula.org/rubik/pkg/common/log"
+	"isula.org/rubik/pkg/common/metrics"
 	"isula.org/rubik/pkg/common/perf"
 	"isula.org/rubik/pkg/common/util"
 	"isula.org/rubik/pkg/core/typedef"
 	"isula.org/rubik/pkg/core/typedef/cgroup"
+	"isula.org/rubik/pkg/lib/kubernetes"
 )
 
+// reasonLimited is the reason of the event on the offline pod whose CPU quota is limited
+const reasonLimited = "CPULimited"
+
 var cpiConf []int = []int{perf.INSTRUCTIONS, perf.CYCLES, perf.CACHEREFERENCES, perf.CACHEMISS}
 
 const (
@@ -41,6 +48,8 @@ type containerStatus struct {
 }
 type podStatus struct {
 	UID        string
+	name       string
+	namespace  string
 	isOnline   bool
 	containers map[string]*containerStatus
 	*cgroup.Hierarchy
@@ -61,6 +70,8 @@ func (podStatus *podStatus) setCPIStatistic(count int64, cpiMean float64, stdDev
 	podStatus.cpiMean = cpiMean
 	podStatus.stdDev = stdDev
 	podStatus.podMutex.Unlock()
+	metrics.CPIMean.Set(cpiMean, podStatus.UID)
+	metrics.CPIStdDev.Set(stdDev, podStatus.UID)
 }
 
 func (podStatus *podStatus) getCPIStatistic() (int64, float64, float64) {
@@ -187,6 +198,8 @@ func newPodStatus(pod *typedef.PodInfo, isOnline bool, uid string) (*podStatus,
 	if isOnline {
 		return &podStatus{
 			UID:            uid,
+			name:           pod.Name,
+			namespace:      pod.Namespace,
 			isOnline:       isOnline,
 			Hierarchy:      &pod.Hierarchy,
 			cpiSeries:      newDataSeries(),
@@ -199,6 +212,8 @@ func newPodStatus(pod *typedef.PodInfo, isOnline bool, uid string) (*podStatus,
 	}
 	offlinePod := &podStatus{
 		UID:            uid,
+		name:           pod.Name,
+		namespace:      pod.Namespace,
 		isOnline:       isOnline,
 		Hierarchy:      &pod.Hierarchy,
 		cpuUsageSeries: newDataSeries(),
@@ -390,6 +405,9 @@ func (podStatus *podStatus) limit(quota string, limitDur time.Duration) error {
 	if err := podStatus.limitQuota(quota); err != nil {
 		return err
 	}
+	kubernetes.RecordEvent(kubernetes.PodReference(podStatus.namespace, podStatus.name, podStatus.UID),
+		corev1.EventTypeWarning, reasonLimited,
+		"CPU quota is limited to %v for %v because the pod interferes with the online pods", quota, limitDur)
 	if podStatus.delayer != nil {
 		podStatus.delayer.Reset(limitDur)
 		return nil
@@ -18,8 +18,9 @@ import (
 	"fmt"
 	"time"
 
+	"isula.org/rubik/pkg/common/checkpoint"
 	"isula.org/rubik/pkg/common/constant"
-	"isula.org/rubik/pkg/common/log"
+	"isula.org/rubik/pkg/common/metrics"
 	"isula.org/rubik/pkg/common/perf"
 	"isula.org/rubik/pkg/common/util"
 	"isula.org/rubik/pkg/core/typedef"
@@ -39,14 +40,14 @@ func (c *DynCache) startDynamic() {
 	needMore := true
 	limiter := c.newCacheLimitSet(levelDynamic, c.Attr.L3PercentDynamic, c.Attr.MemBandPercentDynamic)
 
-	for _, p := range c.listOnlinePods() {
+	for _, p := range c.listUnlimitedPods() {
 		cacheMiss, llcMiss := getPodCacheMiss(p, c.config.PerfDuration)
 		if cacheMiss >= c.Attr.MaxMiss || llcMiss >= c.Attr.MaxMiss {
-			log.Infof("online pod %v cache miss: %v LLC miss: %v exceeds maxmiss, lower offline cache limit",
+			c.Log().WithPod(p.UID).Infof("online pod %v cache miss: %v LLC miss: %v exceeds maxmiss, lower offline cache limit",
 				p.UID, cacheMiss, llcMiss)
 
 			if err := c.flush(limiter, stepLess); err != nil {
-				log.Errorf(err.Error())
+				c.Log().Errorf(err.Error())
 			}
 			return
 		}
@@ -57,7 +58,7 @@ func (c *DynCache) startDynamic() {
 
 	if needMore {
 		if err := c.flush(limiter, stepMore); err != nil {
-			log.Errorf(err.Error())
+			c.Log().Errorf(err.Error())
 		}
 	}
 }
@@ -81,7 +82,7 @@ func getPodCacheMiss(pod *typedef.PodInfo, perfDu int) (int, int) {
 }
 
 func (c *DynCache) dynamicExist() bool {
-	for _, pod := range c.listOfflinePods() {
+	for _, pod := range c.listLimitedPods() {
 		if err := c.syncLevel(pod); err != nil {
 			continue
 		}
@@ -109,7 +110,7 @@ func (c *DynCache) flush(limitSet *limitSet, step int) error {
 	if c.Attr.L3PercentDynamic == l3 && c.Attr.MemBandPercentDynamic == mb {
 		return nil
 	}
-	log.Infof("flush L3 from %v to %v, Mb from %v to %v", limitSet.l3Percent, l3, limitSet.mbPercent, mb)
+	c.Log().Infof("flush L3 from %v to %v, Mb from %v to %v", limitSet.l3Percent, l3, limitSet.mbPercent, mb)
 	limitSet.l3Percent, limitSet.mbPercent = l3, mb
 	return c.doFlush(limitSet)
 }
@@ -119,14 +120,73 @@ func (c *DynCache) doFlush(limitSet *limitSet) error {
 		return fmt.Errorf("adjust dynamic cache limit to l3:%v mb:%v error: %v",
 			limitSet.l3Percent, limitSet.mbPercent, err)
 	}
-	c.Attr.L3PercentDynamic = limitSet.l3Percent
-	c.Attr.MemBandPercentDynamic = limitSet.mbPercent
+	c.setDynamicPercent(limitSet.l3Percent, limitSet.mbPercent)
+	c.reportDynamicPercent()
 
 	return nil
 }
 
-func (c *DynCache) listOnlinePods() map[string]*typedef.PodInfo {
+// setDynamicPercent changes the current percentages of the dynamic level
+func (c *DynCache) setDynamicPercent(l3, mb int) {
+	c.dynamicLock.Lock()
+	c.Attr.L3PercentDynamic = l3
+	c.Attr.MemBandPercentDynamic = mb
+	c.dynamicLock.Unlock()
+}
+
+// dynamicPercent returns the current percentages of the dynamic level
+func (c *DynCache) dynamicPercent() (l3, mb int) {
+	c.dynamicLock.RLock()
+	defer c.dynamicLock.RUnlock()
+	return c.Attr.L3PercentDynamic, c.Attr.MemBandPercentDynamic
+}
+
+// reportDynamicPercent exposes the current percentages of the dynamic level in metrics
+func (c *DynCache) reportDynamicPercent() {
+	l3, mb := c.dynamicPercent()
+	metrics.DynCachePercent.Set(float64(l3), "l3")
+	metrics.DynCachePercent.Set(float64(mb), "mb")
+}
+
+// checkpointVersion is the version of the state of the dynCache service
+const checkpointVersion = 1
+
+// dynamicCheckpoint is the percentages of the dynamic level saved in the checkpoint
+type dynamicCheckpoint struct {
+	L3Percent      int `json:"l3Percent"`
+	MemBandPercent int `json:"memBandPercent"`
+}
+
+// Checkpoint returns the current percentages of the dynamic level, it is called while the service adjusts them
+func (c *DynCache) Checkpoint() (*checkpoint.State, error) {
+	l3, mb := c.dynamicPercent()
+	return checkpoint.NewState(checkpointVersion, &dynamicCheckpoint{L3Percent: l3, MemBandPercent: mb})
+}
+
+// Restore keeps the percentages of the dynamic level saved before rubik restarts,
+// which are used instead of the low percentages when the service starts
+func (c *DynCache) Restore(state *checkpoint.State) error {
+	cp := &dynamicCheckpoint{}
+	if err := state.Decode(checkpointVersion, cp); err != nil {
+		return err
+	}
+	c.restored = cp
+	return nil
+}
+
+// clampPercent limits the percentage between the low and high percentages
+func clampPercent(value int, percent MultiLvlPercent) int {
+	if value < percent.Low {
+		return percent.Low
+	}
+	if value > percent.High {
+		return percent.High
+	}
+	return value
+}
+
+func (c *DynCache) listUnlimitedPods() map[string]*typedef.PodInfo {
 	return c.Viewer.ListPodsWithOptions(func(pi *typedef.PodInfo) bool {
-		return pi.Online()
+		return !c.limited(pi)
 	})
 }
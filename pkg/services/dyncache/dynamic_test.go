@@ -280,3 +280,65 @@ func TestCacheLimit_StartDynamic(t *testing.T) {
 		})
 	}
 }
+
+// TestCacheLimit_Checkpoint tests restoring the percentages of the dynamic level
+func TestCacheLimit_Checkpoint(t *testing.T) {
+	c := newDynCache("dynCache")
+	c.Attr.L3PercentDynamic, c.Attr.MemBandPercentDynamic = 30, 40
+	state, err := c.Checkpoint()
+	if err != nil {
+		t.Fatalf("CacheLimit.Checkpoint() error = %v", err)
+	}
+
+	restarted := newDynCache("dynCache")
+	if err := restarted.Restore(state); err != nil {
+		t.Fatalf("CacheLimit.Restore() error = %v", err)
+	}
+	if *restarted.restored != (dynamicCheckpoint{L3Percent: 30, MemBandPercent: 40}) {
+		t.Errorf("CacheLimit.Restore() restored = %v", *restarted.restored)
+	}
+	percent := MultiLvlPercent{Low: 20, Mid: 30, High: 35}
+	for value, want := range map[int]int{10: 20, 30: 30, 40: 35} {
+		if got := clampPercent(value, percent); got != want {
+			t.Errorf("clampPercent(%v) = %v, want %v", value, got, want)
+		}
+	}
+	state.Version++
+	if err := restarted.Restore(state); err == nil {
+		t.Errorf("CacheLimit.Restore() restores the state of another version")
+	}
+}
+
+// TestCacheLimit_CheckpointWhileFlush tests checkpointing the percentages of the dynamic level while adjusting them
+func TestCacheLimit_CheckpointWhileFlush(t *testing.T) {
+	resctrlDir := try.GenTestDir().String()
+	defer try.RemoveAll(resctrlDir)
+	setMaskFile(t, resctrlDir, "3ff")
+	c := newDynCache("dynCache")
+	c.config.DefaultResctrlDir = resctrlDir
+	c.Attr.NumaNum = 1
+	c.setDynamicPercent(c.config.L3Percent.Low, c.config.MemBandPercent.Low)
+	limiter := c.newCacheLimitSet(levelDynamic, c.Attr.L3PercentDynamic, c.Attr.MemBandPercentDynamic)
+
+	const rounds = 100
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < rounds; i++ {
+			step := 5
+			if i%2 == 1 {
+				step = -5
+			}
+			if err := c.flush(limiter, step); err != nil {
+				t.Errorf("CacheLimit.flush() error = %v", err)
+				return
+			}
+		}
+	}()
+	for i := 0; i < rounds; i++ {
+		if _, err := c.Checkpoint(); err != nil {
+			t.Errorf("CacheLimit.Checkpoint() error = %v", err)
+		}
+	}
+	<-done
+}
@@ -17,11 +17,13 @@ package dyncache
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	"isula.org/rubik/pkg/api"
+	"isula.org/rubik/pkg/core/typedef"
 	"isula.org/rubik/pkg/services/helper"
 )
 
@@ -83,6 +85,9 @@ type Config struct {
 	L3Percent MultiLvlPercent `json:"l3Percent,omitempty"`
 	// MemBandPercent is memory bandwidth percent for each level
 	MemBandPercent MultiLvlPercent `json:"memBandPercent,omitempty"`
+	// Levels maps the priority tiers to the cache limit levels of the pods without the cache limit annotation,
+	// the pods of the online tiers not configured are not limited
+	Levels map[string]string `json:"levels,omitempty"`
 }
 
 // DynCache is cache limit service structure
@@ -91,6 +96,10 @@ type DynCache struct {
 	config *Config
 	Attr   *Attr
 	Viewer api.Viewer
+	// restored is the percentages of the dynamic level saved before rubik restarts
+	restored *dynamicCheckpoint
+	// dynamicLock protects the percentages of the dynamic level in Attr, which are checkpointed while adjusted
+	dynamicLock sync.RWMutex
 }
 
 // Attr is cache limit attribute differ from config
@@ -233,5 +242,13 @@ func (conf *Config) Validate() error {
 		conf.MemBandPercent.Mid > conf.MemBandPercent.High {
 		return fmt.Errorf("cache limit config MemBandPercent does not satisfy constraint low<=mid<=high")
 	}
+	for name, level := range conf.Levels {
+		if _, err := typedef.ParsePriority(name); err != nil {
+			return err
+		}
+		if !validLevel[level] {
+			return fmt.Errorf("invalid cache limit level %v of %v", level, name)
+		}
+	}
 	return nil
 }
@@ -21,8 +21,8 @@ import (
 	"strconv"
 	"strings"
 
+	"isula.org/rubik/pkg/common/audit"
 	"isula.org/rubik/pkg/common/constant"
-	"isula.org/rubik/pkg/common/log"
 	"isula.org/rubik/pkg/common/perf"
 	"isula.org/rubik/pkg/common/util"
 )
@@ -36,21 +36,28 @@ type limitSet struct {
 	level     string
 	l3Percent int
 	mbPercent int
+	// owner is the service writing the schemata
+	owner string
 }
 
-// initCacheLimitDir init multi-level cache limit directories
-func (c *DynCache) initCacheLimitDir() error {
-	const (
-		defaultL3PercentMax = 100
-		defaultMbPercentMax = 100
-	)
+// CheckCapability checks whether the kernel supports the perf events and the resctrl filesystem
+func (c *DynCache) CheckCapability() error {
 	if !perf.Support() {
 		return fmt.Errorf("current os does not support perf hw pmu events")
 	}
 	if err := checkHostPidns(c.config.DefaultPidNameSpace); err != nil {
 		return err
 	}
-	if err := checkResctrlPath(c.config.DefaultResctrlDir); err != nil {
+	return checkResctrlPath(c.config.DefaultResctrlDir)
+}
+
+// initCacheLimitDir init multi-level cache limit directories
+func (c *DynCache) initCacheLimitDir() error {
+	const (
+		defaultL3PercentMax = 100
+		defaultMbPercentMax = 100
+	)
+	if err := c.CheckCapability(); err != nil {
 		return err
 	}
 	numaNum, err := getNUMANum(c.Attr.NumaNodeDir)
@@ -58,8 +65,14 @@ func (c *DynCache) initCacheLimitDir() error {
 		return fmt.Errorf("failed to get NUMA nodes number: %v", err)
 	}
 	c.Attr.NumaNum = numaNum
-	c.Attr.L3PercentDynamic = c.config.L3Percent.Low
-	c.Attr.MemBandPercentDynamic = c.config.MemBandPercent.Low
+	l3, mb := c.config.L3Percent.Low, c.config.MemBandPercent.Low
+	if c.restored != nil {
+		// the percentages are adjusted within the configured range which may change after restart
+		l3 = clampPercent(c.restored.L3Percent, c.config.L3Percent)
+		mb = clampPercent(c.restored.MemBandPercent, c.config.MemBandPercent)
+		c.restored = nil
+	}
+	c.setDynamicPercent(l3, mb)
 
 	cacheLimitList := []*limitSet{
 		c.newCacheLimitSet(levelDynamic, c.Attr.L3PercentDynamic, c.Attr.MemBandPercentDynamic),
@@ -74,8 +87,9 @@ func (c *DynCache) initCacheLimitDir() error {
 			return err
 		}
 	}
+	c.reportDynamicPercent()
 
-	log.Debugf("initialize cache limit directory successfully")
+	c.Log().Debugf("initialize cache limit directory successfully")
 	return nil
 }
 
@@ -85,11 +99,12 @@ func (c *DynCache) newCacheLimitSet(level string, l3Per, mbPer int) *limitSet {
 		l3Percent: l3Per,
 		mbPercent: mbPer,
 		dir:       filepath.Join(filepath.Clean(c.config.DefaultResctrlDir), resctrlDirPrefix+level),
+		owner:     c.Name,
 	}
 }
 
 func (cl *limitSet) setDir() error {
-	if err := os.Mkdir(cl.dir, constant.DefaultDirMode); err != nil && !os.IsExist(err) {
+	if err := audit.Mkdir(audit.Origin{Service: cl.owner}, cl.dir, constant.DefaultDirMode); err != nil {
 		return fmt.Errorf("failed to create cache limit directory: %v", err)
 	}
 	return nil
@@ -116,7 +131,7 @@ func (cl *limitSet) writeResctrlSchemata(numaNum int) error {
 	l3 := fmt.Sprintf("L3:%s\n", strings.Join(l3List, ";"))
 	mb := fmt.Sprintf("MB:%s\n", strings.Join(mbList, ";"))
 	content = l3 + mb
-	if err := util.WriteFile(schemetaFile, content); err != nil {
+	if err := audit.WriteFile(audit.Origin{Service: cl.owner}, schemetaFile, content); err != nil {
 		return fmt.Errorf("failed to write %s to file %s: %v", content, schemetaFile, err)
 	}
 
@@ -19,8 +19,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"isula.org/rubik/pkg/common/audit"
 	"isula.org/rubik/pkg/common/constant"
-	"isula.org/rubik/pkg/common/log"
 	"isula.org/rubik/pkg/common/util"
 	"isula.org/rubik/pkg/core/typedef"
 	"isula.org/rubik/pkg/core/typedef/cgroup"
@@ -47,13 +47,13 @@ var validLevel = map[string]bool{
 
 // SyncCacheLimit will continuously set cache limit with corresponding offline pods
 func (c *DynCache) syncCacheLimit() {
-	for _, p := range c.listOfflinePods() {
+	for _, p := range c.listLimitedPods() {
 		if err := c.syncLevel(p); err != nil {
-			log.Errorf("failed to sync cache limit level: %v", err)
+			c.Log().WithPod(p.UID).Errorf("failed to sync cache limit level: %v", err)
 			continue
 		}
 		if err := c.writeTasksToResctrl(p); err != nil {
-			log.Errorf("failed to set cache limit for pod %v: %v", p.UID, err)
+			c.Log().WithPod(p.UID).Errorf("failed to set cache limit for pod %v: %v", p.UID, err)
 			continue
 		}
 	}
@@ -80,10 +80,11 @@ func (c *DynCache) writeTasksToResctrl(pod *typedef.PodInfo) error {
 
 	resctrlTaskFile := filepath.Join(c.config.DefaultResctrlDir,
 		resctrlDirPrefix+pod.Annotations[constant.CacheLimitAnnotationKey], "tasks")
+	origin := audit.Origin{Service: c.Name, PodUID: pod.UID}
 	for _, task := range taskList {
-		if err := util.WriteFile(resctrlTaskFile, task); err != nil {
+		if err := audit.WriteFile(origin, resctrlTaskFile, task); err != nil {
 			if strings.Contains(err.Error(), "no such process") {
-				log.Errorf("pod %s task %s does not exist", pod.UID, task)
+				c.Log().WithPod(pod.UID).Errorf("pod %s task %s does not exist", pod.UID, task)
 				continue
 			}
 			return fmt.Errorf("failed to add task %v to file %v: %v", task, resctrlTaskFile, err)
@@ -96,7 +97,9 @@ func (c *DynCache) writeTasksToResctrl(pod *typedef.PodInfo) error {
 // syncLevel sync cache limit level
 func (c *DynCache) syncLevel(pod *typedef.PodInfo) error {
 	if pod.Annotations[constant.CacheLimitAnnotationKey] == "" {
-		if c.config.DefaultLimitMode == modeStatic {
+		if level, ok := c.config.Levels[pod.Priority().String()]; ok {
+			pod.Annotations[constant.CacheLimitAnnotationKey] = level
+		} else if c.config.DefaultLimitMode == modeStatic {
 			pod.Annotations[constant.CacheLimitAnnotationKey] = levelMax
 		} else {
 			pod.Annotations[constant.CacheLimitAnnotationKey] = levelDynamic
@@ -110,8 +113,15 @@ func (c *DynCache) syncLevel(pod *typedef.PodInfo) error {
 	return nil
 }
 
-func (c *DynCache) listOfflinePods() map[string]*typedef.PodInfo {
-	return c.Viewer.ListPodsWithOptions(func(pi *typedef.PodInfo) bool {
-		return pi.Offline()
-	})
+// limited returns true if the cache of the pod is limited, which is offline or whose tier has a cache limit level
+func (c *DynCache) limited(pod *typedef.PodInfo) bool {
+	priority := pod.Priority()
+	if _, ok := c.config.Levels[priority.String()]; ok {
+		return true
+	}
+	return priority.Offline()
+}
+
+func (c *DynCache) listLimitedPods() map[string]*typedef.PodInfo {
+	return c.Viewer.ListPodsWithOptions(c.limited)
 }
@@ -18,7 +18,8 @@ type DynMemoryAdapter interface {
 	preStart(api.Viewer) error
 	getInterval() int
 	dynamicAdjust()
-	setOfflinePod(path string) error
+	setOfflinePod(podInfo *typedef.PodInfo) error
+	reconcileOfflinePod(podInfo *typedef.PodInfo) ([]*helper.Drift, error)
 }
 type dynMemoryConfig struct {
 	Policy string `json:"policy,omitempty"`
@@ -66,7 +67,7 @@ func (dynMem *DynMemory) SetConfig(f helper.ConfigHandler) error {
 	if err := f(dynMem.Name, &config); err != nil {
 		return err
 	}
-	if dynMem.dynMemoryAdapter = newAdapter(config.Policy); dynMem.dynMemoryAdapter == nil {
+	if dynMem.dynMemoryAdapter = newAdapter(dynMem.Name, config.Policy); dynMem.dynMemoryAdapter == nil {
 		return fmt.Errorf("invalid dynamic memory policy")
 	}
 	return nil
@@ -91,7 +92,7 @@ func (dynMem *DynMemory) IsRunner() bool {
 // AddPod to deal the event of adding a pod.
 func (dynMem *DynMemory) AddPod(podInfo *typedef.PodInfo) error {
 	if podInfo.Offline() {
-		return dynMem.dynMemoryAdapter.setOfflinePod(podInfo.Path)
+		return dynMem.dynMemoryAdapter.setOfflinePod(podInfo)
 	}
 	return nil
 }
@@ -99,16 +100,24 @@ func (dynMem *DynMemory) AddPod(podInfo *typedef.PodInfo) error {
 // UpdatePod to deal the pod update event.
 func (dynMem *DynMemory) UpdatePod(old, new *typedef.PodInfo) error {
 	if new.Offline() {
-		return dynMem.dynMemoryAdapter.setOfflinePod(new.Path)
+		return dynMem.dynMemoryAdapter.setOfflinePod(new)
 	}
 	return nil
 }
 
-// newAdapter to create adapter of dyn memory.
-func newAdapter(policy string) DynMemoryAdapter {
+// Reconcile restores the memory settings of the offline pod changed by others
+func (dynMem *DynMemory) Reconcile(podInfo *typedef.PodInfo) ([]*helper.Drift, error) {
+	if dynMem.dynMemoryAdapter == nil || !podInfo.Offline() {
+		return nil, nil
+	}
+	return dynMem.dynMemoryAdapter.reconcileOfflinePod(podInfo)
+}
+
+// newAdapter to create adapter of dyn memory, owner is the service using the adapter.
+func newAdapter(owner, policy string) DynMemoryAdapter {
 	switch policy {
 	case "fssr":
-		return initFssrDynMemAdapter()
+		return initFssrDynMemAdapter(owner)
 	default:
 		log.Errorf("no matching policy[%v] is found", policy)
 	}
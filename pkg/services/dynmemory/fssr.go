@@ -7,13 +7,15 @@ import (
 	"math"
 	"os"
 	"strconv"
+	"sync/atomic"
 
 	"isula.org/rubik/pkg/api"
-	"isula.org/rubik/pkg/common/constant"
+	"isula.org/rubik/pkg/common/audit"
 	"isula.org/rubik/pkg/common/log"
 	"isula.org/rubik/pkg/common/util"
 	"isula.org/rubik/pkg/core/typedef"
 	"isula.org/rubik/pkg/core/typedef/cgroup"
+	"isula.org/rubik/pkg/services/helper"
 )
 
 const (
@@ -34,16 +36,19 @@ type fssrDynMemAdapter struct {
 	reservedMem int64
 	count       int64
 	viewer      api.Viewer
+	// owner is the service writing the memory cgroup files
+	owner string
 }
 
 // initFssrDynMemAdapter initializes a new fssrDynMemAdapter struct.
-func initFssrDynMemAdapter() *fssrDynMemAdapter {
+func initFssrDynMemAdapter(owner string) *fssrDynMemAdapter {
 	if total, err := getFieldMemory("MemTotal"); err == nil && total > 0 {
 		return &fssrDynMemAdapter{
 			memTotal:    total,
 			memHigh:     total * 8 / 10,
 			reservedMem: total * 1 / 10,
 			count:       0,
+			owner:       owner,
 		}
 	}
 	return nil
@@ -103,7 +108,8 @@ func (f *fssrDynMemAdapter) dynamicAdjust() {
 
 func (f *fssrDynMemAdapter) adjustMemoryHigh(memHigh int64) {
 	if memHigh != f.memHigh {
-		f.memHigh = memHigh
+		// memHigh is read by the pod events and the reconciliation concurrently
+		atomic.StoreInt64(&f.memHigh, memHigh)
 		f.adjustOfflinePodHighMemory()
 	}
 }
@@ -112,7 +118,7 @@ func (f *fssrDynMemAdapter) adjustMemoryHigh(memHigh int64) {
 func (f *fssrDynMemAdapter) adjustOfflinePodHighMemory() {
 	pods := listOfflinePods(f.viewer)
 	for _, podInfo := range pods {
-		if err := setOfflinePodHighMemory(podInfo.Path, f.memHigh); err != nil {
+		if err := setOfflinePodHighMemory(f.origin(podInfo), podInfo.Path, atomic.LoadInt64(&f.memHigh)); err != nil {
 			log.Errorf("failed to adjust high memory of offline pod[%v]:%v", podInfo.UID, err)
 		}
 	}
@@ -122,7 +128,7 @@ func (f *fssrDynMemAdapter) adjustOfflinePodHighMemory() {
 func (f *fssrDynMemAdapter) dealExistedPods() error {
 	pods := listOfflinePods(f.viewer)
 	for _, podInfo := range pods {
-		if err := f.setOfflinePod(podInfo.Path); err != nil {
+		if err := f.setOfflinePod(podInfo); err != nil {
 			log.Errorf("failed to set fssr of offline pod[%v]:%v", podInfo.UID, err)
 		}
 	}
@@ -131,30 +137,59 @@ func (f *fssrDynMemAdapter) dealExistedPods() error {
 
 // listOfflinePods returns a map of offline PodInfo objects.
 func listOfflinePods(viewer api.Viewer) map[string]*typedef.PodInfo {
-	offlineValue := "true"
 	return viewer.ListPodsWithOptions(func(pi *typedef.PodInfo) bool {
-		return pi.Annotations[constant.PriorityAnnotationKey] == offlineValue
+		return pi.Offline()
 	})
 }
 
-// setOfflinePod sets the offline pod for the given path.
-func (f *fssrDynMemAdapter) setOfflinePod(path string) error {
-	if err := setOfflinePodHighAsyncRatio(path, highRatio); err != nil {
+// setOfflinePod sets the offline pod.
+func (f *fssrDynMemAdapter) setOfflinePod(podInfo *typedef.PodInfo) error {
+	if err := setOfflinePodHighAsyncRatio(f.origin(podInfo), podInfo.Path, highRatio); err != nil {
 		return err
 	}
-	return setOfflinePodHighMemory(path, f.memHigh)
+	return setOfflinePodHighMemory(f.origin(podInfo), podInfo.Path, atomic.LoadInt64(&f.memHigh))
+}
+
+// reconcileOfflinePod restores the memory.high and memory.high_async_ratio of the offline pod changed by others
+func (f *fssrDynMemAdapter) reconcileOfflinePod(podInfo *typedef.PodInfo) ([]*helper.Drift, error) {
+	// the memory.high read from the cgroup is aligned to the page size
+	pageSize := int64(os.Getpagesize())
+	memHigh := atomic.LoadInt64(&f.memHigh) / pageSize * pageSize
+	var (
+		drifts []*helper.Drift
+		errs   error
+	)
+	for _, file := range []struct {
+		name     string
+		expected string
+	}{
+		{highMemFile, strconv.FormatInt(memHigh, scale)},
+		{highMemAsyncRatioFile, strconv.FormatUint(uint64(highRatio), scale)},
+	} {
+		drift, err := helper.ReconcileCgroupFile(f.origin(podInfo), file.expected, memcgRootDir, podInfo.Path, file.name)
+		if drift != nil {
+			drifts = append(drifts, drift)
+		}
+		errs = util.AppendErr(errs, err)
+	}
+	return drifts, errs
+}
+
+// origin returns the origin of the writes to the cgroup files of the pod
+func (f *fssrDynMemAdapter) origin(podInfo *typedef.PodInfo) audit.Origin {
+	return audit.Origin{Service: f.owner, PodUID: podInfo.UID}
 }
 
 // setOfflinePodHighMemory sets the high memory limit for the specified pod in the
 // cgroup memory
-func setOfflinePodHighMemory(podPath string, memHigh int64) error {
-	return cgroup.WriteCgroupFile(strconv.FormatUint(uint64(memHigh), scale), memcgRootDir,
+func setOfflinePodHighMemory(origin audit.Origin, podPath string, memHigh int64) error {
+	return cgroup.WriteCgroupFileAs(origin, strconv.FormatUint(uint64(memHigh), scale), memcgRootDir,
 		podPath, highMemFile)
 }
 
 // setOfflinePodHighAsyncRatio sets the high memory async ratio for a pod in an offline state.
-func setOfflinePodHighAsyncRatio(podPath string, ratio uint) error {
-	return cgroup.WriteCgroupFile(strconv.FormatUint(uint64(ratio), scale), memcgRootDir,
+func setOfflinePodHighAsyncRatio(origin audit.Origin, podPath string, ratio uint) error {
+	return cgroup.WriteCgroupFileAs(origin, strconv.FormatUint(uint64(ratio), scale), memcgRootDir,
 		podPath, highMemAsyncRatioFile)
 }
 
@@ -25,7 +25,6 @@ import (
 	v2 "github.com/google/cadvisor/info/v2"
 
 	"isula.org/rubik/pkg/api"
-	"isula.org/rubik/pkg/common/constant"
 	"isula.org/rubik/pkg/common/log"
 	"isula.org/rubik/pkg/common/util"
 	"isula.org/rubik/pkg/core/metric"
@@ -76,6 +75,36 @@ func newCadvisorManager() (resource.Manager, error) {
 type Controller interface {
 	Start(context.Context, func(func() bool) error)
 	Config() interface{}
+	// Priorities returns the priority tiers of the pods to be evicted in order
+	Priorities() []typedef.Priority
+}
+
+// DefaultPriorities are the priority tiers of the pods to be evicted in order by default
+func DefaultPriorities() []string {
+	return []string{typedef.BestEffort.String(), typedef.Batch.String()}
+}
+
+// ParsePriorities parses the priority tiers of the pods to be evicted in order
+func ParsePriorities(names []string) ([]typedef.Priority, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("priorities should not be empty")
+	}
+	var (
+		priorities = make([]typedef.Priority, 0, len(names))
+		parsed     = make(map[typedef.Priority]bool, len(names))
+	)
+	for _, name := range names {
+		p, err := typedef.ParsePriority(name)
+		if err != nil {
+			return nil, err
+		}
+		if parsed[p] {
+			return nil, fmt.Errorf("duplicate priority %v", name)
+		}
+		parsed[p] = true
+		priorities = append(priorities, p)
+	}
+	return priorities, nil
 }
 
 // Manager is used to manage Evcit services
@@ -183,22 +212,26 @@ func (m *Manager) PreStart(viewer api.Viewer) error {
 	return nil
 }
 
-func priority(online bool) api.ListOption {
-	const (
-		offTag = "true"
-		onTag  = "false"
-	)
-	var wanted = onTag
-	if !online {
-		wanted = offTag
-	}
+func priority(p typedef.Priority) api.ListOption {
 	return func(pod *typedef.PodInfo) bool {
-		if prio, declared := pod.Annotations[constant.PriorityAnnotationKey]; declared {
-			return prio == wanted
+		return pod.Priority() == p
+	}
+}
+
+// candidates returns the pods of the first priority tier having pods in the eviction order of the controller
+func (m *Manager) candidates(name string) map[string]*typedef.PodInfo {
+	m.RLock()
+	controller, existed := m.controllers[name]
+	m.RUnlock()
+	if !existed {
+		return nil
+	}
+	for _, p := range controller.Priorities() {
+		if pods := m.viewer.ListPodsWithOptions(priority(p)); len(pods) != 0 {
+			return pods
 		}
-		// If no priority is set, the priority is online by default
-		return onTag == wanted
 	}
+	return nil
 }
 
 // Terminate clean the resource
@@ -215,7 +248,7 @@ func (m *Manager) Terminate(api.Viewer) error {
 
 func (m *Manager) alarm(typ string) func(func() bool) error {
 	return func(needEvcit func() bool) error {
-		pods := m.viewer.ListPodsWithOptions(priority(false))
+		pods := m.candidates(typ)
 		if len(pods) == 0 {
 			return nil
 		}
@@ -19,6 +19,8 @@ package cpu
 import (
 	"fmt"
 	"math"
+
+	"isula.org/rubik/pkg/services/eviction/common"
 )
 
 const (
@@ -45,6 +47,8 @@ type Config struct {
 	Windows   uint16 `json:"windows,omitempty"`
 	Threshold uint8  `json:"threshold,omitempty"`
 	Cooldown  int    `json:"cooldown,omitempty"`
+	// Priorities are the priority tiers of the pods to be evicted in order
+	Priorities []string `json:"priorities,omitempty"`
 }
 
 // newConfig returns default cpuEvcit configuration
@@ -54,6 +58,8 @@ func newConfig() *Config {
 		Windows:   defaultWindows,
 		Threshold: defaultThreshold,
 		Cooldown:  defaultCooldown,
+		// the pods of the tiers not listed are never evicted
+		Priorities: common.DefaultPriorities(),
 	}
 }
 
@@ -25,7 +25,9 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	"isula.org/rubik/pkg/common/log"
+	"isula.org/rubik/pkg/core/typedef"
 	"isula.org/rubik/pkg/lib/cpu/quotaturbo"
+	"isula.org/rubik/pkg/services/eviction/common"
 	"isula.org/rubik/pkg/services/helper"
 )
 
@@ -41,6 +43,8 @@ type Controller struct {
 	conf   *Config
 	usages []usage
 	block  int32
+	// priorities are the priority tiers of the pods to be evicted in order
+	priorities []typedef.Priority
 }
 
 // fromConfig generates CPU controller based on configuration
@@ -56,8 +60,13 @@ func fromConfig(name string, f helper.ConfigHandler) (*Controller, error) {
 	if err := conf.validate(); err != nil {
 		return nil, err
 	}
+	priorities, err := common.ParsePriorities(conf.Priorities)
+	if err != nil {
+		return nil, err
+	}
 	return &Controller{
-		conf: conf,
+		conf:       conf,
+		priorities: priorities,
 	}, nil
 }
 
@@ -145,3 +154,8 @@ func (c *Controller) assertWithinLimit() bool {
 func (c *Controller) Config() interface{} {
 	return c.conf
 }
+
+// Priorities returns the priority tiers of the pods to be evicted in order
+func (c *Controller) Priorities() []typedef.Priority {
+	return c.priorities
+}
@@ -19,6 +19,8 @@ package memory
 import (
 	"fmt"
 	"math"
+
+	"isula.org/rubik/pkg/services/eviction/common"
 )
 
 const (
@@ -41,6 +43,8 @@ type Config struct {
 	Interval  uint16 `json:"interval,omitempty"`
 	Threshold uint8  `json:"threshold,omitempty"`
 	Cooldown  int    `json:"cooldown,omitempty"`
+	// Priorities are the priority tiers of the pods to be evicted in order
+	Priorities []string `json:"priorities,omitempty"`
 }
 
 // newConfig returns default memory Evcit configuration
@@ -49,6 +53,8 @@ func newConfig() *Config {
 		Interval:  defaultInterval,
 		Threshold: defaultThreshold,
 		Cooldown:  defaultCooldown,
+		// the pods of the tiers not listed are never evicted
+		Priorities: common.DefaultPriorities(),
 	}
 }
 
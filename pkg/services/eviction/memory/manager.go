@@ -58,6 +58,14 @@ func (m *Manager) SetConfig(f helper.ConfigHandler) error {
 	return nil
 }
 
+// ValidateConfig checks the configuration without replacing the controller of the shared manager
+func (m *Manager) ValidateConfig(f helper.ConfigHandler) error {
+	if _, err := fromConfig(m.Name, f); err != nil {
+		return fmt.Errorf("failed to create controller %v: %v", m.Name, err)
+	}
+	return nil
+}
+
 // SetConfig is an interface that invoke the ConfigHandler to obtain the corresponding configuration.
 func (m *Manager) GetConfig() interface{} {
 	return m.Manager.GetConfig(m.Name)
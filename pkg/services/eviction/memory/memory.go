@@ -26,6 +26,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	"isula.org/rubik/pkg/common/log"
+	"isula.org/rubik/pkg/core/typedef"
+	"isula.org/rubik/pkg/services/eviction/common"
 	"isula.org/rubik/pkg/services/helper"
 )
 
@@ -34,6 +36,8 @@ type Controller struct {
 	sync.RWMutex
 	conf  *Config
 	block int32
+	// priorities are the priority tiers of the pods to be evicted in order
+	priorities []typedef.Priority
 }
 
 // fromConfig generates Memory Controller based on configuration
@@ -45,8 +49,13 @@ func fromConfig(name string, f helper.ConfigHandler) (*Controller, error) {
 	if err := conf.validate(); err != nil {
 		return nil, err
 	}
+	priorities, err := common.ParsePriorities(conf.Priorities)
+	if err != nil {
+		return nil, err
+	}
 	return &Controller{
-		conf: conf,
+		conf:       conf,
+		priorities: priorities,
 	}, nil
 }
 
@@ -94,3 +103,8 @@ func (c *Controller) assertWithinLimit() bool {
 func (c *Controller) Config() interface{} {
 	return c.conf
 }
+
+// Priorities returns the priority tiers of the pods to be evicted in order
+func (c *Controller) Priorities() []typedef.Priority {
+	return c.priorities
+}
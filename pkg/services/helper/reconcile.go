@@ -0,0 +1,71 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// rubik licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//     http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Author: agent
+// Create: 2026-10-17
+// Description: This file helps services to detect and repair the drift of the cgroup files
+
+package helper
+
+import (
+	"fmt"
+	"strings"
+
+	"isula.org/rubik/pkg/common/audit"
+	"isula.org/rubik/pkg/core/typedef/cgroup"
+)
+
+// Drift is a cgroup file of a pod or a container changed by others after the service applied it
+type Drift struct {
+	// Path is the cgroup path of the pod or the container
+	Path     string
+	File     string
+	Expected string
+	Actual   string
+}
+
+// String returns the description of the drift
+func (d *Drift) String() string {
+	return fmt.Sprintf("%v of %v is %q, expect %q", d.File, d.Path, d.Actual, d.Expected)
+}
+
+// ReconcileCgroupAttr restores the cgroup file of the hierarchy if its value is not the expected one.
+// The drift is returned if the value is changed, even though it fails to be restored.
+func ReconcileCgroupAttr(h *cgroup.Hierarchy, key *cgroup.Key, expected string) (*Drift, error) {
+	attr := h.GetCgroupAttr(key)
+	if attr.Err != nil {
+		return nil, attr.Err
+	}
+	if attr.Value == expected {
+		return nil, nil
+	}
+	drift := &Drift{Path: h.Path, File: key.FileName, Expected: expected, Actual: attr.Value}
+	if err := h.SetCgroupAttr(key, expected); err != nil {
+		return drift, fmt.Errorf("failed to restore %v: %v", key.FileName, err)
+	}
+	return drift, nil
+}
+
+// ReconcileCgroupFile restores the cgroup file of the path in the subsystem if its value is not the expected one.
+// The drift is returned if the value is changed, even though it fails to be restored.
+func ReconcileCgroupFile(origin audit.Origin, expected, subsys, path, file string) (*Drift, error) {
+	data, err := cgroup.ReadCgroupFile(subsys, path, file)
+	if err != nil {
+		return nil, err
+	}
+	actual := strings.TrimSpace(string(data))
+	if actual == expected {
+		return nil, nil
+	}
+	drift := &Drift{Path: path, File: file, Expected: expected, Actual: actual}
+	if err := cgroup.WriteCgroupFileAs(origin, expected, subsys, path, file); err != nil {
+		return drift, fmt.Errorf("failed to restore %v: %v", file, err)
+	}
+	return drift, nil
+}
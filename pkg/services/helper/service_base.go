@@ -34,6 +34,11 @@ func NewServiceBase(serviceName string) *ServiceBase {
 	}
 }
 
+// Log returns the logger of the service, whose log level can be overridden by the service name
+func (s *ServiceBase) Log() *log.Entry {
+	return log.WithModule(s.Name).WithService(s.Name)
+}
+
 // ConfigHandler is that obtains the configured callback function.
 type ConfigHandler func(configName string, d interface{}) error
 
@@ -21,6 +21,7 @@ import (
 	"unicode"
 
 	"isula.org/rubik/pkg/api"
+	"isula.org/rubik/pkg/common/audit"
 	"isula.org/rubik/pkg/common/constant"
 	"isula.org/rubik/pkg/common/log"
 	"isula.org/rubik/pkg/common/util"
@@ -35,6 +36,9 @@ const (
 	offlineWeight = 10
 	onlineWeight  = 1000
 	scale         = 10
+	// the range of blkio.cost.weight
+	minWeight = 1
+	maxWeight = 10000
 )
 
 // LinearParam for linear model
@@ -47,6 +51,14 @@ type LinearParam struct {
 	Wrandiops int64 `json:"wrandiops,omitempty"`
 }
 
+func (param *LinearParam) validate() error {
+	if param.Rbps <= 0 || param.Rseqiops <= 0 || param.Rrandiops <= 0 ||
+		param.Wbps <= 0 || param.Wseqiops <= 0 || param.Wrandiops <= 0 {
+		return fmt.Errorf("invalid params, linear params must be greater than 0")
+	}
+	return nil
+}
+
 // IOCostConfig define iocost for node
 type IOCostConfig struct {
 	Dev   string      `json:"dev,omitempty"`
@@ -58,11 +70,14 @@ type IOCostConfig struct {
 type NodeConfig struct {
 	NodeName     string         `json:"nodeName,omitempty"`
 	IOCostConfig []IOCostConfig `json:"config,omitempty"`
+	// Weights maps the priority tiers to the iocost weights of the pods
+	Weights map[string]uint64 `json:"weights,omitempty"`
 }
 
 // IOCost for iocost class
 type IOCost struct {
 	helper.ServiceBase
+	weights map[string]uint64
 }
 
 var (
@@ -90,6 +105,18 @@ func (i IOCostFactory) NewObj() (interface{}, error) {
 
 // ioCostSupport tell if the os support iocost.
 func ioCostSupport() bool {
+	// writeback is always enabled in cgroup v2 while cgroup v1 requires the cmdline option
+	if !cgroup.IsUnified() && !writebackSupport() {
+		return false
+	}
+
+	qosFile := cgroup.AbsoluteCgroupPath(blkcgRootDir, iocostQosFile)
+	modelFile := cgroup.AbsoluteCgroupPath(blkcgRootDir, iocostModelFile)
+	return util.PathExist(qosFile) && util.PathExist(modelFile)
+}
+
+// writebackSupport tell if the cgroup v1 writeback is enabled.
+func writebackSupport() bool {
 	cmdLine, err := util.ReadSmallFile("/proc/cmdline")
 	if err != nil {
 		log.Warnf("get /pro/cmdline error:%v", err)
@@ -100,21 +127,67 @@ func ioCostSupport() bool {
 		log.Warnf("current machine does not support writeback, please add 'cgroup1_writeback' to cmdline")
 		return false
 	}
-
-	qosFile := cgroup.AbsoluteCgroupPath(blkcgRootDir, iocostQosFile)
-	modelFile := cgroup.AbsoluteCgroupPath(blkcgRootDir, iocostModelFile)
-	return util.PathExist(qosFile) && util.PathExist(modelFile)
+	return true
 }
 
 // SetConfig to config nodeConfig configure
 func (io *IOCost) SetConfig(f helper.ConfigHandler) error {
+	nodeConfig, err := io.getNodeConfig(f)
+	if err != nil {
+		return err
+	}
+	if err := validateWeights(nodeConfig.Weights); err != nil {
+		return err
+	}
+	io.weights = nodeConfig.Weights
+	return io.loadConfig(nodeConfig)
+}
+
+// ValidateConfig checks nodeConfig configure without writing iocost files
+func (io *IOCost) ValidateConfig(f helper.ConfigHandler) error {
+	nodeConfig, err := io.getNodeConfig(f)
+	if err != nil {
+		return err
+	}
+	if err := validateWeights(nodeConfig.Weights); err != nil {
+		return err
+	}
+	for _, config := range nodeConfig.IOCostConfig {
+		if _, err := getBlkDeviceNo(config.Dev); err != nil {
+			return err
+		}
+		if config.Model != "linear" {
+			return fmt.Errorf("non-linear models are not supported")
+		}
+		if err := config.Param.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateWeights checks the priority tiers and the iocost weights
+func validateWeights(weights map[string]uint64) error {
+	for name, weight := range weights {
+		if _, err := typedef.ParsePriority(name); err != nil {
+			return err
+		}
+		if weight < minWeight || weight > maxWeight {
+			return fmt.Errorf("iocost weight %d of %v out of range [%d,%d]", weight, name, minWeight, maxWeight)
+		}
+	}
+	return nil
+}
+
+// getNodeConfig returns the configuration matching the current node
+func (io *IOCost) getNodeConfig(f helper.ConfigHandler) (*NodeConfig, error) {
 	if f == nil {
-		return fmt.Errorf("no config handler function callback")
+		return nil, fmt.Errorf("no config handler function callback")
 	}
 
 	var nodeConfigs []NodeConfig
 	if err := f(io.Name, &nodeConfigs); err != nil {
-		return err
+		return nil, err
 	}
 
 	var nodeConfig NodeConfig
@@ -127,7 +200,7 @@ func (io *IOCost) SetConfig(f helper.ConfigHandler) error {
 			nodeConfig = config
 		}
 	}
-	return io.loadConfig(&nodeConfig)
+	return &nodeConfig, nil
 }
 
 func (io *IOCost) loadConfig(nodeConfig *NodeConfig) error {
@@ -197,14 +270,14 @@ func (io *IOCost) configIOCost(configs []IOCostConfig) error {
 			return err
 		}
 		if config.Model == "linear" {
-			if err := configIOCostModel(devno, config.Param); err != nil {
+			if err := configIOCostModel(audit.Origin{Service: io.Name}, devno, config.Param); err != nil {
 				return err
 			}
 		} else {
 			return fmt.Errorf("non-linear models are not supported")
 		}
 
-		if err := configIOCostQoS(devno, true); err != nil {
+		if err := configIOCostQoS(audit.Origin{Service: io.Name}, devno, true); err != nil {
 			return err
 		}
 	}
@@ -226,7 +299,7 @@ func (io *IOCost) clearIOCost() error {
 	for _, qosParam := range qosParams {
 		words := strings.FieldsFunc(qosParam, unicode.IsSpace)
 		if len(words) != 0 {
-			if err := configIOCostQoS(words[0], false); err != nil {
+			if err := configIOCostQoS(audit.Origin{Service: io.Name}, words[0], false); err != nil {
 				return err
 			}
 		}
@@ -235,9 +308,13 @@ func (io *IOCost) clearIOCost() error {
 }
 
 func (io *IOCost) configPodIOCostWeight(podInfo *typedef.PodInfo) error {
-	var weight uint64 = offlineWeight
-	if podInfo.Online() {
-		weight = onlineWeight
+	priority := podInfo.Priority()
+	weight, ok := io.weights[priority.String()]
+	if !ok {
+		weight = offlineWeight
+		if !priority.Offline() {
+			weight = onlineWeight
+		}
 	}
-	return configPodIOCostWeight(podInfo.Path, weight)
+	return configPodIOCostWeight(audit.Origin{Service: io.Name, PodUID: podInfo.UID}, podInfo.Path, weight)
 }
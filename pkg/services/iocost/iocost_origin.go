@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"strconv"
 
+	"isula.org/rubik/pkg/common/audit"
 	"isula.org/rubik/pkg/core/typedef/cgroup"
 )
 
@@ -33,23 +34,22 @@ const (
 )
 
 // configIOCostQoS for config iocost qos.
-func configIOCostQoS(devno string, enable bool) error {
+func configIOCostQoS(origin audit.Origin, devno string, enable bool) error {
 	t := 0
 	if enable {
 		t = 1
 	}
 	qosParam := fmt.Sprintf("%v enable=%v ctrl=user min=100.00 max=100.00", devno, t)
-	return cgroup.WriteCgroupFile(qosParam, blkcgRootDir, iocostQosFile)
+	return cgroup.WriteCgroupFileAs(origin, qosParam, blkcgRootDir, iocostQosFile)
 }
 
 // configIOCostModel for config iocost model
-func configIOCostModel(devno string, p interface{}) error {
+func configIOCostModel(origin audit.Origin, devno string, p interface{}) error {
 	var paramStr string
 	switch param := p.(type) {
 	case LinearParam:
-		if param.Rbps <= 0 || param.Rseqiops <= 0 || param.Rrandiops <= 0 ||
-			param.Wbps <= 0 || param.Wseqiops <= 0 || param.Wrandiops <= 0 {
-			return fmt.Errorf("invalid params, linear params must be greater than 0")
+		if err := param.validate(); err != nil {
+			return err
 		}
 
 		paramStr = fmt.Sprintf("%v rbps=%v rseqiops=%v rrandiops=%v wbps=%v wseqiops=%v wrandiops=%v",
@@ -60,26 +60,31 @@ func configIOCostModel(devno string, p interface{}) error {
 	default:
 		return fmt.Errorf("invalid model param")
 	}
-	return cgroup.WriteCgroupFile(paramStr, blkcgRootDir, iocostModelFile)
+	return cgroup.WriteCgroupFileAs(origin, paramStr, blkcgRootDir, iocostModelFile)
 }
 
 // configPodIOCostWeight for config iocost weight
 // cgroup v1 iocost cannot be inherited. Therefore, only the container level can be configured.
-func configPodIOCostWeight(relativePath string, weight uint64) error {
-	if err := cgroup.WriteCgroupFile(strconv.FormatUint(weight, scale), blkcgRootDir,
+func configPodIOCostWeight(origin audit.Origin, relativePath string, weight uint64) error {
+	if err := cgroup.WriteCgroupFileAs(origin, strconv.FormatUint(weight, scale), blkcgRootDir,
 		relativePath, iocostWeightFile); err != nil {
 		return err
 	}
-	return bindMemcgBlkcg(relativePath)
+	// memcg and blkcg are bound naturally in the unified hierarchy
+	if cgroup.IsUnified() {
+		return nil
+	}
+	return bindMemcgBlkcg(origin, relativePath)
 }
 
 // bindMemcgBlkcg for bind memcg and blkcg
-func bindMemcgBlkcg(containerRelativePath string) error {
+func bindMemcgBlkcg(origin audit.Origin, containerRelativePath string) error {
 	blkcgPath := cgroup.AbsoluteCgroupPath(blkcgRootDir, containerRelativePath)
 	ino, err := getDirInode(blkcgPath)
 	if err != nil {
 		return err
 	}
 
-	return cgroup.WriteCgroupFile(strconv.FormatUint(ino, scale), memcgRootDir, containerRelativePath, wbBlkioinoFile)
+	return cgroup.WriteCgroupFileAs(origin, strconv.FormatUint(ino, scale), memcgRootDir, containerRelativePath,
+		wbBlkioinoFile)
 }
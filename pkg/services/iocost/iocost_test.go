@@ -11,6 +11,7 @@ import (
 	"unicode"
 
 	"github.com/stretchr/testify/assert"
+	"isula.org/rubik/pkg/common/audit"
 	"isula.org/rubik/pkg/common/constant"
 	"isula.org/rubik/pkg/core/typedef/cgroup"
 	"isula.org/rubik/pkg/services/helper"
@@ -334,7 +335,7 @@ func TestSetPodWeight(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := configPodIOCostWeight(tt.cgroupPath, uint64(tt.weight))
+			err := configPodIOCostWeight(audit.Origin{}, tt.cgroupPath, uint64(tt.weight))
 			if tt.wantErr {
 				assert.Contains(t, err.Error(), tt.errMsg)
 				return
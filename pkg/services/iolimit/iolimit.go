@@ -24,14 +24,22 @@ import (
 	"syscall"
 
 	"golang.org/x/sys/unix"
+	corev1 "k8s.io/api/core/v1"
+
 	"isula.org/rubik/pkg/api"
+	"isula.org/rubik/pkg/common/audit"
 	"isula.org/rubik/pkg/common/constant"
 	"isula.org/rubik/pkg/common/log"
+	"isula.org/rubik/pkg/common/util"
 	"isula.org/rubik/pkg/core/typedef"
 	"isula.org/rubik/pkg/core/typedef/cgroup"
+	"isula.org/rubik/pkg/lib/kubernetes"
 	"isula.org/rubik/pkg/services/helper"
 )
 
+// reasonInvalidConfig is the reason of the event on the pod whose blkio annotation is invalid
+const reasonInvalidConfig = "InvalidBlkioConfig"
+
 // convertToMajorMinorFunc is a function variable that can be replaced in tests
 var convertToMajorMinorFunc = convertToMajorMinorImpl
 
@@ -46,6 +54,29 @@ const (
 	deviceWriteIopsFile = "blkio.throttle.write_iops_device"
 )
 
+// throttleFiles are the blkio throttle files configured by ioLimit
+var throttleFiles = []string{
+	deviceReadBpsFile,
+	deviceWriteBpsFile,
+	deviceReadIopsFile,
+	deviceWriteIopsFile,
+}
+
+func init() {
+	// the devices absent in the original value of the throttle file are reset before restoring the original value
+	for _, file := range throttleFiles {
+		audit.RegisterRestorer(file, func(original, current string) []string {
+			var values []string
+			for _, value := range []string{parseAndResetParams(current), original} {
+				if value != "" {
+					values = append(values, value)
+				}
+			}
+			return values
+		})
+	}
+}
+
 // DeviceConfig defines blkio device configurations.
 type DeviceConfig struct {
 	DeviceName  string `json:"device,omitempty"`
@@ -104,9 +135,8 @@ func (i *IOLimit) PreStart(viewer api.Viewer) error {
 }
 
 // Terminate performs cleanup work for IOLimit.
-// Currently no cleanup is needed, so it just returns nil.
+// The throttles are restored by the revert journal after the service terminates, so it just returns nil.
 func (i *IOLimit) Terminate(_ api.Viewer) error {
-	// nothing to do here, just return nil.
 	return nil
 }
 
@@ -142,10 +172,11 @@ func (i *IOLimit) configIOLimit(podInfo *typedef.PodInfo) error {
 		return nil
 	}
 
+	origin := audit.Origin{Service: i.Name, PodUID: podInfo.UID}
 	// firstly clear all config
 	// blkio cgroup hierarchical is not enabled default, only set container cgroups
 	for _, container := range podInfo.IDContainersMap {
-		if err := clearAllBlkioThrottleFiles(container.Path); err != nil {
+		if err := clearAllBlkioThrottleFiles(origin, container.Path); err != nil {
 			return fmt.Errorf("failed to clear blkio throttle files for container %s of pod %s: %v", container.Name, podInfo.Name, err)
 		}
 	}
@@ -153,12 +184,14 @@ func (i *IOLimit) configIOLimit(podInfo *typedef.PodInfo) error {
 	// secondly parse the config
 	cfg, err := parseIOLimitConfig(cfgString)
 	if err != nil {
+		kubernetes.RecordEvent(kubernetes.PodReference(podInfo.Namespace, podInfo.Name, podInfo.UID),
+			corev1.EventTypeWarning, reasonInvalidConfig, "invalid annotation %v: %v", constant.BlkioKey, err)
 		return fmt.Errorf("parse blkio config for pod %s failed: %v", podInfo.Name, err)
 	}
 
 	// thirdly apply the config to cgroup files
 	for _, container := range podInfo.IDContainersMap {
-		if err := applyIOLimitConfig(container.Path, cfg); err != nil {
+		if err := applyIOLimitConfig(origin, container.Path, cfg); err != nil {
 			return fmt.Errorf("failed to apply blkio config for container %s of pod %s: %v", container.Name, podInfo.Name, err)
 		}
 	}
@@ -166,6 +199,79 @@ func (i *IOLimit) configIOLimit(podInfo *typedef.PodInfo) error {
 	return nil
 }
 
+// Reconcile restores the blkio throttles of the containers changed by others.
+// Only the configured devices are compared, and all throttles of the container are applied again if any changes.
+func (i *IOLimit) Reconcile(podInfo *typedef.PodInfo) ([]*helper.Drift, error) {
+	cfgString := podInfo.Annotations[constant.BlkioKey]
+	if len(cfgString) == 0 {
+		return nil, nil
+	}
+	cfg, err := parseIOLimitConfig(cfgString)
+	if err != nil {
+		// the invalid config is reported when the pod is added
+		return nil, nil
+	}
+
+	var (
+		drifts []*helper.Drift
+		errs   error
+		origin = audit.Origin{Service: i.Name, PodUID: podInfo.UID}
+	)
+	for _, container := range podInfo.IDContainersMap {
+		var drifted bool
+		for _, config := range throttleConfigs(cfg) {
+			drift, err := throttleDrift(container.Path, config.fileName, config.devices)
+			if err != nil {
+				errs = util.AppendErr(errs, err)
+				continue
+			}
+			if drift != nil {
+				drifts = append(drifts, drift)
+				drifted = true
+			}
+		}
+		if drifted {
+			errs = util.AppendErr(errs, applyIOLimitConfig(origin, container.Path, cfg))
+		}
+	}
+	return drifts, errs
+}
+
+// throttleDrift returns the drift if the throttle of any configured device is not the configured value
+func throttleDrift(cgroupPath, fileName string, devices []DeviceConfig) (*helper.Drift, error) {
+	configLines := deviceConfigLines(devices)
+	if len(configLines) == 0 {
+		return nil, nil
+	}
+	params, err := cgroup.ReadCgroupFile(blkcgRootDir, cgroupPath, fileName)
+	if err != nil {
+		return nil, fmt.Errorf("read cgroup file %s failed: %v", fileName, err)
+	}
+	var actual = make(map[string]string)
+	for _, line := range strings.Split(string(params), "\n") {
+		if parts := strings.Fields(line); len(parts) >= 2 {
+			actual[parts[0]] = parts[1]
+		}
+	}
+	for _, line := range configLines {
+		parts := strings.Fields(line)
+		value, ok := actual[parts[0]]
+		// the device whose throttle is 0 is not listed in the file
+		if !ok {
+			value = "0"
+		}
+		if value != parts[1] {
+			return &helper.Drift{
+				Path:     cgroupPath,
+				File:     fileName,
+				Expected: strings.Join(configLines, "\n"),
+				Actual:   strings.TrimSpace(string(params)),
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
 // parseIOLimitConfig parses the blkio configuration string into a BlkConfig struct.
 // The input string should be in JSON format representing the blkio configuration.
 // It returns a BlkConfig struct or an error if parsing fails.
@@ -189,16 +295,9 @@ func parseIOLimitConfig(blkioCfg string) (*BlkConfig, error) {
 
 // clearAllBlkioThrottleFiles clears all 4 blkio throttle files for a given cgroup path.
 // This resets all device throttle configurations to default values.
-func clearAllBlkioThrottleFiles(cgroupPath string) error {
-	files := []string{
-		deviceReadBpsFile,
-		deviceWriteBpsFile,
-		deviceReadIopsFile,
-		deviceWriteIopsFile,
-	}
-
-	for _, file := range files {
-		if err := clearConfig(cgroupPath, file); err != nil {
+func clearAllBlkioThrottleFiles(origin audit.Origin, cgroupPath string) error {
+	for _, file := range throttleFiles {
+		if err := clearConfig(origin, cgroupPath, file); err != nil {
 			return fmt.Errorf("failed to clear %s: %v", file, err)
 		}
 	}
@@ -207,7 +306,7 @@ func clearAllBlkioThrottleFiles(cgroupPath string) error {
 }
 
 // clearConfig clears a specific blkio throttle file by resetting all device values to 0.
-func clearConfig(cgroupPath, file string) error {
+func clearConfig(origin audit.Origin, cgroupPath, file string) error {
 	params, err := cgroup.ReadCgroupFile(blkcgRootDir, cgroupPath, file)
 	if err != nil {
 		return fmt.Errorf("read cgroup file %s failed: %v", file, err)
@@ -222,7 +321,7 @@ func clearConfig(cgroupPath, file string) error {
 	resetContent := parseAndResetParams(string(params))
 
 	// Write the reset content back to the file
-	if err := cgroup.WriteCgroupFile(resetContent, blkcgRootDir, cgroupPath, file); err != nil {
+	if err := cgroup.WriteCgroupFileAs(origin, resetContent, blkcgRootDir, cgroupPath, file); err != nil {
 		return fmt.Errorf("reset cgroup file %s failed: %v", file, err)
 	}
 	log.Infof("successfully reset cgroup file %s", file)
@@ -259,27 +358,32 @@ func parseAndResetParams(params string) string {
 	return strings.Join(resetLines, "\n")
 }
 
-// applyIOLimitConfig applies the parsed BlkConfig to all corresponding cgroup files.
-func applyIOLimitConfig(cgroupPath string, cfg *BlkConfig) error {
-	if cfg == nil {
-		return fmt.Errorf("config is nil")
-	}
+// throttleConfig is the devices configured in a throttle file
+type throttleConfig struct {
+	fileName    string
+	devices     []DeviceConfig
+	description string
+}
 
-	// Define the device config mappings
-	deviceConfigs := []struct {
-		fileName    string
-		devices     []DeviceConfig
-		description string
-	}{
+// throttleConfigs returns the devices configured in each throttle file
+func throttleConfigs(cfg *BlkConfig) []throttleConfig {
+	return []throttleConfig{
 		{deviceReadBpsFile, cfg.DeviceReadBps, "device read bps"},
 		{deviceWriteBpsFile, cfg.DeviceWriteBps, "device write bps"},
 		{deviceReadIopsFile, cfg.DeviceReadIops, "device read iops"},
 		{deviceWriteIopsFile, cfg.DeviceWriteIops, "device write iops"},
 	}
+}
+
+// applyIOLimitConfig applies the parsed BlkConfig to all corresponding cgroup files.
+func applyIOLimitConfig(origin audit.Origin, cgroupPath string, cfg *BlkConfig) error {
+	if cfg == nil {
+		return fmt.Errorf("config is nil")
+	}
 
 	// Apply all device configs in a loop
-	for _, config := range deviceConfigs {
-		if err := applyDeviceConfig(cgroupPath, config.fileName, config.devices); err != nil {
+	for _, config := range throttleConfigs(cfg) {
+		if err := applyDeviceConfig(origin, cgroupPath, config.fileName, config.devices); err != nil {
 			return fmt.Errorf("failed to apply %s config: %v", config.description, err)
 		}
 	}
@@ -287,12 +391,29 @@ func applyIOLimitConfig(cgroupPath string, cfg *BlkConfig) error {
 }
 
 // applyDeviceConfig applies device configurations to a specific cgroup throttle file.
-func applyDeviceConfig(cgroupPath, fileName string, devices []DeviceConfig) error {
+func applyDeviceConfig(origin audit.Origin, cgroupPath, fileName string, devices []DeviceConfig) error {
 	if len(devices) == 0 {
 		log.Infof("no device config for file %s, skip", fileName)
 		return nil
 	}
 
+	configLines := deviceConfigLines(devices)
+	if len(configLines) == 0 {
+		log.Infof("no valid device config for file %s, skip", fileName)
+		return nil
+	}
+
+	configContent := strings.Join(configLines, "\n")
+	if err := cgroup.WriteCgroupFileAs(origin, configContent, blkcgRootDir, cgroupPath, fileName); err != nil {
+		return fmt.Errorf("failed to write config to file %s: %v", fileName, err)
+	}
+
+	return nil
+}
+
+// deviceConfigLines converts the device configurations to the lines of the throttle file,
+// in the format of "major:minor value".
+func deviceConfigLines(devices []DeviceConfig) []string {
 	var configLines []string
 	for _, device := range devices {
 		if device.DeviceName == "" || device.DeviceValue == "" {
@@ -310,18 +431,7 @@ func applyDeviceConfig(cgroupPath, fileName string, devices []DeviceConfig) erro
 		configLine := fmt.Sprintf("%s %s", majorMinor, device.DeviceValue)
 		configLines = append(configLines, configLine)
 	}
-
-	if len(configLines) == 0 {
-		log.Infof("no valid device config for file %s, skip", fileName)
-		return nil
-	}
-
-	configContent := strings.Join(configLines, "\n")
-	if err := cgroup.WriteCgroupFile(configContent, blkcgRootDir, cgroupPath, fileName); err != nil {
-		return fmt.Errorf("failed to write config to file %s: %v", fileName, err)
-	}
-
-	return nil
+	return configLines
 }
 
 // convertToMajorMinor converts device name to major:minor format.
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"isula.org/rubik/pkg/api"
+	"isula.org/rubik/pkg/common/audit"
 	"isula.org/rubik/pkg/common/constant"
 	"isula.org/rubik/pkg/core/typedef"
 	"isula.org/rubik/pkg/core/typedef/cgroup"
@@ -673,7 +674,7 @@ func TestApplyDeviceConfig(t *testing.T) {
 				t.Fatalf("Failed to create test file: %v", err)
 			}
 
-			err = applyDeviceConfig(testPodPath, testFileName, tt.devices)
+			err = applyDeviceConfig(audit.Origin{}, testPodPath, testFileName, tt.devices)
 
 			if tt.expectError {
 				if err == nil {
@@ -732,7 +733,7 @@ func TestApplyIOLimitConfig(t *testing.T) {
 	}
 
 	// Test with nil config
-	err = applyIOLimitConfig(testPodPath, nil)
+	err = applyIOLimitConfig(audit.Origin{}, testPodPath, nil)
 	if err == nil {
 		t.Error("Expected error with nil config")
 	}
@@ -771,7 +772,7 @@ func TestApplyIOLimitConfig(t *testing.T) {
 		}
 	}
 
-	err = applyIOLimitConfig(testPodPath, cfg)
+	err = applyIOLimitConfig(audit.Origin{}, testPodPath, cfg)
 	if err != nil {
 		t.Errorf("Should not error with valid config and mock devices, got %v", err)
 	}
@@ -1030,7 +1031,7 @@ func TestClearConfig(t *testing.T) {
 			}
 
 			// Call clearConfig
-			err := clearConfig(testPodPath, tt.fileName)
+			err := clearConfig(audit.Origin{}, testPodPath, tt.fileName)
 
 			if tt.expectError {
 				if err == nil {
@@ -1099,7 +1100,7 @@ func TestClearAllBlkioThrottleFiles(t *testing.T) {
 	}
 
 	// Call clearAllBlkioThrottleFiles
-	err = clearAllBlkioThrottleFiles(testPodPath)
+	err = clearAllBlkioThrottleFiles(audit.Origin{}, testPodPath)
 	if err != nil {
 		t.Errorf("clearAllBlkioThrottleFiles returned error: %v", err)
 		return
@@ -1120,7 +1121,7 @@ func TestClearAllBlkioThrottleFiles(t *testing.T) {
 	}
 
 	// Test with non-existent directory
-	err = clearAllBlkioThrottleFiles("/non/existent/path")
+	err = clearAllBlkioThrottleFiles(audit.Origin{}, "/non/existent/path")
 	if err == nil {
 		t.Error("Expected error with non-existent directory")
 	}
@@ -1278,7 +1279,7 @@ func TestApplyIOLimitConfig_ErrorCases(t *testing.T) {
 	}
 
 	// Don't create the cgroup files - this should cause write errors
-	err := applyIOLimitConfig(testPodPath, cfg)
+	err := applyIOLimitConfig(audit.Origin{}, testPodPath, cfg)
 	if err == nil {
 		t.Error("Expected error when cgroup files don't exist")
 	}
@@ -1301,7 +1302,7 @@ func TestApplyDeviceConfig_ErrorCases(t *testing.T) {
 	}
 
 	// Test with non-existent cgroup path - should cause write error
-	err := applyDeviceConfig(testPodPath, testFileName, devices)
+	err := applyDeviceConfig(audit.Origin{}, testPodPath, testFileName, devices)
 	if err == nil {
 		t.Error("Expected error when cgroup file doesn't exist")
 	}
@@ -1328,7 +1329,7 @@ func TestClearConfig_EdgeCases(t *testing.T) {
 
 	// Test 1: File doesn't exist - should return error
 	testFileName := "nonexistent_file"
-	err = clearConfig(testPodPath, testFileName)
+	err = clearConfig(audit.Origin{}, testPodPath, testFileName)
 	if err == nil {
 		t.Error("Expected error when file doesn't exist")
 	}
@@ -1343,7 +1344,7 @@ func TestClearConfig_EdgeCases(t *testing.T) {
 	}
 
 	// Clear the config
-	err = clearConfig(testPodPath, testFileName)
+	err = clearConfig(audit.Origin{}, testPodPath, testFileName)
 	if err != nil {
 		t.Errorf("Unexpected error when clearing existing file: %v", err)
 		return
@@ -1398,3 +1399,51 @@ func TestConfigIOLimit_EdgeCases(t *testing.T) {
 		}
 	}
 }
+
+func TestThrottleDrift(t *testing.T) {
+	tempDir, cleanupCgroup := setupTestCgroupEnv(t)
+	defer cleanupCgroup()
+	cleanupMock := setupMockConvertToMajorMinor()
+	defer cleanupMock()
+
+	testPodPath := "test-pod"
+	testPodDir := filepath.Join(tempDir, "blkio", testPodPath)
+	if err := os.MkdirAll(testPodDir, 0755); err != nil {
+		t.Fatalf("Failed to create test pod directory: %v", err)
+	}
+	fileName := "blkio.throttle.read_bps_device"
+	devices := []DeviceConfig{
+		{DeviceName: "/dev/sda", DeviceValue: "1048576"},
+		{DeviceName: "8:16", DeviceValue: "0"},
+	}
+
+	// Test reading the missing file
+	if _, err := throttleDrift(testPodPath, fileName, devices); err == nil {
+		t.Error("Expected error with missing cgroup file")
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		drifted bool
+	}{
+		{name: "same values", content: "8:0 1048576\n", drifted: false},
+		{name: "changed value", content: "8:0 2097152\n", drifted: true},
+		{name: "removed device", content: "", drifted: true},
+		{name: "unexpected throttle", content: "8:0 1048576\n8:16 1000\n", drifted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ioutil.WriteFile(filepath.Join(testPodDir, fileName), []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write cgroup file: %v", err)
+			}
+			drift, err := throttleDrift(testPodPath, fileName, devices)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if (drift != nil) != tt.drifted {
+				t.Errorf("Expected drifted %v, got %v", tt.drifted, drift)
+			}
+		})
+	}
+}
@@ -18,8 +18,10 @@ import (
 	"os"
 	"strconv"
 
+	"isula.org/rubik/pkg/common/audit"
 	"isula.org/rubik/pkg/common/constant"
 	"isula.org/rubik/pkg/core/typedef"
+	"isula.org/rubik/pkg/core/typedef/cgroup"
 )
 
 const (
@@ -43,6 +45,9 @@ func getNetLevelStr(qosLevel int) string {
 }
 
 func validateNetResConf(conf *PreemptionConfig) error {
+	if cgroup.IsUnified() {
+		return fmt.Errorf("net preemption depends on the net_cls subsystem which is not available in cgroup v2")
+	}
 	if !isSupportNetqos() {
 		return fmt.Errorf("this machine does not support net preemption, please install oncn-bwm first.")
 	}
@@ -77,32 +82,33 @@ func enableNetRes(pod *typedef.PodInfo) (bool, error) {
 		return false, nil
 	}
 
-	if err = enablePodNetqos(pid); err != nil {
-		disablePodNetqos(pid)
+	origin := audit.Origin{Service: pod.Owner, PodUID: pod.UID}
+	if err = enablePodNetqos(origin, pid); err != nil {
+		disablePodNetqos(origin, pid)
 		return true, err
 	}
 
 	return true, nil
 }
 
-func initNetRes(conf *PreemptionConfig) error {
+func initNetRes(origin audit.Origin, conf *PreemptionConfig) error {
 	var err error
 	pid := strconv.Itoa(os.Getpid())
 	defer func() {
 		if err != nil {
-			disablePodNetqos(pid)
+			disablePodNetqos(origin, pid)
 		}
 	}()
-	if err = enablePodNetqos(pid); err != nil {
+	if err = enablePodNetqos(origin, pid); err != nil {
 		return err
 	}
 
 	// The bandwidth or waterline can be set only after netqos has been enabled at least once.
-	if err = setPodNetqosWaterline(conf.Net.Waterline); err != nil {
+	if err = setPodNetqosWaterline(origin, conf.Net.Waterline); err != nil {
 		return err
 	}
 
-	if err = setPodNetqosBandwidth(conf.Net.BandwidthLow, conf.Net.BandwidthHigh); err != nil {
+	if err = setPodNetqosBandwidth(origin, conf.Net.BandwidthLow, conf.Net.BandwidthHigh); err != nil {
 		return err
 	}
 
@@ -18,6 +18,7 @@ import (
 	"strconv"
 	"strings"
 
+	"isula.org/rubik/pkg/common/audit"
 	"isula.org/rubik/pkg/common/util"
 	"isula.org/rubik/pkg/core/typedef"
 	"isula.org/rubik/pkg/core/typedef/cgroup"
@@ -66,33 +67,33 @@ func getPodProcID(pod *typedef.PodInfo) (string, error) {
 	return "", fmt.Errorf("failed to find valid proc")
 }
 
-func enablePodNetqos(pid string) error {
-	if err := util.WriteFile(netQosEnablePath, pid); err != nil {
+func enablePodNetqos(origin audit.Origin, pid string) error {
+	if err := audit.WriteFile(origin, netQosEnablePath, pid); err != nil {
 		return fmt.Errorf("failed to write %s to file %s: %v", pid, netQosEnablePath, err)
 	}
 	return nil
 }
 
-func disablePodNetqos(pid string) error {
-	if err := util.WriteFile(netQosDisablePath, pid); err != nil {
+func disablePodNetqos(origin audit.Origin, pid string) error {
+	if err := audit.WriteFile(origin, netQosDisablePath, pid); err != nil {
 		return fmt.Errorf("failed to write %s to file %s: %v", pid, netQosDisablePath, err)
 	}
 	return nil
 }
 
-func setPodNetqosBandwidth(bandwidthLow, bandwidthHigh int) error {
+func setPodNetqosBandwidth(origin audit.Origin, bandwidthLow, bandwidthHigh int) error {
 	bandwidthStr := strconv.Itoa(bandwidthLow) + "mb," + strconv.Itoa(bandwidthHigh) + "mb"
 
-	if err := util.WriteFile(netQosBandwidthPath, bandwidthStr); err != nil {
+	if err := audit.WriteFile(origin, netQosBandwidthPath, bandwidthStr); err != nil {
 		return fmt.Errorf("failed to write %s to file %s: %v", bandwidthStr, netQosBandwidthPath, err)
 	}
 	return nil
 }
 
-func setPodNetqosWaterline(waterline int) error {
+func setPodNetqosWaterline(origin audit.Origin, waterline int) error {
 	waterlineStr := strconv.Itoa(waterline) + "mb"
 
-	if err := util.WriteFile(netQosWaterlinePath, waterlineStr); err != nil {
+	if err := audit.WriteFile(origin, netQosWaterlinePath, waterlineStr); err != nil {
 		return fmt.Errorf("failed to write %s to file %s: %v", waterlineStr, netQosWaterlinePath, err)
 	}
 	return nil
@@ -19,6 +19,7 @@ import (
 	"strconv"
 
 	"isula.org/rubik/pkg/api"
+	"isula.org/rubik/pkg/common/audit"
 	"isula.org/rubik/pkg/common/constant"
 	"isula.org/rubik/pkg/common/log"
 	"isula.org/rubik/pkg/common/util"
@@ -31,18 +32,24 @@ type resOpt struct {
 	cgKey           *cgroup.Key
 	getQosStr       func(int) string
 	validateResConf func(*PreemptionConfig) error
-	initRes         func(*PreemptionConfig) error
+	initRes         func(audit.Origin, *PreemptionConfig) error
 	enableRes       func(*typedef.PodInfo) (bool, error) // Return true to indicate that the res needs to enable qos
+	// minLevel and maxLevel are the range of the qos level supported by the kernel
+	minLevel, maxLevel int
 }
 
 var supportCgroupTypes = map[string]resOpt{
 	"cpu": {
 		cgKey:     &cgroup.Key{SubSys: "cpu", FileName: constant.CPUCgroupFileName},
 		getQosStr: func(qosLevel int) string { return strconv.Itoa(qosLevel) },
+		minLevel:  -2,
+		maxLevel:  2,
 	},
 	"memory": {
 		cgKey:     &cgroup.Key{SubSys: "memory", FileName: constant.MemoryCgroupFileName},
 		getQosStr: func(qosLevel int) string { return strconv.Itoa(qosLevel) },
+		minLevel:  constant.Offline,
+		maxLevel:  constant.Online,
 	},
 	"net": {
 		cgKey:           &cgroup.Key{SubSys: "net_cls", FileName: constant.NetCgroupFileName},
@@ -50,6 +57,8 @@ var supportCgroupTypes = map[string]resOpt{
 		validateResConf: validateNetResConf,
 		initRes:         initNetRes,
 		enableRes:       enableNetRes,
+		minLevel:        constant.Offline,
+		maxLevel:        constant.Online,
 	},
 }
 
@@ -63,6 +72,9 @@ type Preemption struct {
 type PreemptionConfig struct {
 	Resource []string  `json:"resource,omitempty"`
 	Net      NetConfig `json:"net,omitempty"`
+	// Levels maps the priority tiers to the qos levels, the offline tiers not configured are offline(-1)
+	// and the others are online(0)
+	Levels map[string]int `json:"levels,omitempty"`
 }
 
 // PreemptionFactory is the factory os Preemption.
@@ -97,6 +109,19 @@ func (q *Preemption) SetConfig(f helper.ConfigHandler) error {
 	return nil
 }
 
+// CheckCapability checks whether the kernel supports the qos level of the configured resources
+func (q *Preemption) CheckCapability() error {
+	for _, r := range q.config.Resource {
+		if err := cgroup.CheckKubepodsFile(supportCgroupTypes[r].cgKey); err != nil {
+			return fmt.Errorf("qos level of %v is not supported: %v", r, err)
+		}
+		if r == "net" && !isSupportNetqos() {
+			return fmt.Errorf("qos level of net is not supported: %v does not exist", netQosEnablePath)
+		}
+	}
+	return nil
+}
+
 // PreStart is the pre-start action
 func (q *Preemption) PreStart(viewer api.Viewer) error {
 	if viewer == nil {
@@ -107,7 +132,7 @@ func (q *Preemption) PreStart(viewer api.Viewer) error {
 		if supportCgroupTypes[r].initRes == nil {
 			continue
 		}
-		if err := supportCgroupTypes[r].initRes(&q.config); err != nil {
+		if err := supportCgroupTypes[r].initRes(audit.Origin{Service: q.Name}, &q.config); err != nil {
 			return err
 		}
 	}
@@ -130,7 +155,7 @@ func (q *Preemption) AddPod(pod *typedef.PodInfo) error {
 
 // UpdatePod implement update function when pod info is changed
 func (q *Preemption) UpdatePod(old, new *typedef.PodInfo) error {
-	oldQos, newQos := getQoSLevel(old), getQoSLevel(new)
+	oldQos, newQos := q.config.qosLevel(old), q.config.qosLevel(new)
 	switch {
 	case newQos == oldQos:
 		return nil
@@ -154,7 +179,7 @@ func (q *Preemption) DeletePod(_ *typedef.PodInfo) error {
 // validateConfig will validate pod's qos level between value from
 // cgroup file and the one from pod info
 func (q *Preemption) validateConfig(pod *typedef.PodInfo) error {
-	targetLevel := getQoSLevel(pod)
+	targetLevel := q.config.qosLevel(pod)
 	for _, r := range q.config.Resource {
 		resOpt := supportCgroupTypes[r]
 		if err := pod.GetCgroupAttr(resOpt.cgKey).Expect(resOpt.getQosStr(targetLevel)); err != nil {
@@ -169,6 +194,34 @@ func (q *Preemption) validateConfig(pod *typedef.PodInfo) error {
 	return nil
 }
 
+// Reconcile restores the qos level of the pod and its containers changed by others
+func (q *Preemption) Reconcile(pod *typedef.PodInfo) ([]*helper.Drift, error) {
+	qosLevel := q.config.qosLevel(pod)
+	// the qos level of the online pod is not set by the service
+	if qosLevel == constant.Online {
+		return nil, nil
+	}
+	var hierarchies = []*cgroup.Hierarchy{&pod.Hierarchy}
+	for _, container := range pod.IDContainersMap {
+		hierarchies = append(hierarchies, &container.Hierarchy)
+	}
+	var (
+		drifts []*helper.Drift
+		errs   error
+	)
+	for _, r := range q.config.Resource {
+		resOpt := supportCgroupTypes[r]
+		for _, h := range hierarchies {
+			drift, err := helper.ReconcileCgroupAttr(h, resOpt.cgKey, resOpt.getQosStr(qosLevel))
+			if drift != nil {
+				drifts = append(drifts, drift)
+			}
+			errs = util.AppendErr(errs, err)
+		}
+	}
+	return drifts, errs
+}
+
 // SetQoSLevel set pod and all containers' qos level within it
 func (q *Preemption) SetQoSLevel(pod *typedef.PodInfo) error {
 	if pod == nil {
@@ -189,7 +242,7 @@ func (q *Preemption) SetQoSLevel(pod *typedef.PodInfo) error {
 		}
 	}
 
-	qosLevel := getQoSLevel(pod)
+	qosLevel := q.config.qosLevel(pod)
 	if qosLevel == constant.Online {
 		log.Infof("pod %s(%s) has already been set to online(%d)", pod.Name, pod.UID, qosLevel)
 		return nil
@@ -212,15 +265,20 @@ func (q *Preemption) SetQoSLevel(pod *typedef.PodInfo) error {
 	if errs != nil {
 		return errs
 	}
-	log.Infof("pod %s(%s) is set to offline(%d) successfully", pod.Name, pod.UID, qosLevel)
+	log.Infof("pod %s(%s) is set to %v(%d) successfully", pod.Name, pod.UID, pod.Priority(), qosLevel)
 	return nil
 }
 
-func getQoSLevel(pod *typedef.PodInfo) int {
+// qosLevel returns the qos level of the priority tier of the pod
+func (conf *PreemptionConfig) qosLevel(pod *typedef.PodInfo) int {
 	if pod == nil {
 		return constant.Online
 	}
-	if pod.Offline() {
+	priority := pod.Priority()
+	if level, ok := conf.Levels[priority.String()]; ok {
+		return level
+	}
+	if priority.Offline() {
 		return constant.Offline
 	}
 
@@ -237,6 +295,15 @@ func (conf *PreemptionConfig) Validate() error {
 		if !ok {
 			return fmt.Errorf("does not support setting the %s subsystem", r)
 		}
+		for name, level := range conf.Levels {
+			if _, err := typedef.ParsePriority(name); err != nil {
+				return err
+			}
+			if level < resOpt.minLevel || level > resOpt.maxLevel {
+				return fmt.Errorf("qos level %d of %v out of range [%d,%d] for the %s subsystem",
+					level, name, resOpt.minLevel, resOpt.maxLevel, r)
+			}
+		}
 		if resOpt.validateResConf == nil {
 			continue
 		}
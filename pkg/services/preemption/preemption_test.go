@@ -207,6 +207,31 @@ func TestPreemptionValidate(t *testing.T) {
 			name:    "TC3-empty config",
 			wantErr: true,
 		},
+		{
+			name: "TC4-qos levels of the priority tiers",
+			fields: fields{
+				Name: "qos",
+				Config: PreemptionConfig{Resource: []string{"cpu"},
+					Levels: map[string]int{"latency-critical": 2, "best-effort": -2}},
+			},
+		},
+		{
+			name: "TC5-invalid priority tier",
+			fields: fields{
+				Name:   "qos",
+				Config: PreemptionConfig{Resource: []string{"cpu"}, Levels: map[string]int{"offline": -1}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "TC6-qos level not supported by memory",
+			fields: fields{
+				Name: "qos",
+				Config: PreemptionConfig{Resource: []string{"cpu", "memory"},
+					Levels: map[string]int{"best-effort": -2}},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range validateTC {
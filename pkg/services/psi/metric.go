@@ -18,6 +18,7 @@ import (
 
 	"isula.org/rubik/pkg/common/constant"
 	"isula.org/rubik/pkg/common/log"
+	"isula.org/rubik/pkg/common/metrics"
 	"isula.org/rubik/pkg/common/util"
 	"isula.org/rubik/pkg/core/metric"
 	"isula.org/rubik/pkg/core/trigger/common"
@@ -53,6 +54,8 @@ type BasePSIMetric struct {
 
 // Update updates the PSI CPU indicator of the cgroup list
 func (m *BasePSIMetric) Update() error {
+	// the readings of the pods no longer monitored are dropped
+	metrics.PSIAvg10.Reset()
 	if len(m.conservation) == 0 || len(m.suspicion) == 0 {
 		log.Debugf("lack of guarantors or suspicious objects")
 		return nil
@@ -75,6 +78,8 @@ func detectPSiMetric(resTyp string, conservation map[string]*typedef.PodInfo, av
 		return false
 	}
 
+	// all pods are checked so that the readings of every pod are recorded
+	var reached bool
 	for _, pod := range conservation {
 		log.Debugf("check psi of online pod: %v", pod.Name)
 		pressure, err := pod.GetCgroupAttr(key).PSI()
@@ -82,13 +87,14 @@ func detectPSiMetric(resTyp string, conservation map[string]*typedef.PodInfo, av
 			log.Warnf("failed to get file %v: %v", key.FileName, err)
 			continue
 		}
+		metrics.PSIAvg10.Set(pressure.Some.Avg10, pod.UID, resTyp)
 		if pressure.Some.Avg10 > avg10Threshold {
 			log.Warnf("%v resource of pod %v reaches psi avg10 threshold (cur: %v, threshold: %v)",
 				resTyp, pod.UID, pressure.Some.Avg10, avg10Threshold)
-			return true
+			reached = true
 		}
 	}
-	return false
+	return reached
 }
 
 func alarm(resTyp string, triggers []common.Trigger, suspicion map[string]*typedef.PodInfo) error {
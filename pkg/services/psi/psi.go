@@ -25,13 +25,13 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	"isula.org/rubik/pkg/api"
-	"isula.org/rubik/pkg/common/constant"
 	"isula.org/rubik/pkg/common/log"
 	"isula.org/rubik/pkg/core/metric"
 	"isula.org/rubik/pkg/core/trigger/common"
 	"isula.org/rubik/pkg/core/trigger/executor"
 	"isula.org/rubik/pkg/core/trigger/template"
 	"isula.org/rubik/pkg/core/typedef"
+	"isula.org/rubik/pkg/core/typedef/cgroup"
 	"isula.org/rubik/pkg/resource/analyze"
 	"isula.org/rubik/pkg/resource/manager"
 	"isula.org/rubik/pkg/resource/manager/cadvisor"
@@ -191,6 +191,16 @@ func (m *Manager) SetConfig(f helper.ConfigHandler) error {
 	return nil
 }
 
+// CheckCapability checks whether the kernel supports the PSI of the configured resources
+func (m *Manager) CheckCapability() error {
+	for _, res := range m.conf.Resource {
+		if err := cgroup.CheckKubepodsFile(supportResources[res]); err != nil {
+			return fmt.Errorf("PSI of %v is not supported: %v", res, err)
+		}
+	}
+	return nil
+}
+
 // IsRunner returns true that tells other Manager is a persistent service
 func (m *Manager) IsRunner() bool {
 	return true
@@ -206,20 +216,8 @@ func (m *Manager) PreStart(viewer api.Viewer) error {
 }
 
 func priority(online bool) api.ListOption {
-	const (
-		offTag = "true"
-		onTag  = "false"
-	)
-	var wanted = onTag
-	if !online {
-		wanted = offTag
-	}
 	return func(pod *typedef.PodInfo) bool {
-		if prio, declared := pod.Annotations[constant.PriorityAnnotationKey]; declared {
-			return prio == wanted
-		}
-		// If no priority is set, the priority is online by default
-		return onTag == wanted
+		return pod.Online() == online
 	}
 }
 
@@ -16,11 +16,7 @@ package quotaburst
 
 import (
 	"fmt"
-	"io/ioutil"
-	"os"
-	"path/filepath"
 	"runtime"
-	"strings"
 
 	"isula.org/rubik/pkg/api"
 	"isula.org/rubik/pkg/common/constant"
@@ -31,6 +27,12 @@ import (
 	"isula.org/rubik/pkg/services/helper"
 )
 
+var (
+	burstKey  = &cgroup.Key{SubSys: "cpu", FileName: "cpu.cfs_burst_us"}
+	quotaKey  = &cgroup.Key{SubSys: "cpu", FileName: "cpu.cfs_quota_us"}
+	periodKey = &cgroup.Key{SubSys: "cpu", FileName: "cpu.cfs_period_us"}
+)
+
 // Burst is used to control cpu burst
 type Burst struct {
 	helper.ServiceBase
@@ -95,6 +97,11 @@ func (conf *Burst) DeletePod(podInfo *typedef.PodInfo) error {
 	return nil
 }
 
+// CheckCapability checks whether the kernel supports the cpu burst
+func (conf *Burst) CheckCapability() error {
+	return cgroup.CheckKubepodsFile(burstKey)
+}
+
 // PreStart is the pre-start action
 func (conf *Burst) PreStart(viewer api.Viewer) error {
 	if viewer == nil {
@@ -109,6 +116,41 @@ func (conf *Burst) PreStart(viewer api.Viewer) error {
 	return nil
 }
 
+// Reconcile restores the quota burst of the pod and its containers changed by others
+func (conf *Burst) Reconcile(podInfo *typedef.PodInfo) ([]*helper.Drift, error) {
+	if podInfo.Annotations[constant.QuotaBurstAnnotationKey] == "" {
+		return nil, nil
+	}
+	burst, err := parseQuotaBurst(podInfo)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		drifts   []*helper.Drift
+		errs     error
+		podBurst int64
+	)
+	reconcile := func(value int64, h *cgroup.Hierarchy) bool {
+		// the burst is not set if the quota does not allow it
+		if matchQuota(value, h) != nil {
+			return false
+		}
+		drift, err := helper.ReconcileCgroupAttr(h, burstKey, util.FormatInt64(value))
+		if drift != nil {
+			drifts = append(drifts, drift)
+		}
+		errs = util.AppendErr(errs, err)
+		return true
+	}
+	for _, c := range podInfo.IDContainersMap {
+		if reconcile(burst, &c.Hierarchy) {
+			podBurst += burst
+		}
+	}
+	reconcile(podBurst, &podInfo.Hierarchy)
+	return drifts, errs
+}
+
 func setPodQuotaBurst(podInfo *typedef.PodInfo) error {
 	if podInfo.Annotations[constant.QuotaBurstAnnotationKey] == "" {
 		return nil
@@ -118,11 +160,9 @@ func setPodQuotaBurst(podInfo *typedef.PodInfo) error {
 		return err
 	}
 	var podBurst int64
-	const subsys = "cpu"
 	// 1. Try to write container burst value firstly
 	for _, c := range podInfo.IDContainersMap {
-		cgpath := cgroup.AbsoluteCgroupPath(subsys, c.Path, "")
-		if err := setQuotaBurst(burst, cgpath); err != nil {
+		if err := setQuotaBurst(burst, &c.Hierarchy); err != nil {
 			log.Errorf("set container quota burst failed: %v", err)
 			continue
 		}
@@ -134,52 +174,37 @@ func setPodQuotaBurst(podInfo *typedef.PodInfo) error {
 		podBurst += burst
 	}
 	// 2. Try to write pod burst value
-	podPath := cgroup.AbsoluteCgroupPath(subsys, podInfo.Path, "")
-	if err := setQuotaBurst(podBurst, podPath); err != nil {
+	if err := setQuotaBurst(podBurst, &podInfo.Hierarchy); err != nil {
 		log.Errorf("set pod quota burst failed: %v", err)
 	}
 	return nil
 }
 
-func setQuotaBurst(burst int64, cgpath string) error {
-	const burstFileName = "cpu.cfs_burst_us"
-	fpath := filepath.Join(cgpath, burstFileName)
+func setQuotaBurst(burst int64, h *cgroup.Hierarchy) error {
 	// check whether cgroup support cpu burst
-	if _, err := os.Stat(fpath); err != nil && os.IsNotExist(err) {
-		return fmt.Errorf("quota-burst path=%v missing", fpath)
+	if err := h.GetCgroupAttr(burstKey).Err; err != nil {
+		return fmt.Errorf("quota-burst path=%v missing: %v", h.Path, err)
 	}
-	if err := matchQuota(burst, cgpath); err != nil {
+	if err := matchQuota(burst, h); err != nil {
 		return err
 	}
 	// try to write cfs_burst_us
-	if err := ioutil.WriteFile(fpath, []byte(util.FormatInt64(burst)), constant.DefaultFileMode); err != nil {
-		return fmt.Errorf("quota-burst path=%v setting failed: %v", fpath, err)
+	if err := h.SetCgroupAttr(burstKey, util.FormatInt64(burst)); err != nil {
+		return fmt.Errorf("quota-burst path=%v setting failed: %v", h.Path, err)
 	}
-	log.Infof("quota-burst path=%v setting success", fpath)
+	log.Infof("quota-burst path=%v setting success", h.Path)
 	return nil
 }
 
-func matchQuota(burst int64, cgpath string) error {
-	const (
-		cpuPeriodFileName = "cpu.cfs_period_us"
-		cpuQuotaFileName  = "cpu.cfs_quota_us"
-	)
-	quotaStr, err := util.ReadSmallFile(filepath.Join(cgpath, cpuQuotaFileName))
-	if err != nil {
-		return fmt.Errorf("failed to read cfs.cpu_quota_us: %v", err)
-	}
-	quota, err := util.ParseInt64(strings.TrimSpace(string(quotaStr)))
+func matchQuota(burst int64, h *cgroup.Hierarchy) error {
+	quota, err := h.GetCgroupAttr(quotaKey).Int64()
 	if err != nil {
-		return fmt.Errorf("failed to parse quota as int64: %v", err)
+		return fmt.Errorf("failed to get cfs.cpu_quota_us: %v", err)
 	}
 
-	periodStr, err := util.ReadSmallFile(filepath.Join(cgpath, cpuPeriodFileName))
-	if err != nil {
-		return fmt.Errorf("failed to read cfs.cpu_period_us: %v", err)
-	}
-	period, err := util.ParseInt64(strings.TrimSpace(string(periodStr)))
+	period, err := h.GetCgroupAttr(periodKey).Int64()
 	if err != nil {
-		return fmt.Errorf("failed to parse period as int64: %v", err)
+		return fmt.Errorf("failed to get cfs.cpu_period_us: %v", err)
 	}
 
 	/*
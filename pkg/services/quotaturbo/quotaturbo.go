@@ -23,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	"isula.org/rubik/pkg/api"
+	"isula.org/rubik/pkg/common/checkpoint"
 	"isula.org/rubik/pkg/common/constant"
 	"isula.org/rubik/pkg/common/log"
 	"isula.org/rubik/pkg/common/util"
@@ -36,6 +37,8 @@ const (
 	defaultHightWaterMark         = 60
 	defaultAlarmWaterMark         = 80
 	defaultQuotaTurboSyncInterval = 100
+	// checkpointVersion is the version of the state of the quotaTurbo service
+	checkpointVersion = 1
 )
 
 var (
@@ -80,6 +83,14 @@ type QuotaTurbo struct {
 	client quotaturbo.ClientAPI
 	Viewer api.Viewer
 	helper.ServiceBase
+	// restored is the cgroup paths of the containers adjusted before rubik restarts
+	restored map[string]struct{}
+}
+
+// turboCheckpoint is the state of the quotaTurbo service saved in the checkpoint
+type turboCheckpoint struct {
+	// Histories is the cpu usage histories of the containers adjusting quota by cgroup path
+	Histories map[string][]quotaturbo.UsageRecord `json:"histories"`
 }
 
 // NewQuotaTurbo generate quota turbo objects
@@ -209,11 +220,55 @@ func (qt *QuotaTurbo) PreStart(viewer api.Viewer) error {
 	)
 	qt.Viewer = viewer
 
-	// 2. attempts to fix all currently running pods and containers
+	// 2. attempts to fix all currently running pods and containers,
+	// the quotas adjusted before rubik restarts continue to be adjusted from the current values
 	pods := viewer.ListPodsWithOptions()
 	for _, pod := range pods {
+		if qt.adjustedBeforeRestart(pod) {
+			continue
+		}
 		recoverOnePodQuota(pod)
 	}
+	qt.restored = nil
+	return nil
+}
+
+// adjustedBeforeRestart returns true if the quotas of all containers with cpu limit in the pod
+// are adjusted before rubik restarts and still need to be adjusted
+func (qt *QuotaTurbo) adjustedBeforeRestart(pod *typedef.PodInfo) bool {
+	if len(qt.restored) == 0 || pod.Annotations[constant.QuotaAnnotationKey] != "true" {
+		return false
+	}
+	var adjusted bool
+	for _, cont := range pod.IDContainersMap {
+		if cont.LimitResources[typedef.ResourceCPU] == 0 {
+			continue
+		}
+		if _, ok := qt.restored[cont.Path]; !ok {
+			return false
+		}
+		adjusted = true
+	}
+	return adjusted
+}
+
+// Checkpoint returns the cpu usage histories of the containers adjusting quota
+func (qt *QuotaTurbo) Checkpoint() (*checkpoint.State, error) {
+	return checkpoint.NewState(checkpointVersion, &turboCheckpoint{Histories: qt.client.Histories()})
+}
+
+// Restore restores the cpu usage histories saved before rubik restarts,
+// the containers in the histories keep their adjusted quotas when the service starts
+func (qt *QuotaTurbo) Restore(state *checkpoint.State) error {
+	cp := &turboCheckpoint{}
+	if err := state.Decode(checkpointVersion, cp); err != nil {
+		return err
+	}
+	qt.client.RestoreHistories(cp.Histories)
+	qt.restored = make(map[string]struct{}, len(cp.Histories))
+	for path := range cp.Histories {
+		qt.restored[path] = struct{}{}
+	}
 	return nil
 }
 
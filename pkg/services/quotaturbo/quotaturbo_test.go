@@ -25,6 +25,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"isula.org/rubik/pkg/common/checkpoint"
 	"isula.org/rubik/pkg/common/constant"
 	"isula.org/rubik/pkg/common/util"
 	"isula.org/rubik/pkg/core/typedef"
@@ -495,3 +496,38 @@ func TestQuotaTurbo_Run(t *testing.T) {
 		})
 	}
 }
+
+// TestQuotaTurbo_Checkpoint tests keeping the adjusted quotas of the containers after restart
+func TestQuotaTurbo_Checkpoint(t *testing.T) {
+	const name = "quotaturbo"
+	var (
+		cont = &typedef.ContainerInfo{
+			ID:             "testCon1",
+			Hierarchy:      cgroup.Hierarchy{Path: "kubepods/testPod1/testCon1"},
+			LimitResources: typedef.ResourceMap{typedef.ResourceCPU: 1},
+		}
+		pod = &typedef.PodInfo{
+			UID:             "testPod1",
+			Hierarchy:       cgroup.Hierarchy{Path: "kubepods/testPod1"},
+			Annotations:     map[string]string{constant.QuotaAnnotationKey: "true"},
+			IDContainersMap: map[string]*typedef.ContainerInfo{cont.ID: cont},
+		}
+		histories = map[string][]quotaturbo.UsageRecord{cont.Path: {{Timestamp: 1, Usage: 1}}}
+	)
+	state, err := checkpoint.NewState(checkpointVersion, &turboCheckpoint{Histories: histories})
+	assert.NoError(t, err)
+
+	qt := NewQuotaTurbo(name)
+	assert.False(t, qt.adjustedBeforeRestart(pod))
+	assert.NoError(t, qt.Restore(state))
+	assert.True(t, qt.adjustedBeforeRestart(pod))
+	// the quota is recovered if the container is no longer adjusted
+	pod.Annotations[constant.QuotaAnnotationKey] = "false"
+	assert.False(t, qt.adjustedBeforeRestart(pod))
+
+	state, err = qt.Checkpoint()
+	assert.NoError(t, err)
+	assert.Equal(t, checkpointVersion, state.Version)
+	state.Version++
+	assert.Error(t, NewQuotaTurbo(name).Restore(state))
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 
 	"isula.org/rubik/pkg/api"
+	"isula.org/rubik/pkg/common/checkpoint"
 	"isula.org/rubik/pkg/common/log"
 	"isula.org/rubik/pkg/core/typedef"
 	"isula.org/rubik/pkg/services/helper"
@@ -61,6 +62,35 @@ type Service interface {
 	Terminate(api.Viewer) error
 }
 
+// ConfigValidator is implemented by the service whose configuration needs to be checked without being applied,
+// otherwise a new service object is created to check the configuration by SetConfig.
+type ConfigValidator interface {
+	// ValidateConfig checks the configuration obtained from the ConfigHandler
+	ValidateConfig(helper.ConfigHandler) error
+}
+
+// CapabilityChecker is implemented by the service which depends on the features of the kernel
+type CapabilityChecker interface {
+	// CheckCapability checks whether the kernel supports the configured service
+	CheckCapability() error
+}
+
+// Checkpointer is implemented by the service whose state is kept across the restarts of rubik
+type Checkpointer interface {
+	// Checkpoint returns the current state of the service to be saved
+	Checkpoint() (*checkpoint.State, error)
+	// Restore restores the state saved before rubik restarts, it is called before PreStart
+	Restore(*checkpoint.State) error
+}
+
+// Reconciler is implemented by the service whose settings of the pods may be changed by others,
+// such as the kubelet or the container runtime
+type Reconciler interface {
+	// Reconcile compares the settings of the pod applied by the service with the actual values,
+	// restores the changed settings and returns them
+	Reconcile(*typedef.PodInfo) ([]*helper.Drift, error)
+}
+
 // FeatureSpec to defines the feature name and whether the feature is enabled.
 type FeatureSpec struct {
 	// feature name
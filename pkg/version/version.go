@@ -16,7 +16,7 @@ package version
 
 import (
 	"fmt"
-	"os"
+	"io"
 	"runtime"
 )
 
@@ -31,15 +31,12 @@ var (
 	BuildTime string
 )
 
-func init() {
-	const maxArgLen = 2
-	if len(os.Args) == maxArgLen && os.Args[1] == "-v" {
-		fmt.Println("Version:      ", Version)
-		fmt.Println("Release:      ", Release)
-		fmt.Println("Go Version:   ", runtime.Version())
-		fmt.Println("Git Commit:   ", GitCommit)
-		fmt.Println("Built:        ", BuildTime)
-		fmt.Println("OS/Arch:      ", runtime.GOOS+"/"+runtime.GOARCH)
-		os.Exit(0)
-	}
+// Print prints the version information of rubik
+func Print(w io.Writer) {
+	fmt.Fprintln(w, "Version:      ", Version)
+	fmt.Fprintln(w, "Release:      ", Release)
+	fmt.Fprintln(w, "Go Version:   ", runtime.Version())
+	fmt.Fprintln(w, "Git Commit:   ", GitCommit)
+	fmt.Fprintln(w, "Built:        ", BuildTime)
+	fmt.Fprintln(w, "OS/Arch:      ", runtime.GOOS+"/"+runtime.GOARCH)
 }